@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server serves operational endpoints such as /metrics on a port which isn't exposed to clients.
+type Server struct {
+	mux    *http.ServeMux
+	server *http.Server
+}
+
+// NewServer creates a new admin server serving the Prometheus metrics at /metrics.
+func NewServer(address string) *Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &Server{
+		mux: mux,
+		server: &http.Server{
+			Addr:              address,
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}
+}
+
+// Handle registers a handler for the given pattern.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Start runs the admin server on a specific address.
+func (s *Server) Start() error {
+	log.Println("admin server is listening on", s.server.Addr)
+
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Wrap(err, "failed to start admin server")
+	}
+
+	return nil
+}
+
+// Stop stops the admin server.
+func (s *Server) Stop(ctx context.Context) error {
+	return errors.Wrap(s.server.Shutdown(ctx), "failed to shutdown admin server")
+}
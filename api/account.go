@@ -4,19 +4,49 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/IfanTsai/go-lib/gin/middlewares"
 	"github.com/gin-gonic/gin"
 	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/util"
 	"github.com/lib/pq"
 )
 
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	Type     string `json:"type" binding:"omitempty,account_type"`
+	Nickname string `json:"nickname" binding:"omitempty,account_nickname"`
 }
 
+// updateAccountRequest only updates the given fields, an empty nickname clears it.
+type updateAccountRequest struct {
+	Type     *string `json:"type" binding:"omitempty,account_type"`
+	Nickname *string `json:"nickname" binding:"omitempty,len=0|account_nickname"`
+}
+
+// getAccountRequest identifies an account by its id or its account number.
 type getAccountRequest struct {
-	ID int64 `uri:"id" binding:"required,min=1"`
+	ID string `uri:"id" binding:"required,account_ref"`
+}
+
+// accountRef is an account given either by its account number or by its id.
+type accountRef struct {
+	ID     int64
+	Number string
+}
+
+func (r getAccountRequest) ref() accountRef {
+	if id, err := strconv.ParseInt(r.ID, 10, 64); err == nil {
+		return accountRef{ID: id}
+	}
+
+	return accountRef{Number: r.ID}
+}
+
+// setInterestRateRequest takes the annual interest rate in basis points, at most 100%.
+type setInterestRateRequest struct {
+	RateBps *int32 `json:"rate_bps" binding:"required,min=0,max=10000"`
 }
 
 type listAccountRequest struct {
@@ -39,12 +69,28 @@ func (s *Server) createAccount(c *gin.Context) {
 		return
 	}
 
-	account, err := s.store.CreateAccount(c, db.CreateAccountParams{
-		Owner:    username,
-		Currency: req.Currency,
-		Balance:  0,
+	if req.Type == "" {
+		req.Type = util.Checking
+	}
+
+	account, err := s.store.CreateAccountTx(c, db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:    username,
+			Currency: req.Currency,
+			Balance:  0,
+			Type:     req.Type,
+			Nickname: req.Nickname,
+		},
+		MaxPerCurrency: maxAccountsPerCurrency(s.config),
+		Audit:          auditMeta(c, username),
 	})
 	if err != nil {
+		if errors.Is(err, db.ErrAccountLimitReached) || errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusForbidden, errorResponse(err))
+
+			return
+		}
+
 		if pqErr, ok := err.(*pq.Error); ok { //nolint: errorlint
 			switch pqErr.Code.Name() {
 			case "foreign_key_violation", "unique_violation":
@@ -61,6 +107,16 @@ func (s *Server) createAccount(c *gin.Context) {
 	c.JSON(http.StatusOK, account)
 }
 
+// maxAccountsPerCurrency returns how many accounts a user can open in one currency, at least one.
+// A config without the limit keeps the rule of one account per currency instead of allowing any number.
+func maxAccountsPerCurrency(config util.Config) int64 {
+	if config.MaxAccountsPerCurrency < 1 {
+		return 1
+	}
+
+	return config.MaxAccountsPerCurrency
+}
+
 func (s *Server) getAccount(c *gin.Context) {
 	var req getAccountRequest
 	if err := c.ShouldBindUri(&req); err != nil {
@@ -69,7 +125,7 @@ func (s *Server) getAccount(c *gin.Context) {
 		return
 	}
 
-	account, err := s.store.GetAccount(c, req.ID)
+	account, err := s.findAccount(c, req.ref())
 	if err != nil {
 		httpCode := http.StatusInternalServerError
 		if errors.Is(err, sql.ErrNoRows) {
@@ -129,3 +185,192 @@ func (s *Server) listAccount(c *gin.Context) {
 
 	c.JSON(http.StatusOK, accounts)
 }
+
+func (s *Server) updateAccount(c *gin.Context) {
+	var uri getAccountRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req updateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	account, err := s.findAccount(c, uri.ref())
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return
+	}
+
+	// A logged-in user can only update accounts that he/she owns
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	if account.Owner != username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		c.JSON(http.StatusUnauthorized, errorResponse(err))
+
+		return
+	}
+
+	arg := db.UpdateAccountParams{
+		ID: account.ID,
+	}
+
+	if req.Type != nil {
+		arg.Type = sql.NullString{String: *req.Type, Valid: true}
+	}
+
+	if req.Nickname != nil {
+		arg.Nickname = sql.NullString{String: *req.Nickname, Valid: true}
+	}
+
+	account, err = s.store.UpdateAccountTx(c, db.UpdateAccountTxParams{
+		UpdateAccountParams: arg,
+		Audit:               auditMeta(c, username),
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok { //nolint: errorlint
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				c.JSON(http.StatusForbidden, errorResponse(err))
+
+				return
+			}
+		}
+
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (s *Server) closeAccount(c *gin.Context) {
+	var req getAccountRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	account, err := s.findAccount(c, req.ref())
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return
+	}
+
+	// A logged-in user can only close accounts that he/she owns
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	if account.Owner != username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		c.JSON(http.StatusUnauthorized, errorResponse(err))
+
+		return
+	}
+
+	account, err = s.store.CloseAccountTx(c, db.CloseAccountTxParams{
+		ID:    account.ID,
+		Audit: auditMeta(c, username),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrAccountNotEmpty) || errors.Is(err, db.ErrAccountClosed) {
+			c.JSON(http.StatusForbidden, errorResponse(err))
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// setAccountInterestRate sets the interest rate a savings account earns instead of the default rate.
+// Only bankers can set interest rates.
+func (s *Server) setAccountInterestRate(c *gin.Context) {
+	var uri getAccountRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req setInterestRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	banker, ok := s.authorizeBanker(c)
+	if !ok {
+		return
+	}
+
+	account, err := s.findAccount(c, uri.ref())
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return
+	}
+
+	rate, err := s.store.SetAccountInterestRateTx(c, db.SetAccountInterestRateTxParams{
+		AccountID: account.ID,
+		RateBps:   *req.RateBps,
+		Audit:     auditMeta(c, banker.Username),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrNotSavingsAccount) || errors.Is(err, db.ErrAccountClosed) {
+			c.JSON(http.StatusForbidden, errorResponse(err))
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, rate)
+}
+
+// findAccount looks an account up by its account number if given, otherwise by its id.
+func (s *Server) findAccount(c *gin.Context, ref accountRef) (db.Account, error) {
+	if ref.Number != "" {
+		return s.store.GetAccountByNumber(c, ref.Number)
+	}
+
+	return s.store.GetAccount(c, ref.ID)
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/pkg/errors"
+)
+
+// setLimitsRequest takes transfer limits in minor units, 0 means unlimited.
+type setLimitsRequest struct {
+	PerTransaction *int64 `json:"per_transaction" binding:"required,min=0"`
+	Daily          *int64 `json:"daily" binding:"required,min=0"`
+	Monthly        *int64 `json:"monthly" binding:"required,min=0"`
+}
+
+type tierLimitRequest struct {
+	Tier     string `uri:"tier" binding:"required,alphanum"`
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+type userTierURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type setUserTierRequest struct {
+	Tier string `json:"tier" binding:"required,alphanum"`
+}
+
+func (s *Server) setAccountLimits(c *gin.Context) {
+	var uri getAccountRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req setLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	banker, ok := s.authorizeBanker(c)
+	if !ok {
+		return
+	}
+
+	account, err := s.findAccount(c, uri.ref())
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return
+	}
+
+	limit, err := s.store.SetAccountLimitTx(c, db.SetAccountLimitTxParams{
+		SetAccountLimitParams: db.SetAccountLimitParams{
+			AccountID:      account.ID,
+			PerTransaction: *req.PerTransaction,
+			Daily:          *req.Daily,
+			Monthly:        *req.Monthly,
+		},
+		Audit: auditMeta(c, banker.Username),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrAccountClosed) {
+			c.JSON(http.StatusForbidden, errorResponse(err))
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, limit)
+}
+
+func (s *Server) setTierLimits(c *gin.Context) {
+	var uri tierLimitRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req setLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	banker, ok := s.authorizeBanker(c)
+	if !ok {
+		return
+	}
+
+	limit, err := s.store.SetTierLimitTx(c, db.SetTierLimitTxParams{
+		SetTierLimitParams: db.SetTierLimitParams{
+			Tier:           uri.Tier,
+			Currency:       uri.Currency,
+			PerTransaction: *req.PerTransaction,
+			Daily:          *req.Daily,
+			Monthly:        *req.Monthly,
+		},
+		Audit: auditMeta(c, banker.Username),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, limit)
+}
+
+func (s *Server) setUserTier(c *gin.Context) {
+	var uri userTierURI
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req setUserTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	banker, ok := s.authorizeBanker(c)
+	if !ok {
+		return
+	}
+
+	user, err := s.store.SetUserTierTx(c, db.SetUserTierTxParams{
+		UpdateUserTierParams: db.UpdateUserTierParams{
+			Username: uri.Username,
+			Tier:     req.Tier,
+		},
+		Audit: auditMeta(c, banker.Username),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrUnknownTier):
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
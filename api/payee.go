@@ -0,0 +1,249 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/IfanTsai/go-lib/gin/middlewares"
+	"github.com/gin-gonic/gin"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/lib/pq"
+	xerrors "github.com/pkg/errors"
+)
+
+// createPayeeRequest saves either the default account of a user in a currency or an account by its number.
+type createPayeeRequest struct {
+	Username      string `json:"username" binding:"required_without=AccountNumber,excluded_with=AccountNumber,omitempty,alphanum"` //nolint: lll
+	Currency      string `json:"currency" binding:"required_with=Username,excluded_with=AccountNumber,omitempty,currency"`         //nolint: lll
+	AccountNumber string `json:"account_number" binding:"omitempty,account_number"`
+	Nickname      string `json:"nickname" binding:"required,account_nickname"`
+}
+
+type getPayeeRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listPayeesRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+type updateUserSettingsRequest struct {
+	RequireSavedPayee *bool `json:"require_saved_payee" binding:"required"`
+}
+
+// payeeResponse describes a payee without the internal id of its account.
+type payeeResponse struct {
+	ID        int64     `json:"id"`
+	Nickname  string    `json:"nickname"`
+	Username  string    `json:"username"`
+	Currency  string    `json:"currency"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newPayeeResponse(payee db.GetPayeeRow) payeeResponse {
+	return payeeResponse{
+		ID:        payee.ID,
+		Nickname:  payee.Nickname,
+		Username:  payee.PayeeUsername,
+		Currency:  payee.Currency,
+		CreatedAt: payee.CreatedAt,
+	}
+}
+
+func (s *Server) createPayee(c *gin.Context) {
+	var req createPayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	account, err := s.payeeAccount(c, req)
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return
+	}
+
+	payee, err := s.store.CreatePayee(c, db.CreatePayeeParams{
+		Owner:     username,
+		AccountID: account.ID,
+		Nickname:  req.Nickname,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok { //nolint: errorlint
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation", "unique_violation":
+				c.JSON(http.StatusForbidden, errorResponse(err))
+
+				return
+			}
+		}
+
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, payeeResponse{
+		ID:        payee.ID,
+		Nickname:  payee.Nickname,
+		Username:  account.Owner,
+		Currency:  account.Currency,
+		CreatedAt: payee.CreatedAt,
+	})
+}
+
+// payeeAccount returns the account to save as a payee.
+func (s *Server) payeeAccount(c *gin.Context, req createPayeeRequest) (db.Account, error) {
+	if req.AccountNumber != "" {
+		return s.store.GetAccountByNumber(c, req.AccountNumber)
+	}
+
+	account, err := s.store.GetDefaultAccount(c, db.GetDefaultAccountParams{
+		Owner:    req.Username,
+		Currency: req.Currency,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return account, xerrors.Wrap(err, "user has no account in this currency")
+	}
+
+	return account, err
+}
+
+func (s *Server) listPayees(c *gin.Context) {
+	var req listPayeesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	payees, err := s.store.ListPayees(c, db.ListPayeesParams{
+		Owner:  username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	rsp := make([]payeeResponse, 0, len(payees))
+	for _, payee := range payees {
+		rsp = append(rsp, newPayeeResponse(db.GetPayeeRow(payee)))
+	}
+
+	c.JSON(http.StatusOK, rsp)
+}
+
+func (s *Server) deletePayee(c *gin.Context) {
+	var req getPayeeRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	payee, valid := s.validPayee(c, req.ID)
+	if !valid {
+		return
+	}
+
+	err := s.store.DeletePayee(c, db.DeletePayeeParams{
+		ID:    payee.ID,
+		Owner: payee.Owner,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newPayeeResponse(payee))
+}
+
+func (s *Server) updateUserSettings(c *gin.Context) {
+	var req updateUserSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	user, err := s.store.UpdateUserTx(c, db.UpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
+			Username: username,
+			RequireSavedPayee: sql.NullBool{
+				Bool:  *req.RequireSavedPayee,
+				Valid: true,
+			},
+		},
+		Audit: auditMeta(c, username),
+	})
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// validPayee checks that the payee exists and belongs to the authenticated user.
+func (s *Server) validPayee(c *gin.Context, payeeID int64) (db.GetPayeeRow, bool) {
+	payee, err := s.store.GetPayee(c, payeeID)
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return payee, false
+	}
+
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return payee, false
+	}
+
+	if payee.Owner != username {
+		err := errors.New("payee doesn't belong to the authenticated user")
+		c.JSON(http.StatusUnauthorized, errorResponse(err))
+
+		return payee, false
+	}
+
+	return payee, true
+}
@@ -0,0 +1,309 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/IfanTsai/go-lib/gin/middlewares"
+	"github.com/gin-gonic/gin"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/lib/pq"
+)
+
+// defaultPaymentRequestExpiry is how long a payment request can be paid when no expiry is given.
+const defaultPaymentRequestExpiry = 7 * 24 * time.Hour
+
+type createPaymentRequestRequest struct {
+	AccountID     int64      `json:"account_id" binding:"required_without=AccountNumber,excluded_with=AccountNumber,omitempty,min=1"` //nolint: lll
+	AccountNumber string     `json:"account_number" binding:"omitempty,account_number"`
+	Payer         string     `json:"payer" binding:"required,alphanum"`
+	Amount        int64      `json:"amount" binding:"required,gt=0"`
+	Currency      string     `json:"currency" binding:"required,currency"`
+	Memo          string     `json:"memo" binding:"omitempty,transfer_description"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+}
+
+type getPaymentRequestRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listPaymentRequestsRequest struct {
+	Direction string `form:"direction" binding:"required,oneof=incoming outgoing"`
+	PageID    int32  `form:"page_id" binding:"required,min=1"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+type payPaymentRequestRequest struct {
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,excluded_with=FromAccountNumber,omitempty,min=1"` //nolint: lll
+	FromAccountNumber string `json:"from_account_number" binding:"omitempty,account_number"`
+	Instant           bool   `json:"instant"`
+}
+
+// incomingPaymentRequest is a payment request as shown to its payer. The account to pay into belongs to another user,
+// so it is shown by its number instead of its internal id.
+type incomingPaymentRequest struct {
+	ID                     int64         `json:"id"`
+	RequesterAccountID     int64         `json:"-"`
+	Requester              string        `json:"requester"`
+	Payer                  string        `json:"payer"`
+	Amount                 int64         `json:"amount"`
+	Currency               string        `json:"currency"`
+	Memo                   string        `json:"memo"`
+	Status                 string        `json:"status"`
+	TransferID             sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt              time.Time     `json:"expires_at"`
+	CreatedAt              time.Time     `json:"created_at"`
+	RequesterAccountNumber string        `json:"requester_account_number"`
+}
+
+func newIncomingPaymentRequest(request db.PaymentRequest, requesterAccountNumber string) incomingPaymentRequest {
+	return incomingPaymentRequest(db.ListIncomingPaymentRequestsRow{
+		ID:                     request.ID,
+		RequesterAccountID:     request.RequesterAccountID,
+		Requester:              request.Requester,
+		Payer:                  request.Payer,
+		Amount:                 request.Amount,
+		Currency:               request.Currency,
+		Memo:                   request.Memo,
+		Status:                 request.Status,
+		TransferID:             request.TransferID,
+		ExpiresAt:              request.ExpiresAt,
+		CreatedAt:              request.CreatedAt,
+		RequesterAccountNumber: requesterAccountNumber,
+	})
+}
+
+func (s *Server) createPaymentRequest(c *gin.Context) {
+	var req createPaymentRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	// A logged-in user can only request money into his/her own account
+	account, valid := s.validAccount(c, accountRef{ID: req.AccountID, Number: req.AccountNumber}, req.Currency)
+	if !valid {
+		return
+	}
+
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	if account.Owner != username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		c.JSON(http.StatusUnauthorized, errorResponse(err))
+
+		return
+	}
+
+	if req.Payer == username {
+		err := errors.New("cannot request money from yourself")
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	expiresAt := time.Now().Add(defaultPaymentRequestExpiry)
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			err := errors.New("expires_at must be in the future")
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+
+			return
+		}
+
+		expiresAt = *req.ExpiresAt
+	}
+
+	request, err := s.store.CreatePaymentRequest(c, db.CreatePaymentRequestParams{
+		RequesterAccountID: account.ID,
+		Requester:          username,
+		Payer:              req.Payer,
+		Amount:             req.Amount,
+		Currency:           req.Currency,
+		Memo:               req.Memo,
+		ExpiresAt:          expiresAt,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok { //nolint: errorlint
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				c.JSON(http.StatusForbidden, errorResponse(err))
+
+				return
+			}
+		}
+
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+func (s *Server) listPaymentRequests(c *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	// A logged-in user can only list payment requests he/she sent or received
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	if req.Direction == "incoming" {
+		requests, err := s.store.ListIncomingPaymentRequests(c, db.ListIncomingPaymentRequestsParams{
+			Payer:  username,
+			Limit:  req.PageSize,
+			Offset: (req.PageID - 1) * req.PageSize,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return
+		}
+
+		rsp := make([]incomingPaymentRequest, 0, len(requests))
+		for _, request := range requests {
+			rsp = append(rsp, incomingPaymentRequest(request))
+		}
+
+		c.JSON(http.StatusOK, rsp)
+
+		return
+	}
+
+	requests, err := s.store.ListOutgoingPaymentRequests(c, db.ListOutgoingPaymentRequestsParams{
+		Requester: username,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+func (s *Server) declinePaymentRequest(c *gin.Context) {
+	var req getPaymentRequestRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, valid := s.validIncomingPaymentRequest(c, req.ID); !valid {
+		return
+	}
+
+	request, err := s.store.DeclinePaymentRequest(c, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusForbidden, errorResponse(db.ErrPaymentRequestNotPending))
+
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	requesterAccount, err := s.store.GetAccount(c, request.RequesterAccountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newIncomingPaymentRequest(request, requesterAccount.Number))
+}
+
+func (s *Server) payPaymentRequest(c *gin.Context) {
+	var uri getPaymentRequestRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req payPaymentRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	request, valid := s.validIncomingPaymentRequest(c, uri.ID)
+	if !valid {
+		return
+	}
+
+	from := accountRef{ID: req.FromAccountID, Number: req.FromAccountNumber}
+	to := accountRef{ID: request.RequesterAccountID}
+
+	fromAccount, toAccount, valid := s.validTransferAccounts(c, from, to, request.Currency)
+	if !valid {
+		return
+	}
+
+	if !s.allowedRecipient(c, fromAccount.Owner, toAccount.ID) {
+		return
+	}
+
+	result, ok := s.sendMoney(c, fromAccount, toAccount, db.TransferTxParams{
+		FromAccountID:    fromAccount.ID,
+		ToAccountID:      toAccount.ID,
+		Amount:           request.Amount,
+		Description:      request.Memo,
+		Audit:            auditMeta(c, fromAccount.Owner),
+		PaymentRequestID: request.ID,
+	}, req.Instant)
+	if !ok {
+		return
+	}
+
+	writeTransferResponse(c, result, newTransferResponse(result, fromAccount.Owner, 0))
+}
+
+// validIncomingPaymentRequest checks that the payment request exists and is addressed to the authenticated user.
+func (s *Server) validIncomingPaymentRequest(c *gin.Context, id int64) (db.PaymentRequest, bool) {
+	request, err := s.store.GetPaymentRequest(c, id)
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return request, false
+	}
+
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return request, false
+	}
+
+	if request.Payer != username {
+		err := errors.New("payment request isn't addressed to the authenticated user")
+		c.JSON(http.StatusUnauthorized, errorResponse(err))
+
+		return request, false
+	}
+
+	return request, true
+}
@@ -0,0 +1,469 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IfanTsai/go-lib/gin/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/ifantsai/simple-bank-api/db/mock"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatePaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	account := randomAccount(requester.Username)
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: requester.Username,
+			body: gin.H{
+				"account_id": account.ID,
+				"payer":      payer.Username,
+				"amount":     100,
+				"currency":   account.Currency,
+				"memo":       "Dinner",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				store.EXPECT().
+					CreatePaymentRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
+						require.Equal(t, account.ID, arg.RequesterAccountID)
+						require.Equal(t, requester.Username, arg.Requester)
+						require.Equal(t, payer.Username, arg.Payer)
+						require.Equal(t, int64(100), arg.Amount)
+						require.Equal(t, "Dinner", arg.Memo)
+						require.WithinDuration(t, time.Now().Add(7*24*time.Hour), arg.ExpiresAt, time.Minute)
+
+						return db.PaymentRequest{ID: 1, Status: db.PaymentRequestStatusPending}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "RequestFromSelf",
+			username: requester.Username,
+			body: gin.H{
+				"account_id": account.ID,
+				"payer":      requester.Username,
+				"amount":     100,
+				"currency":   account.Currency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "ExpiredOnCreation",
+			username: requester.Username,
+			body: gin.H{
+				"account_id": account.ID,
+				"payer":      payer.Username,
+				"amount":     100,
+				"currency":   account.Currency,
+				"expires_at": time.Now().Add(-time.Hour),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: payer.Username,
+			body: gin.H{
+				"account_id": account.ID,
+				"payer":      requester.Username,
+				"amount":     100,
+				"currency":   account.Currency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CreatePaymentRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/v1/payment_requests", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.GetTokenMaker(), middlewares.AuthorizationTypeBear, tc.username, time.Minute)
+			server.Getrouter().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPayPaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	requesterAccount := randomAccount(requester.Username)
+	payerAccount := randomAccount(payer.Username)
+	payerAccount.Currency = requesterAccount.Currency
+
+	paymentRequest := db.PaymentRequest{
+		ID:                 5,
+		RequesterAccountID: requesterAccount.ID,
+		Requester:          requester.Username,
+		Payer:              payer.Username,
+		Amount:             100,
+		Currency:           requesterAccount.Currency,
+		Memo:               "Dinner",
+		Status:             db.PaymentRequestStatusPending,
+		ExpiresAt:          time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payerAccount.ID)).Times(1).Return(payerAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(requesterAccount.ID)).Times(1).Return(requesterAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, payerAccount.ID, arg.FromAccountID)
+						require.Equal(t, requesterAccount.ID, arg.ToAccountID)
+						require.Equal(t, paymentRequest.Amount, arg.Amount)
+						require.Equal(t, paymentRequest.Memo, arg.Description)
+						require.Equal(t, paymentRequest.ID, arg.PaymentRequestID)
+
+						paid := paymentRequest
+						paid.Status = db.PaymentRequestStatusPaid
+						paid.TransferID = sql.NullInt64{Int64: 9, Valid: true}
+
+						return db.TransferTxResult{
+							Transfer: db.Transfer{
+								ID:            9,
+								FromAccountID: payerAccount.ID,
+								ToAccountID:   requesterAccount.ID,
+								Status:        db.TransferStatusCompleted,
+							},
+							FromAccount:    payerAccount,
+							ToAccount:      requesterAccount,
+							PaymentRequest: &paid,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireNoCounterpartyAccountID(t, recorder.Body.Bytes())
+			},
+		},
+		{
+			name:     "NotPayer",
+			username: requester.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyPaid",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(2).Return(payerAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{}, db.ErrPaymentRequestNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: payer.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).
+					Times(1).
+					Return(db.PaymentRequest{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"from_account_id": payerAccount.ID})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/v1/payment_requests/%d/pay", paymentRequest.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.GetTokenMaker(), middlewares.AuthorizationTypeBear, tc.username, time.Minute)
+			server.Getrouter().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeclinePaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+
+	requesterAccount := randomAccount(requester.Username)
+
+	paymentRequest := db.PaymentRequest{
+		ID:                 5,
+		RequesterAccountID: requesterAccount.ID,
+		Requester:          requester.Username,
+		Payer:              payer.Username,
+		Status:             db.PaymentRequestStatusPending,
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				declined := paymentRequest
+				declined.Status = db.PaymentRequestStatusDeclined
+
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().DeclinePaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(declined, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(requesterAccount.ID)).Times(1).Return(requesterAccount, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.NotContains(t, body, "requester_account_id")
+				require.Equal(t, requesterAccount.Number, body["requester_account_number"])
+				require.Equal(t, db.PaymentRequestStatusDeclined, body["status"])
+			},
+		},
+		{
+			name: "NotPending",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).Times(1).Return(paymentRequest, nil)
+				store.EXPECT().
+					DeclinePaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).
+					Times(1).
+					Return(db.PaymentRequest{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/payment_requests/%d/decline", paymentRequest.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.GetTokenMaker(), middlewares.AuthorizationTypeBear, payer.Username, time.Minute)
+			server.Getrouter().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListPaymentRequestsAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	requesterAccount := randomAccount(requester.Username)
+
+	paymentRequest := db.PaymentRequest{
+		ID:                 5,
+		RequesterAccountID: requesterAccount.ID,
+		Requester:          requester.Username,
+		Payer:              payer.Username,
+		Amount:             100,
+		Currency:           requesterAccount.Currency,
+		Status:             db.PaymentRequestStatusPending,
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		direction     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "Incoming",
+			username:  payer.Username,
+			direction: "incoming",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListIncomingPaymentRequests(gomock.Any(), gomock.Eq(db.ListIncomingPaymentRequestsParams{
+						Payer:  payer.Username,
+						Limit:  5,
+						Offset: 0,
+					})).
+					Times(1).
+					Return([]db.ListIncomingPaymentRequestsRow{{
+						ID:                     paymentRequest.ID,
+						RequesterAccountID:     paymentRequest.RequesterAccountID,
+						Requester:              paymentRequest.Requester,
+						Payer:                  paymentRequest.Payer,
+						Amount:                 paymentRequest.Amount,
+						Currency:               paymentRequest.Currency,
+						Status:                 paymentRequest.Status,
+						RequesterAccountNumber: requesterAccount.Number,
+					}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body []map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Len(t, body, 1)
+				require.NotContains(t, body[0], "requester_account_id")
+				require.Equal(t, requesterAccount.Number, body[0]["requester_account_number"])
+				require.Equal(t, requester.Username, body[0]["requester"])
+			},
+		},
+		{
+			name:      "Outgoing",
+			username:  requester.Username,
+			direction: "outgoing",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListOutgoingPaymentRequests(gomock.Any(), gomock.Eq(db.ListOutgoingPaymentRequestsParams{
+						Requester: requester.Username,
+						Limit:     5,
+						Offset:    0,
+					})).
+					Times(1).
+					Return([]db.PaymentRequest{paymentRequest}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var body []db.PaymentRequest
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, []db.PaymentRequest{paymentRequest}, body)
+			},
+		},
+		{
+			name:      "InvalidDirection",
+			username:  payer.Username,
+			direction: "both",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListIncomingPaymentRequests(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListOutgoingPaymentRequests(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/v1/payment_requests?direction=%s&page_id=1&page_size=5", tc.direction)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.GetTokenMaker(), middlewares.AuthorizationTypeBear, tc.username, time.Minute)
+			server.Getrouter().ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
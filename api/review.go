@@ -0,0 +1,121 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/IfanTsai/go-lib/gin/middlewares"
+	"github.com/gin-gonic/gin"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/util"
+)
+
+type reviewTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listTransfersForReviewRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (s *Server) listTransfersForReview(c *gin.Context) {
+	var req listTransfersForReviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.authorizeBanker(c); !ok {
+		return
+	}
+
+	transfers, err := s.store.ListTransfersForReview(c, db.ListTransfersForReviewParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+func (s *Server) approveTransfer(c *gin.Context) {
+	s.reviewTransfer(c, true)
+}
+
+func (s *Server) rejectTransfer(c *gin.Context) {
+	s.reviewTransfer(c, false)
+}
+
+func (s *Server) reviewTransfer(c *gin.Context, approve bool) {
+	var req reviewTransferRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	banker, ok := s.authorizeBanker(c)
+	if !ok {
+		return
+	}
+
+	result, err := s.store.ReviewTransferTx(c, db.ReviewTransferTxParams{
+		TransferID: req.ID,
+		Approve:    approve,
+		Audit:      auditMeta(c, banker.Username),
+	})
+	if err != nil {
+		var limitErr *db.LimitExceededError
+
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, db.ErrTransferNotPending), errors.Is(err, db.ErrAccountClosed):
+			c.JSON(http.StatusConflict, errorResponse(err))
+		case errors.As(err, &limitErr):
+			c.JSON(http.StatusForbidden, limitExceededResponse(limitErr))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// authorizeBanker returns the authenticated user if it is a banker.
+func (s *Server) authorizeBanker(c *gin.Context) (db.User, bool) {
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return db.User{}, false
+	}
+
+	user, err := s.store.GetUser(c, username)
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusUnauthorized
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return user, false
+	}
+
+	if user.Role != util.BankerRole {
+		err := errors.New("only bankers are allowed to do this")
+		c.JSON(http.StatusForbidden, errorResponse(err))
+
+		return user, false
+	}
+
+	return user, true
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/fee"
+	"github.com/ifantsai/simple-bank-api/risk"
 	"github.com/ifantsai/simple-bank-api/util"
 	"github.com/pkg/errors"
 )
@@ -22,6 +24,8 @@ type Server struct {
 	config     util.Config
 	store      db.Store
 	tokenMaker token.Maker
+	fees       *fee.Schedule
+	risk       risk.Evaluator
 	router     *gin.Engine
 	server     *http.Server
 	address    string
@@ -33,16 +37,47 @@ func NewServer(config util.Config, store db.Store, address string) (*Server, err
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot create token")
 	}
+
+	fees, err := fee.LoadSchedule(config.FeeRulesPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load fee schedule")
+	}
+
 	server := &Server{
 		config:     config,
 		store:      store,
 		address:    address,
 		tokenMaker: tokenMaker,
+		fees:       fees,
+		risk: risk.NewRulesEvaluator(store, risk.Config{
+			VelocityWindow:          config.RiskVelocityWindow,
+			VelocityMaxTransfers:    config.RiskVelocityMaxTransfers,
+			UnusualAmountFactor:     config.RiskUnusualAmountFactor,
+			UnusualAmountMinHistory: config.RiskUnusualAmountMinHistory,
+			NewPayeeReviewAmount:    config.RiskNewPayeeReviewAmount,
+			NewClientReviewAmount:   config.RiskNewClientReviewAmount,
+		}),
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		if err := v.RegisterValidation("currency", validCurrency); err != nil {
-			log.Fatalln("cannot register currency validation, err:", err)
+		validations := map[string]validator.Func{
+			"username":             validUsername,
+			"currency":             validCurrency,
+			"transfer_description": validTransferDescription,
+			"transfer_category":    validTransferCategory,
+			"client_reference":     validClientReference,
+			"search_query":         validSearchQuery,
+			"account_type":         validAccountType,
+			"account_nickname":     validAccountNickname,
+			"account_number":       validAccountNumber,
+			"account_ref":          validAccountRef,
+			"webhook_event":        validWebhookEvent,
+		}
+
+		for tag, fn := range validations {
+			if err := v.RegisterValidation(tag, fn); err != nil {
+				log.Fatalf("cannot register %s validation, err: %s", tag, err)
+			}
 		}
 	}
 
@@ -76,10 +111,35 @@ func (s *Server) setupRouter() {
 	v1API.POST("token/refresh_access", s.refreshAccessToken)
 
 	authRoutes := v1API.Use(middlewares.Authorization(version, s.tokenMaker))
+	authRoutes.PATCH("users/settings", s.updateUserSettings)
 	authRoutes.POST("accounts", s.createAccount)
 	authRoutes.GET("accounts/:id", s.getAccount)
 	authRoutes.GET("accounts", s.listAccount)
+	authRoutes.PATCH("accounts/:id", s.updateAccount)
+	authRoutes.DELETE("accounts/:id", s.closeAccount)
+	authRoutes.PUT("accounts/:id/interest_rate", s.setAccountInterestRate)
+	authRoutes.PUT("accounts/:id/limits", s.setAccountLimits)
+	authRoutes.PUT("tiers/:tier/limits/:currency", s.setTierLimits)
+	authRoutes.PUT("users/:username/tier", s.setUserTier)
+	authRoutes.POST("payees", s.createPayee)
+	authRoutes.GET("payees", s.listPayees)
+	authRoutes.DELETE("payees/:id", s.deletePayee)
+	authRoutes.POST("payment_requests", s.createPaymentRequest)
+	authRoutes.GET("payment_requests", s.listPaymentRequests)
+	authRoutes.POST("payment_requests/:id/decline", s.declinePaymentRequest)
+	authRoutes.POST("payment_requests/:id/pay", s.payPaymentRequest)
+	authRoutes.POST("webhooks", s.createWebhook)
+	authRoutes.GET("webhooks", s.listWebhooks)
+	authRoutes.DELETE("webhooks/:id", s.deleteWebhook)
+	authRoutes.GET("webhooks/:id/deliveries", s.listWebhookDeliveries)
+	authRoutes.POST("webhooks/:id/deliveries/:delivery_id/redeliver", s.redeliverWebhook)
 	authRoutes.POST("transfers", s.createTransfer)
+	authRoutes.POST("transfers/quote", s.quoteTransfer)
+	authRoutes.GET("transfers", s.listTransfers)
+	authRoutes.GET("transfers/search", s.searchTransfers)
+	authRoutes.GET("transfers/review", s.listTransfersForReview)
+	authRoutes.POST("transfers/:id/approve", s.approveTransfer)
+	authRoutes.POST("transfers/:id/reject", s.rejectTransfer)
 
 	s.router = router
 }
@@ -106,6 +166,11 @@ func (s *Server) Stop(ctx context.Context) error {
 	return errors.Wrap(s.server.Shutdown(ctx), "failed to shutdown http server")
 }
 
+// SetRiskEvaluator replaces the built-in risk rules of transfers.
+func (s *Server) SetRiskEvaluator(evaluator risk.Evaluator) {
+	s.risk = evaluator
+}
+
 func (s *Server) GetTokenMaker() token.Maker {
 	return s.tokenMaker
 }
@@ -114,6 +179,14 @@ func (s *Server) Getrouter() *gin.Engine {
 	return s.router
 }
 
+// auditMeta describes the authenticated user and client of a request for the audit log.
+func auditMeta(c *gin.Context, username string) db.AuditMeta {
+	return db.AuditMeta{
+		Actor:    username,
+		ClientIP: c.ClientIP(),
+	}
+}
+
 func errorResponse(err error) gin.H {
 	return gin.H{
 		"error": err.Error(),
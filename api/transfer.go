@@ -4,18 +4,117 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/IfanTsai/go-lib/gin/middlewares"
 	"github.com/gin-gonic/gin"
 	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/fee"
+	"github.com/ifantsai/simple-bank-api/risk"
+	"github.com/ifantsai/simple-bank-api/util"
 	xerrors "github.com/pkg/errors"
 )
 
+// transferRequest takes the accounts either by id or by account number.
 type transferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,excluded_with=FromAccountNumber,omitempty,min=1"`               //nolint: lll
+	FromAccountNumber string `json:"from_account_number" binding:"omitempty,account_number"`                                                                     //nolint: lll
+	ToAccountID       int64  `json:"to_account_id" binding:"required_without_all=ToAccountNumber PayeeID,excluded_with=ToAccountNumber PayeeID,omitempty,min=1"` //nolint: lll
+	ToAccountNumber   string `json:"to_account_number" binding:"omitempty,excluded_with=PayeeID,account_number"`                                                 //nolint: lll
+	PayeeID           int64  `json:"payee_id" binding:"omitempty,min=1"`
+	Amount            int64  `json:"amount" binding:"required,gt=0"`
+	Currency          string `json:"currency" binding:"required,currency"`
+	Description       string `json:"description" binding:"omitempty,transfer_description"`
+	Category          string `json:"category" binding:"omitempty,transfer_category"`
+	ClientReference   string `json:"client_reference" binding:"omitempty,client_reference"`
+	Instant           bool   `json:"instant"`
+}
+
+type quoteTransferRequest struct {
+	FromAccountID     int64  `json:"from_account_id" binding:"required_without=FromAccountNumber,excluded_with=FromAccountNumber,omitempty,min=1"`               //nolint: lll
+	FromAccountNumber string `json:"from_account_number" binding:"omitempty,account_number"`                                                                     //nolint: lll
+	ToAccountID       int64  `json:"to_account_id" binding:"required_without_all=ToAccountNumber PayeeID,excluded_with=ToAccountNumber PayeeID,omitempty,min=1"` //nolint: lll
+	ToAccountNumber   string `json:"to_account_number" binding:"omitempty,excluded_with=PayeeID,account_number"`                                                 //nolint: lll
+	PayeeID           int64  `json:"payee_id" binding:"omitempty,min=1"`
+	Amount            int64  `json:"amount" binding:"required,gt=0"`
+	Currency          string `json:"currency" binding:"required,currency"`
+	Instant           bool   `json:"instant"`
+}
+
+// transferResponse hides the account of the recipient unless it belongs to the sender.
+type transferResponse struct {
+	Transfer       db.Transfer             `json:"transfer"`
+	PayeeID        int64                   `json:"payee_id,omitempty"`
+	FromAccount    db.Account              `json:"from_account"`
+	ToAccount      *db.Account             `json:"to_account,omitempty"`
+	FromEntry      db.Entry                `json:"from_entry"`
+	ToEntry        *db.Entry               `json:"to_entry,omitempty"`
+	FeeEntry       *db.Entry               `json:"fee_entry,omitempty"`
+	RiskAssessment *db.RiskAssessment      `json:"risk_assessment,omitempty"`
+	PaymentRequest *incomingPaymentRequest `json:"payment_request,omitempty"`
+}
+
+func newTransferResponse(result db.TransferTxResult, username string, payeeID int64) transferResponse {
+	rsp := transferResponse{
+		Transfer:       result.Transfer,
+		PayeeID:        payeeID,
+		FromAccount:    result.FromAccount,
+		FromEntry:      result.FromEntry,
+		RiskAssessment: result.RiskAssessment,
+	}
+
+	if result.ToAccount.Owner == username {
+		rsp.ToAccount = &result.ToAccount
+		rsp.ToEntry = &result.ToEntry
+	}
+
+	if result.FeeEntry.ID != 0 {
+		rsp.FeeEntry = &result.FeeEntry
+	}
+
+	// a payment request is paid by its payer, who is the sender
+	if result.PaymentRequest != nil {
+		request := newIncomingPaymentRequest(*result.PaymentRequest, result.ToAccount.Number)
+		rsp.PaymentRequest = &request
+	}
+
+	// the sender must not learn the internal id of another user's account, whichever way it was addressed
+	if rsp.ToAccount == nil {
+		rsp.Transfer.ToAccountID = 0
+
+		if rsp.RiskAssessment != nil {
+			assessment := *rsp.RiskAssessment
+			assessment.ToAccountID = 0
+			rsp.RiskAssessment = &assessment
+		}
+	}
+
+	return rsp
+}
+
+type listTransfersRequest struct {
+	AccountID     int64  `form:"account_id" binding:"required_without=AccountNumber,excluded_with=AccountNumber,omitempty,min=1"` //nolint: lll
+	AccountNumber string `form:"account_number" binding:"omitempty,account_number"`
+	Query         string `form:"q" binding:"omitempty,search_query"`
+	PageID        int32  `form:"page_id" binding:"required,min=1"`
+	PageSize      int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+type searchTransfersRequest struct {
+	Direction                 string     `form:"direction" binding:"omitempty,oneof=in out both"`
+	AccountID                 *int64     `form:"account_id" binding:"omitempty,excluded_with=AccountNumber,min=1"`
+	AccountNumber             string     `form:"account_number" binding:"omitempty,account_number"`
+	CounterpartyAccountID     *int64     `form:"counterparty_account_id" binding:"omitempty,excluded_with=CounterpartyAccountNumber,min=1"` //nolint: lll
+	CounterpartyAccountNumber string     `form:"counterparty_account_number" binding:"omitempty,account_number"`
+	MinAmount                 *int64     `form:"min_amount" binding:"omitempty,min=0"`
+	MaxAmount                 *int64     `form:"max_amount" binding:"omitempty,min=0"`
+	StartTime                 *time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime                   *time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	Currency                  string     `form:"currency" binding:"omitempty,currency"`
+	Sort                      string     `form:"sort" binding:"omitempty,oneof=created_at_asc created_at_desc amount_asc amount_desc"` //nolint: lll
+	PageID                    int32      `form:"page_id" binding:"required,min=1"`
+	PageSize                  int32      `form:"page_size" binding:"required,min=5,max=10"`
 }
 
 func (s *Server) createTransfer(c *gin.Context) {
@@ -26,7 +125,244 @@ func (s *Server) createTransfer(c *gin.Context) {
 		return
 	}
 
-	fromAccount, valid := s.validAccount(c, req.FromAccountID, req.Currency)
+	to, valid := s.transferRecipient(c, accountRef{ID: req.ToAccountID, Number: req.ToAccountNumber}, req.PayeeID)
+	if !valid {
+		return
+	}
+
+	from := accountRef{ID: req.FromAccountID, Number: req.FromAccountNumber}
+
+	fromAccount, toAccount, valid := s.validTransferAccounts(c, from, to, req.Currency)
+	if !valid {
+		return
+	}
+
+	if req.PayeeID == 0 && toAccount.Owner != fromAccount.Owner {
+		if !s.allowedRecipient(c, fromAccount.Owner, toAccount.ID) {
+			return
+		}
+	}
+
+	result, ok := s.sendMoney(c, fromAccount, toAccount, db.TransferTxParams{
+		FromAccountID:   fromAccount.ID,
+		ToAccountID:     toAccount.ID,
+		Amount:          req.Amount,
+		Description:     req.Description,
+		Category:        req.Category,
+		ClientReference: req.ClientReference,
+		Audit:           auditMeta(c, fromAccount.Owner),
+	}, req.Instant)
+	if !ok {
+		return
+	}
+
+	writeTransferResponse(c, result, newTransferResponse(result, fromAccount.Owner, req.PayeeID))
+}
+
+// sendMoney charges the transfer fee, evaluates the risk and executes a transfer between checked accounts.
+// It responds with the error and returns false when the transfer is not executed.
+func (s *Server) sendMoney(
+	c *gin.Context,
+	fromAccount, toAccount db.Account,
+	arg db.TransferTxParams,
+	instant bool,
+) (db.TransferTxResult, bool) {
+	quote := s.fees.Quote(fee.Transfer{
+		Amount:   arg.Amount,
+		Currency: fromAccount.Currency,
+		Instant:  instant,
+	})
+
+	arg.Fee = quote.Fee
+
+	if arg.Fee > 0 {
+		feeAccount, err := s.store.GetSystemAccount(c, db.GetSystemAccountParams{
+			Owner:    util.BankUsername,
+			Currency: fromAccount.Currency,
+			Type:     util.FeeRevenue,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return db.TransferTxResult{}, false
+		}
+
+		arg.FeeAccountID = feeAccount.ID
+	}
+
+	decision, err := s.risk.Evaluate(c, risk.Transfer{
+		Username:      fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        arg.Amount,
+		ClientIP:      c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return db.TransferTxResult{}, false
+	}
+
+	if decision.Outcome == db.RiskOutcomeDeny {
+		s.denyTransfer(c, arg, decision)
+
+		return db.TransferTxResult{}, false
+	}
+
+	arg.Risk = &db.RiskDecision{
+		Outcome:   decision.Outcome,
+		Reason:    decision.Reason,
+		Username:  fromAccount.Owner,
+		ClientIP:  c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+
+	result, err := s.store.TransferTx(c, arg)
+	if err != nil {
+		var limitErr *db.LimitExceededError
+
+		switch {
+		case errors.As(err, &limitErr):
+			c.JSON(http.StatusForbidden, limitExceededResponse(limitErr))
+		case errors.Is(err, db.ErrPaymentRequestNotPending), errors.Is(err, db.ErrPaymentRequestExpired),
+			errors.Is(err, db.ErrAccountClosed):
+			c.JSON(http.StatusForbidden, errorResponse(err))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return result, false
+	}
+
+	return result, true
+}
+
+// writeTransferResponse responds with the executed transfer, a transfer held until a banker reviews it is accepted.
+func writeTransferResponse(c *gin.Context, result db.TransferTxResult, rsp transferResponse) {
+	if result.Transfer.Status == db.TransferStatusPendingReview {
+		c.JSON(http.StatusAccepted, rsp)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, rsp)
+}
+
+// denyTransfer records a transfer denied by the risk rules without executing it.
+func (s *Server) denyTransfer(c *gin.Context, arg db.TransferTxParams, decision risk.Decision) {
+	_, err := s.store.CreateRiskAssessment(c, db.CreateRiskAssessmentParams{
+		Username:      arg.Audit.Actor,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ClientIp:      c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+		Outcome:       decision.Outcome,
+		Reason:        decision.Reason,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	err = xerrors.Errorf("transfer denied: %s", decision.Reason)
+	c.JSON(http.StatusForbidden, errorResponse(err))
+}
+
+func (s *Server) quoteTransfer(c *gin.Context) {
+	var req quoteTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	to, valid := s.transferRecipient(c, accountRef{ID: req.ToAccountID, Number: req.ToAccountNumber}, req.PayeeID)
+	if !valid {
+		return
+	}
+
+	from := accountRef{ID: req.FromAccountID, Number: req.FromAccountNumber}
+
+	fromAccount, _, valid := s.validTransferAccounts(c, from, to, req.Currency)
+	if !valid {
+		return
+	}
+
+	quote := s.fees.Quote(fee.Transfer{
+		Amount:   req.Amount,
+		Currency: fromAccount.Currency,
+		Instant:  req.Instant,
+	})
+
+	c.JSON(http.StatusOK, quote)
+}
+
+// limitExceededResponse tells the client which limit was exceeded and how much can still be sent.
+func limitExceededResponse(err *db.LimitExceededError) gin.H {
+	return gin.H{
+		"error":     err.Error(),
+		"scope":     err.Scope,
+		"period":    err.Period,
+		"limit":     err.Limit,
+		"remaining": err.Remaining,
+	}
+}
+
+// transferRecipient returns the account to send money to, either given directly or by a saved payee.
+func (s *Server) transferRecipient(c *gin.Context, to accountRef, payeeID int64) (accountRef, bool) {
+	if payeeID == 0 {
+		return to, true
+	}
+
+	payee, valid := s.validPayee(c, payeeID)
+
+	return accountRef{ID: payee.AccountID}, valid
+}
+
+// allowedRecipient checks that a user who only sends money to saved payees has saved the account.
+func (s *Server) allowedRecipient(c *gin.Context, username string, accountID int64) bool {
+	user, err := s.store.GetUser(c, username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return false
+	}
+
+	if !user.RequireSavedPayee {
+		return true
+	}
+
+	_, err = s.store.GetPayeeByAccount(c, db.GetPayeeByAccountParams{
+		Owner:     username,
+		AccountID: accountID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err := errors.New("transfers are only allowed to saved payees")
+			c.JSON(http.StatusForbidden, errorResponse(err))
+
+			return false
+		}
+
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return false
+	}
+
+	return true
+}
+
+// validTransferAccounts checks that both accounts exist in the currency
+// and that the sender account belongs to the authenticated user.
+func (s *Server) validTransferAccounts(
+	c *gin.Context,
+	from, to accountRef,
+	currency string,
+) (fromAccount db.Account, toAccount db.Account, valid bool) {
+	fromAccount, valid = s.validAccount(c, from, currency)
 	if !valid {
 		return
 	}
@@ -34,35 +370,127 @@ func (s *Server) createTransfer(c *gin.Context) {
 	// A logged-in user can only send money from his/her own account
 	username, err := middlewares.GetUsername(c)
 	if err != nil {
-		return
+		return fromAccount, toAccount, false
 	}
 
 	if fromAccount.Owner != username {
 		err := errors.New("from account doesn't belong to the authenticated user")
 		c.JSON(http.StatusUnauthorized, errorResponse(err))
 
+		return fromAccount, toAccount, false
+	}
+
+	toAccount, valid = s.validAccount(c, to, currency)
+
+	return
+}
+
+// transferItem is a transfer in a list of the authenticated user,
+// the accounts of other users are only given by their account number.
+type transferItem struct {
+	ID                int64     `json:"id"`
+	FromAccountID     int64     `json:"from_account_id,omitempty"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountID       int64     `json:"to_account_id,omitempty"`
+	ToAccountNumber   string    `json:"to_account_number"`
+	Amount            int64     `json:"amount"`
+	Fee               int64     `json:"fee,omitempty"`
+	Description       string    `json:"description"`
+	Category          string    `json:"category"`
+	ClientReference   string    `json:"client_reference"`
+	Status            string    `json:"status"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func newTransferItem(transfer db.TransferWithAccounts, username string) transferItem {
+	item := transferItem{
+		ID:                transfer.ID,
+		FromAccountNumber: transfer.FromAccountNumber,
+		ToAccountNumber:   transfer.ToAccountNumber,
+		Amount:            transfer.Amount,
+		Description:       transfer.Description,
+		Category:          transfer.Category,
+		ClientReference:   transfer.ClientReference,
+		Status:            transfer.Status,
+		CreatedAt:         transfer.CreatedAt,
+	}
+
+	// the fee is charged to the sender, so only the sender sees it
+	if transfer.FromAccountOwner == username {
+		item.FromAccountID = transfer.FromAccountID
+		item.Fee = transfer.Fee
+	}
+
+	if transfer.ToAccountOwner == username {
+		item.ToAccountID = transfer.ToAccountID
+	}
+
+	return item
+}
+
+func (s *Server) listTransfers(c *gin.Context) {
+	var req listTransfersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
 		return
 	}
 
-	_, valid = s.validAccount(c, req.ToAccountID, req.Currency)
-	if !valid {
+	account, err := s.findAccount(c, accountRef{ID: req.AccountID, Number: req.AccountNumber})
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
 		return
 	}
 
-	result, err := s.store.TransferTx(c, db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+	// A logged-in user can only list transfers of his/her own account
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	if account.Owner != username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		c.JSON(http.StatusUnauthorized, errorResponse(err))
+
+		return
+	}
+
+	transfers, err := s.store.ListTransfers(c, db.ListTransfersParams{
+		FromAccountID: account.ID,
+		ToAccountID:   account.ID,
+		Search:        escapeLike(req.Query),
+		Limit:         req.PageSize,
+		Offset:        (req.PageID - 1) * req.PageSize,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	items := make([]transferItem, len(transfers))
+	for i, transfer := range transfers {
+		items[i] = newTransferItem(db.TransferWithAccounts(transfer), username)
+	}
+
+	c.JSON(http.StatusOK, items)
 }
 
-func (s *Server) validAccount(c *gin.Context, accountID int64, currency string) (db.Account, bool) {
-	account, err := s.store.GetAccount(c, accountID)
+// likeEscaper escapes the wildcards of LIKE patterns, so that search terms match literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(search string) string {
+	return likeEscaper.Replace(search)
+}
+
+func (s *Server) validAccount(c *gin.Context, ref accountRef, currency string) (db.Account, bool) {
+	account, err := s.findAccount(c, ref)
 	if err != nil {
 		httpCode := http.StatusInternalServerError
 		if errors.Is(err, sql.ErrNoRows) {
@@ -75,11 +503,134 @@ func (s *Server) validAccount(c *gin.Context, accountID int64, currency string)
 	}
 
 	if account.Currency != currency {
-		err := xerrors.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
+		err := xerrors.Errorf("account [%s] currency mismatch: %s vs %s", account.Number, account.Currency, currency)
 		c.JSON(http.StatusBadRequest, errorResponse(err))
 
 		return account, false
 	}
 
+	if account.Status == db.AccountStatusClosed {
+		err := xerrors.Errorf("account [%s] is closed", account.Number)
+		c.JSON(http.StatusForbidden, errorResponse(err))
+
+		return account, false
+	}
+
 	return account, true
 }
+
+func (s *Server) searchTransfers(c *gin.Context) {
+	var req searchTransfersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if req.MinAmount != nil && req.MaxAmount != nil && *req.MinAmount > *req.MaxAmount {
+		err := errors.New("min_amount must not be greater than max_amount")
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if req.StartTime != nil && req.EndTime != nil && !req.StartTime.Before(*req.EndTime) {
+		err := errors.New("start_time must be before end_time")
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	// A logged-in user can only search transfers of his/her own accounts
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	arg := db.SearchTransfersParams{
+		Direction: req.Direction,
+		Owner:     username,
+		Sort:      req.Sort,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	}
+
+	if arg.Direction == "" {
+		arg.Direction = "both"
+	}
+
+	if arg.Sort == "" {
+		arg.Sort = db.TransferSortCreatedAtDesc
+	}
+
+	var valid bool
+
+	arg.AccountID, valid = s.searchAccountID(c, req.AccountID, req.AccountNumber)
+	if !valid {
+		return
+	}
+
+	arg.CounterpartyAccountID, valid = s.searchAccountID(c, req.CounterpartyAccountID, req.CounterpartyAccountNumber)
+	if !valid {
+		return
+	}
+
+	if req.MinAmount != nil {
+		arg.MinAmount = sql.NullInt64{Int64: *req.MinAmount, Valid: true}
+	}
+
+	if req.MaxAmount != nil {
+		arg.MaxAmount = sql.NullInt64{Int64: *req.MaxAmount, Valid: true}
+	}
+
+	if req.StartTime != nil {
+		arg.StartTime = sql.NullTime{Time: *req.StartTime, Valid: true}
+	}
+
+	if req.EndTime != nil {
+		arg.EndTime = sql.NullTime{Time: *req.EndTime, Valid: true}
+	}
+
+	if req.Currency != "" {
+		arg.Currency = sql.NullString{String: req.Currency, Valid: true}
+	}
+
+	transfers, err := s.store.SearchTransfers(c, arg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	items := make([]transferItem, len(transfers))
+	for i, transfer := range transfers {
+		items[i] = newTransferItem(transfer, username)
+	}
+
+	c.JSON(http.StatusOK, items)
+}
+
+// searchAccountID resolves an optional search filter given by account id or account number.
+func (s *Server) searchAccountID(c *gin.Context, id *int64, number string) (sql.NullInt64, bool) {
+	if number == "" {
+		if id == nil {
+			return sql.NullInt64{}, true
+		}
+
+		return sql.NullInt64{Int64: *id, Valid: true}, true
+	}
+
+	account, err := s.store.GetAccountByNumber(c, number)
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return sql.NullInt64{}, false
+	}
+
+	return sql.NullInt64{Int64: account.ID, Valid: true}, true
+}
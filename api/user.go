@@ -8,13 +8,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/metrics"
 	"github.com/ifantsai/simple-bank-api/util"
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
 )
 
 type createUserRequest struct {
-	Username string `json:"username" binding:"required,alphanum"`
+	Username string `json:"username" binding:"required,alphanum,username"`
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
@@ -25,6 +26,8 @@ type userResponse struct {
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
+	RequireSavedPayee bool      `json:"require_saved_payee"`
+	Tier              string    `json:"tier"`
 	CreatedAt         time.Time `json:"created_at"`
 }
 
@@ -48,6 +51,8 @@ func newUserResponse(user db.User) userResponse {
 		FullName:          user.FullName,
 		Email:             user.Email,
 		PasswordChangedAt: user.PasswordChangedAt,
+		RequireSavedPayee: user.RequireSavedPayee,
+		Tier:              user.Tier,
 		CreatedAt:         user.CreatedAt,
 	}
 }
@@ -67,11 +72,13 @@ func (s *Server) createUser(c *gin.Context) {
 		return
 	}
 
-	user, err := s.store.CreateUser(c, db.CreateUserParams{
-		Username:       req.Username,
-		HashedPassword: hashedPassword,
-		FullName:       req.FullName,
-		Email:          req.Email,
+	user, err := s.store.CreateUserTx(c, db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       req.Username,
+			HashedPassword: hashedPassword,
+			FullName:       req.FullName,
+			Email:          req.Email,
+		},
 	})
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok { //nolint: errorlint
@@ -103,6 +110,7 @@ func (s *Server) loginUser(c *gin.Context) {
 		errorHTTPCode := http.StatusInternalServerError
 		if errors.Is(errors.Cause(err), sql.ErrNoRows) {
 			errorHTTPCode = http.StatusNotFound
+			metrics.LoginFailed(metrics.LoginFailureNotFound)
 		}
 
 		c.JSON(errorHTTPCode, errorResponse(err))
@@ -111,6 +119,8 @@ func (s *Server) loginUser(c *gin.Context) {
 	}
 
 	if err = util.CheckPassword(req.Password, user.HashedPassword); err != nil {
+		metrics.LoginFailed(metrics.LoginFailureWrongPassword)
+
 		c.JSON(http.StatusUnauthorized, errorResponse(err))
 
 		return
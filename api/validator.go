@@ -1,8 +1,12 @@
 package api
 
 import (
+	"strconv"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/ifantsai/simple-bank-api/util"
+	bankvalidator "github.com/ifantsai/simple-bank-api/validator"
+	"github.com/ifantsai/simple-bank-api/webhook"
 )
 
 var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
@@ -13,3 +17,54 @@ var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
 
 	return false
 }
+
+var validWebhookEvent validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if eventType, ok := fieldLevel.Field().Interface().(string); ok {
+		return webhook.IsSupportedEventType(eventType)
+	}
+
+	return false
+}
+
+var validAccountType validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if accountType, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedAccountType(accountType)
+	}
+
+	return false
+}
+
+// stringValidation adapts a validation function of the validator package to gin binding.
+func stringValidation(validate func(string) error) validator.Func {
+	return func(fieldLevel validator.FieldLevel) bool {
+		if value, ok := fieldLevel.Field().Interface().(string); ok {
+			return validate(value) == nil
+		}
+
+		return false
+	}
+}
+
+var (
+	validUsername            = stringValidation(bankvalidator.ValidateUsername)
+	validTransferDescription = stringValidation(bankvalidator.ValidateTransferDescription)
+	validTransferCategory    = stringValidation(bankvalidator.ValidateTransferCategory)
+	validClientReference     = stringValidation(bankvalidator.ValidateClientReference)
+	validSearchQuery         = stringValidation(bankvalidator.ValidateSearchQuery)
+	validAccountNickname     = stringValidation(bankvalidator.ValidateAccountNickname)
+	validAccountNumber       = stringValidation(bankvalidator.ValidateAccountNumber)
+)
+
+// validAccountRef accepts either a positive account id or a valid account number.
+var validAccountRef validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	value, ok := fieldLevel.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return id > 0
+	}
+
+	return bankvalidator.ValidateAccountNumber(value) == nil
+}
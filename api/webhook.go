@@ -0,0 +1,273 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/IfanTsai/go-lib/gin/middlewares"
+	"github.com/gin-gonic/gin"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/webhook"
+	"github.com/lib/pq"
+)
+
+type createWebhookRequest struct {
+	URL                 string   `json:"url" binding:"required,url"`
+	EventTypes          []string `json:"event_types" binding:"required,min=1,unique,dive,webhook_event"`
+	LowBalanceThreshold int64    `json:"low_balance_threshold" binding:"omitempty,min=0"`
+}
+
+type getWebhookRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getWebhookDeliveryRequest struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+type listWebhooksRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// webhookResponse describes a webhook subscription without its secret.
+type webhookResponse struct {
+	ID                  int64     `json:"id"`
+	URL                 string    `json:"url"`
+	EventTypes          []string  `json:"event_types"`
+	LowBalanceThreshold int64     `json:"low_balance_threshold"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+func newWebhookResponse(subscription db.WebhookSubscription) webhookResponse {
+	return webhookResponse{
+		ID:                  subscription.ID,
+		URL:                 subscription.Url,
+		EventTypes:          subscription.EventTypes,
+		LowBalanceThreshold: subscription.LowBalanceThreshold,
+		CreatedAt:           subscription.CreatedAt,
+	}
+}
+
+// createWebhookResponse is the only response that contains the secret to verify the payload signatures.
+type createWebhookResponse struct {
+	webhookResponse
+	Secret string `json:"secret"`
+}
+
+func (s *Server) createWebhook(c *gin.Context) {
+	var req createWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if err := webhook.ValidateURL(c, req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	subscription, err := s.store.CreateWebhookSubscription(c, db.CreateWebhookSubscriptionParams{
+		Owner:               username,
+		Url:                 req.URL,
+		Secret:              secret,
+		EventTypes:          req.EventTypes,
+		LowBalanceThreshold: req.LowBalanceThreshold,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok { //nolint: errorlint
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				c.JSON(http.StatusForbidden, errorResponse(err))
+
+				return
+			}
+		}
+
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, createWebhookResponse{
+		webhookResponse: newWebhookResponse(subscription),
+		Secret:          subscription.Secret,
+	})
+}
+
+func (s *Server) listWebhooks(c *gin.Context) {
+	var req listWebhooksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return
+	}
+
+	subscriptions, err := s.store.ListWebhookSubscriptions(c, db.ListWebhookSubscriptionsParams{
+		Owner:  username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	rsp := make([]webhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		rsp = append(rsp, newWebhookResponse(subscription))
+	}
+
+	c.JSON(http.StatusOK, rsp)
+}
+
+func (s *Server) deleteWebhook(c *gin.Context) {
+	var req getWebhookRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	subscription, valid := s.validWebhook(c, req.ID)
+	if !valid {
+		return
+	}
+
+	if err := s.store.DeleteWebhookSubscription(c, subscription.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newWebhookResponse(subscription))
+}
+
+func (s *Server) listWebhookDeliveries(c *gin.Context) {
+	var uri getWebhookRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var req listWebhooksRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	subscription, valid := s.validWebhook(c, uri.ID)
+	if !valid {
+		return
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(c, db.ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          req.PageSize,
+		Offset:         (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// redeliverWebhook schedules a delivery to be attempted again right away, even if it already succeeded.
+func (s *Server) redeliverWebhook(c *gin.Context) {
+	var req getWebhookDeliveryRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	subscription, valid := s.validWebhook(c, req.ID)
+	if !valid {
+		return
+	}
+
+	delivery, err := s.store.GetWebhookDelivery(c, req.DeliveryID)
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return
+	}
+
+	if delivery.SubscriptionID != subscription.ID {
+		err := errors.New("delivery doesn't belong to the webhook")
+		c.JSON(http.StatusNotFound, errorResponse(err))
+
+		return
+	}
+
+	delivery, err = s.store.RedeliverWebhookDelivery(c, delivery.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// validWebhook checks that the webhook subscription exists and belongs to the authenticated user.
+func (s *Server) validWebhook(c *gin.Context, id int64) (db.WebhookSubscription, bool) {
+	subscription, err := s.store.GetWebhookSubscription(c, id)
+	if err != nil {
+		httpCode := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			httpCode = http.StatusNotFound
+		}
+
+		c.JSON(httpCode, errorResponse(err))
+
+		return subscription, false
+	}
+
+	username, err := middlewares.GetUsername(c)
+	if err != nil {
+		return subscription, false
+	}
+
+	if subscription.Owner != username {
+		err := errors.New("webhook doesn't belong to the authenticated user")
+		c.JSON(http.StatusUnauthorized, errorResponse(err))
+
+		return subscription, false
+	}
+
+	return subscription, true
+}
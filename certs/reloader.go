@@ -0,0 +1,202 @@
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// reloadInterval is how often the certificate files are checked for changes.
+const reloadInterval = 10 * time.Second
+
+// Reloader keeps the server certificate and the CA of client certificates loaded from disk,
+// and reloads them when the files change, so that renewed certificates are served without a restart.
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+
+	cert      atomic.Pointer[tls.Certificate]
+	clientCAs atomic.Pointer[x509.CertPool]
+
+	// mu guards the content of the files which was loaded last.
+	mu       sync.Mutex
+	certPEM  []byte
+	keyPEM   []byte
+	clientCA []byte
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewReloader creates a new reloader and loads the certificate and key.
+// The client CA is optional, it is only needed to verify client certificates.
+func NewReloader(certFile string, keyFile string, clientCAFile string) (*Reloader, error) {
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCAFile: clientCAFile,
+		done:         make(chan struct{}),
+	}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload loads the files again and reports whether they changed.
+// The certificates in use are kept if the files are invalid, e.g. while they are being replaced.
+func (r *Reloader) Reload() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	certPEM, err := os.ReadFile(r.certFile)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot read certificate")
+	}
+
+	keyPEM, err := os.ReadFile(r.keyFile)
+	if err != nil {
+		return false, errors.Wrap(err, "cannot read key")
+	}
+
+	var clientCA []byte
+	if r.clientCAFile != "" {
+		if clientCA, err = os.ReadFile(r.clientCAFile); err != nil {
+			return false, errors.Wrap(err, "cannot read client CA")
+		}
+	}
+
+	if bytes.Equal(certPEM, r.certPEM) && bytes.Equal(keyPEM, r.keyPEM) && bytes.Equal(clientCA, r.clientCA) {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid certificate")
+	}
+
+	clientCAs := x509.NewCertPool()
+	if r.clientCAFile != "" && !clientCAs.AppendCertsFromPEM(clientCA) {
+		return false, errors.New("invalid client CA")
+	}
+
+	r.cert.Store(&cert)
+	r.clientCAs.Store(clientCAs)
+	r.certPEM, r.keyPEM, r.clientCA = certPEM, keyPEM, clientCA
+
+	return true, nil
+}
+
+// ServerConfig returns the TLS config of a server which always uses the latest certificates.
+// If clientAuth is set, clients must present a certificate signed by the client CA.
+func (r *Reloader) ServerConfig(clientAuth bool, nextProtos ...string) *tls.Config {
+	getCertificate := func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return r.cert.Load(), nil
+	}
+
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     nextProtos,
+		GetCertificate: getCertificate,
+	}
+
+	if clientAuth {
+		// the client CA is looked up for every handshake, so that a reloaded CA is used right away
+		config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return &tls.Config{
+				MinVersion:     tls.VersionTLS12,
+				NextProtos:     nextProtos,
+				GetCertificate: getCertificate,
+				ClientAuth:     tls.RequireAndVerifyClientCert,
+				ClientCAs:      r.clientCAs.Load(),
+			}, nil
+		}
+	}
+
+	return config
+}
+
+// ClientConfig returns the TLS config of a client which verifies servers with the root CAs, nil means the system roots.
+// It presents the latest certificate to servers which require client certificates,
+// so the certificate must also be valid for client authentication then.
+func (r *Reloader) ClientConfig(rootCAs *x509.CertPool) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    rootCAs,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.cert.Load(), nil
+		},
+	}
+}
+
+// LoadCertPool loads the CA certificates of a PEM file, an empty file name returns nil for the system roots.
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	if file == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read CA")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, errors.Errorf("invalid CA %s", file)
+	}
+
+	return pool, nil
+}
+
+// Start reloads the certificates when they change until the reloader is stopped.
+func (r *Reloader) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	defer close(r.done)
+
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		reloaded, err := r.Reload()
+		if err != nil {
+			log.Println("cannot reload certificates:", err)
+		} else if reloaded {
+			log.Println("certificates are reloaded from", r.certFile)
+		}
+	}
+}
+
+// Stop stops the reloader.
+func (r *Reloader) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+
+	r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
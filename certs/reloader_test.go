@@ -0,0 +1,262 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type keyPair struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newKeyPair creates a certificate signed by the parent, or a self-signed CA if the parent is nil.
+func newKeyPair(t *testing.T, name string, parent *keyPair) keyPair {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{name},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return keyPair{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func writeKeyPair(t *testing.T, dir string, pair keyPair) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.crt"), pair.certPEM, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.key"), pair.keyPEM, 0o600))
+}
+
+// serve accepts TLS connections and completes their handshakes.
+func serve(t *testing.T, config *tls.Config) string {
+	t.Helper()
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				_ = conn.(*tls.Conn).Handshake()
+				_, _ = conn.Read(make([]byte, 1))
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// dial returns the certificate served at the address.
+func dial(address string, roots *x509.CertPool, client *keyPair) (*x509.Certificate, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		RootCAs:    roots,
+		ServerName: "server",
+	}
+
+	if client != nil {
+		config.Certificates = []tls.Certificate{{
+			Certificate: [][]byte{client.cert.Raw},
+			PrivateKey:  client.key,
+		}}
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: time.Second}, "tcp", address, config)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// TLS 1.3 reports a rejected client certificate with the first read
+	if err := conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			return nil, err
+		}
+	}
+
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestReloaderReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newKeyPair(t, "ca", nil)
+	first := newKeyPair(t, "server", &ca)
+	writeKeyPair(t, dir, first)
+
+	reloader, err := NewReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), "")
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	address := serve(t, reloader.ServerConfig(false))
+
+	cert, err := dial(address, roots, nil)
+	require.NoError(t, err)
+	require.Equal(t, first.cert.SerialNumber, cert.SerialNumber)
+
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+
+	second := newKeyPair(t, "server", &ca)
+	writeKeyPair(t, dir, second)
+
+	reloaded, err = reloader.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+
+	cert, err = dial(address, roots, nil)
+	require.NoError(t, err)
+	require.Equal(t, second.cert.SerialNumber, cert.SerialNumber)
+
+	// an invalid certificate keeps the one in use
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tls.crt"), []byte("invalid"), 0o600))
+
+	_, err = reloader.Reload()
+	require.Error(t, err)
+
+	cert, err = dial(address, roots, nil)
+	require.NoError(t, err)
+	require.Equal(t, second.cert.SerialNumber, cert.SerialNumber)
+}
+
+func TestReloaderClientAuth(t *testing.T) {
+	dir := t.TempDir()
+	ca := newKeyPair(t, "ca", nil)
+	writeKeyPair(t, dir, newKeyPair(t, "server", &ca))
+
+	clientCA := newKeyPair(t, "client-ca", nil)
+	clientCAFile := filepath.Join(dir, "client-ca.crt")
+	require.NoError(t, os.WriteFile(clientCAFile, clientCA.certPEM, 0o600))
+
+	reloader, err := NewReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), clientCAFile)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	address := serve(t, reloader.ServerConfig(true))
+
+	client := newKeyPair(t, "client", &clientCA)
+
+	_, err = dial(address, roots, &client)
+	require.NoError(t, err)
+
+	_, err = dial(address, roots, nil)
+	require.Error(t, err)
+
+	// a client signed by another CA is rejected
+	stranger := newKeyPair(t, "client", &ca)
+
+	_, err = dial(address, roots, &stranger)
+	require.Error(t, err)
+
+	// a reloaded client CA is used by the next handshake
+	require.NoError(t, os.WriteFile(clientCAFile, ca.certPEM, 0o600))
+
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+
+	_, err = dial(address, roots, &stranger)
+	require.NoError(t, err)
+
+	_, err = dial(address, roots, &client)
+	require.Error(t, err)
+}
+
+func TestReloaderClientConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newKeyPair(t, "ca", nil)
+	writeKeyPair(t, dir, newKeyPair(t, "server", &ca))
+
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, ca.certPEM, 0o600))
+
+	reloader, err := NewReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), caFile)
+	require.NoError(t, err)
+
+	address := serve(t, reloader.ServerConfig(true))
+
+	roots, err := LoadCertPool(caFile)
+	require.NoError(t, err)
+
+	config := reloader.ClientConfig(roots)
+	config.ServerName = "server"
+
+	// the client presents the certificate of the reloader, which the server accepts
+	conn, err := tls.Dial("tcp", address, config)
+	require.NoError(t, err)
+	require.NoError(t, conn.Handshake())
+	require.NoError(t, conn.Close())
+
+	roots, err = LoadCertPool("")
+	require.NoError(t, err)
+	require.Nil(t, roots)
+
+	_, err = LoadCertPool(filepath.Join(dir, "tls.key"))
+	require.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/util"
+	_ "github.com/lib/pq"
+)
+
+// audit-verify recomputes the audit log hash chain and exits non-zero
+// if any event has been modified, removed or reordered.
+func main() {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal("cannot load configurations:", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db:", err)
+	}
+	defer conn.Close()
+
+	report, err := db.VerifyAuditChain(context.Background(), db.New(conn))
+	if err != nil {
+		log.Fatalf("audit chain verification failed after %d events: %s", report.Events, err)
+	}
+
+	log.Printf("audit chain verified: %d events, last id %d, last hash %s",
+		report.Events, report.LastID, report.LastHash)
+}
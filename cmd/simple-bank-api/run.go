@@ -7,12 +7,34 @@ import (
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/ifantsai/simple-bank-api/admin"
+	"github.com/ifantsai/simple-bank-api/certs"
 	db "github.com/ifantsai/simple-bank-api/db/sqlc"
 	"github.com/ifantsai/simple-bank-api/gapi"
+	"github.com/ifantsai/simple-bank-api/health"
+	"github.com/ifantsai/simple-bank-api/interest"
+	"github.com/ifantsai/simple-bank-api/metrics"
+	"github.com/ifantsai/simple-bank-api/outbox"
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/ifantsai/simple-bank-api/ratelimit"
 	"github.com/ifantsai/simple-bank-api/server"
+	"github.com/ifantsai/simple-bank-api/tracing"
 	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/ifantsai/simple-bank-api/watch"
+	"github.com/ifantsai/simple-bank-api/webhook"
 	_ "github.com/lib/pq"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Roles of the binary.
+const (
+	// roleAll runs the gRPC server, the gateway and the background workers.
+	roleAll = "all"
+	// roleGRPC runs the gRPC server and the background workers, for gateways deployed on their own.
+	roleGRPC = "grpc"
+	// roleGateway runs only the gateway, which proxies to the gRPC servers at GATEWAY_GRPC_TARGET.
+	roleGateway = "gateway"
 )
 
 func main() {
@@ -21,29 +43,156 @@ func main() {
 		log.Fatal("cannot load configurations:", err)
 	}
 
+	tracerProvider, err := tracing.NewProvider(config.TracingExporter, config.TracingOTLPEndpoint, config.TracingSampleRatio)
+	if err != nil {
+		log.Fatal("cannot set up tracing:", err)
+	}
+
+	var certReloader *certs.Reloader
+	if config.TLSCertFile != "" {
+		certReloader, err = certs.NewReloader(config.TLSCertFile, config.TLSKeyFile, config.TLSClientCAFile)
+		if err != nil {
+			log.Fatal("cannot load certificates:", err)
+		}
+	}
+
+	var servers []server.Server
+
+	switch config.ServerRole {
+	case "", roleAll, roleGRPC:
+		servers = newBankServers(config, certReloader)
+	case roleGateway:
+		servers = newGatewayServers(config, certReloader)
+	default:
+		log.Fatalf("unknown server role %q", config.ServerRole)
+	}
+
+	servers = append(servers, admin.NewServer(config.AdminServerAddress))
+
+	if certReloader != nil {
+		servers = append(servers, certReloader)
+	}
+
+	// stop the tracer provider last to export the spans of the other servers
+	servers = append(servers, tracerProvider)
+
+	server.Run(servers...)
+}
+
+// newBankServers creates the gRPC server, the gateway unless the role is grpc, and the background workers.
+func newBankServers(config util.Config, certReloader *certs.Reloader) []server.Server {
 	conn, err := sql.Open(config.DBDriver, config.DBSource)
 	if err != nil {
 		log.Fatal("cannot connect to db:", err)
 	}
 
-	runDBMigration(config.DBMigrationURL, config.DBSource)
+	migrationVersion := runDBMigration(config.DBMigrationURL, config.DBSource)
+
+	store := metrics.NewStore(db.NewStore(conn))
+	prometheus.MustRegister(metrics.NewSessionCollector(store))
+
+	hub := watch.NewHub(config.DBSource)
+
+	checker := health.NewChecker(config.ShutdownDrainDelay, []string{pb.SimpleBank_ServiceDesc.ServiceName},
+		health.DatabaseCheck(conn),
+		health.MigrationCheck(conn, migrationVersion),
+	)
 
-	store := db.NewStore(conn)
+	limiter, err := newRateLimiter(config, store)
+	if err != nil {
+		log.Fatal("cannot set up rate limiting:", err)
+	}
 
-	grpcServer, err := gapi.NewGRPCServer(config, store, config.GRPCServerAddress)
+	grpcServer, err := gapi.NewGRPCServer(config, store, hub, checker, limiter, certReloader, config.GRPCServerAddress)
 	if err != nil {
 		log.Fatal("cannot new gRPC server:", err)
 	}
 
-	gatewayServer, err := gapi.NewGatewayServer(config, store, config.HTTPServerAddress)
+	servers := []server.Server{checker, grpcServer}
+
+	if config.ServerRole != roleGRPC {
+		gatewayServer, err := gapi.NewGatewayServer(
+			config, store, hub, checker, limiter, certReloader, config.HTTPServerAddress,
+		)
+		if err != nil {
+			log.Fatal("cannot new gateway server:", err)
+		}
+
+		servers = append(servers, gatewayServer)
+	}
+
+	servers = append(servers, hub, limiter)
+
+	if config.InterestCheckInterval > 0 {
+		engine := interest.NewEngine(store, config.SavingsInterestRateBps)
+		servers = append(servers, interest.NewScheduler(engine, config.InterestCheckInterval))
+	}
+
+	if config.OutboxRelayInterval > 0 {
+		servers = append(servers, newOutboxRelay(config, store))
+	}
+
+	if config.WebhookDeliveryInterval > 0 {
+		servers = append(servers, webhook.NewDeliverer(store, config.WebhookDeliveryInterval))
+	}
+
+	return servers
+}
+
+// newGatewayServers creates a gateway which proxies every request to the gRPC servers, so it needs no database.
+// The gRPC servers run the interceptors, e.g. rate limiting, for the requests of the gateway.
+func newGatewayServers(config util.Config, certReloader *certs.Reloader) []server.Server {
+	if config.GatewayMode != gapi.GatewayProxy || config.GatewayGRPCTarget == "" {
+		log.Fatal("the gateway role requires GATEWAY_MODE=proxy and GATEWAY_GRPC_TARGET")
+	}
+
+	checker := health.NewChecker(config.ShutdownDrainDelay, nil)
+
+	gatewayServer, err := gapi.NewGatewayServer(config, nil, nil, checker, nil, certReloader, config.HTTPServerAddress)
 	if err != nil {
 		log.Fatal("cannot new gateway server:", err)
 	}
 
-	server.Run(grpcServer, gatewayServer)
+	return []server.Server{checker, gatewayServer}
 }
 
-func runDBMigration(url string, source string) {
+// newOutboxRelay creates the relay of domain events to the webhook subscriptions and the configured sinks.
+func newOutboxRelay(config util.Config, store db.Store) *outbox.Relay {
+	sinks := []outbox.Sink{webhook.NewDispatcher(store)}
+
+	if config.OutboxWebhookURL != "" {
+		sinks = append(sinks, outbox.NewWebhookSink(config.OutboxWebhookURL, outbox.DefaultWebhookTimeout))
+	}
+
+	if config.OutboxFilePath != "" {
+		sinks = append(sinks, outbox.NewFileSink(config.OutboxFilePath))
+	}
+
+	return outbox.NewRelay(store, config.OutboxRelayInterval, config.OutboxMaxAttempts, sinks...)
+}
+
+// newRateLimiter creates the rate limiter of the gRPC and gateway servers, which share its buckets.
+func newRateLimiter(config util.Config, store db.Store) (*ratelimit.Limiter, error) {
+	defaultPolicy, err := ratelimit.ParsePolicy(config.RateLimitDefault)
+	if err != nil {
+		return nil, err
+	}
+
+	policies, err := ratelimit.ParsePolicies(config.RateLimitPolicies)
+	if err != nil {
+		return nil, err
+	}
+
+	limiterStore, err := ratelimit.NewStore(config.RateLimitStore, store)
+	if err != nil {
+		return nil, err
+	}
+
+	return ratelimit.NewLimiter(limiterStore, defaultPolicy, policies), nil
+}
+
+// runDBMigration migrates the database to the latest version and returns the version.
+func runDBMigration(url string, source string) uint {
 	migration, err := migrate.New(url, source)
 	if err != nil {
 		log.Fatal("cannot new migration:", err)
@@ -53,5 +202,12 @@ func runDBMigration(url string, source string) {
 		log.Fatal("cannot migrate:", err)
 	}
 
-	log.Println("migration done")
+	version, _, err := migration.Version()
+	if err != nil {
+		log.Fatal("cannot get migration version:", err)
+	}
+
+	log.Println("migration done, version", version)
+
+	return version
 }
@@ -0,0 +1,35 @@
+CREATE TABLE "audit_events" (
+    "id" bigserial PRIMARY KEY,
+    "actor" varchar NOT NULL,
+    "client_ip" varchar NOT NULL,
+    "action" varchar NOT NULL,
+    "resource_type" varchar NOT NULL,
+    "resource_id" varchar NOT NULL,
+    "before_state" jsonb NOT NULL,
+    "after_state" jsonb NOT NULL,
+    "prev_hash" varchar NOT NULL,
+    "hash" varchar UNIQUE NOT NULL,
+    "created_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "audit_events" ("actor");
+
+CREATE INDEX ON "audit_events" ("resource_type", "resource_id");
+
+COMMENT ON COLUMN "audit_events"."prev_hash" IS 'hash of the previous event in the chain';
+
+COMMENT ON COLUMN "audit_events"."hash" IS 'sha256 over prev_hash and the event content';
+
+CREATE FUNCTION "audit_events_append_only"() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_no_modify"
+    BEFORE UPDATE OR DELETE ON "audit_events"
+    FOR EACH ROW EXECUTE FUNCTION "audit_events_append_only"();
+
+CREATE TRIGGER "audit_events_no_truncate"
+    BEFORE TRUNCATE ON "audit_events"
+    FOR EACH STATEMENT EXECUTE FUNCTION "audit_events_append_only"();
@@ -0,0 +1,9 @@
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "category";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "description";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "client_reference";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "category";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "description";
@@ -0,0 +1,17 @@
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+
+ALTER TABLE "transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "category" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "client_reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "entries" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "entries" ADD COLUMN "category" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "transfers" USING gin ("description" gin_trgm_ops);
+
+CREATE INDEX ON "transfers" ("category");
+
+CREATE INDEX ON "transfers" ("client_reference");
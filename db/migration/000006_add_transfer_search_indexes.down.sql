@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "transfers_to_account_id_amount_idx";
+
+DROP INDEX IF EXISTS "transfers_from_account_id_amount_idx";
+
+DROP INDEX IF EXISTS "transfers_to_account_id_created_at_idx";
+
+DROP INDEX IF EXISTS "transfers_from_account_id_created_at_idx";
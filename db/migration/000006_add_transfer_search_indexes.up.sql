@@ -0,0 +1,7 @@
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at");
+
+CREATE INDEX ON "transfers" ("from_account_id", "amount");
+
+CREATE INDEX ON "transfers" ("to_account_id", "amount");
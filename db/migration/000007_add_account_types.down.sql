@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "owner_nickname_key";
+
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "nickname";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "type";
@@ -0,0 +1,9 @@
+ALTER TABLE "accounts" ADD COLUMN "type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts" ADD COLUMN "nickname" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_key";
+
+CREATE INDEX ON "accounts" ("owner", "currency");
+
+CREATE UNIQUE INDEX "owner_nickname_key" ON "accounts" ("owner", "nickname") WHERE "nickname" <> '';
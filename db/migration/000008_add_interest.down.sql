@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "interest_postings";
+
+DROP TABLE IF EXISTS "interest_accruals";
+
+DROP TABLE IF EXISTS "account_interest_rates";
+
+DELETE FROM "accounts" WHERE "owner" = 'bank' AND "type" = 'interest_expense';
@@ -0,0 +1,61 @@
+CREATE TABLE "account_interest_rates" (
+    "account_id" bigint PRIMARY KEY,
+    "rate_bps" integer NOT NULL,
+    "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_accruals" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "accrual_date" date NOT NULL,
+    "balance" bigint NOT NULL,
+    "rate_bps" integer NOT NULL,
+    "amount_micros" bigint NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_postings" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "period_end" date NOT NULL,
+    "amount" bigint NOT NULL,
+    "transfer_id" bigint,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_interest_rates" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "interest_accruals" ADD CONSTRAINT "account_accrual_date_key" UNIQUE ("account_id", "accrual_date");
+
+ALTER TABLE "interest_postings" ADD CONSTRAINT "account_period_end_key" UNIQUE ("account_id", "period_end");
+
+COMMENT ON COLUMN "account_interest_rates"."rate_bps" IS 'annual rate in basis points';
+
+COMMENT ON COLUMN "interest_accruals"."amount_micros" IS 'accrued interest in millionths of the minor unit';
+
+COMMENT ON COLUMN "interest_postings"."amount" IS 'posted interest in minor units';
+
+-- bank-owned accounts that pay out interest,
+-- which must not be handed to a customer who registered as the bank
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM "users" WHERE "username" = 'bank' AND "hashed_password" <> '!') THEN
+        RAISE EXCEPTION 'user "bank" is a customer, rename it before migrating';
+    END IF;
+END
+$$;
+
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('bank', '!', 'Simple Bank', 'bank@simplebank.internal')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "type", "nickname")
+VALUES ('bank', 0, 'USD', 'interest_expense', 'Interest expense USD'),
+       ('bank', 0, 'EUR', 'interest_expense', 'Interest expense EUR'),
+       ('bank', 0, 'CAD', 'interest_expense', 'Interest expense CAD');
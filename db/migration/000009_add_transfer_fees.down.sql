@@ -0,0 +1,3 @@
+DELETE FROM "accounts" WHERE "owner" = 'bank' AND "type" = 'fee_revenue';
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "fee";
@@ -0,0 +1,9 @@
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "transfers"."fee" IS 'charged to the sender on top of the amount';
+
+-- bank-owned accounts that collect transfer fees
+INSERT INTO "accounts" ("owner", "balance", "currency", "type", "nickname")
+VALUES ('bank', 0, 'USD', 'fee_revenue', 'Fee revenue USD'),
+       ('bank', 0, 'EUR', 'fee_revenue', 'Fee revenue EUR'),
+       ('bank', 0, 'CAD', 'fee_revenue', 'Fee revenue CAD');
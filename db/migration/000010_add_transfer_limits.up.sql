@@ -0,0 +1,43 @@
+ALTER TABLE "users" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+CREATE TABLE "tier_limits" (
+    "tier" varchar NOT NULL,
+    "currency" varchar NOT NULL,
+    "per_transaction" bigint NOT NULL DEFAULT 0,
+    "daily" bigint NOT NULL DEFAULT 0,
+    "monthly" bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY ("tier", "currency")
+);
+
+CREATE TABLE "account_limits" (
+    "account_id" bigint PRIMARY KEY,
+    "per_transaction" bigint NOT NULL DEFAULT 0,
+    "daily" bigint NOT NULL DEFAULT 0,
+    "monthly" bigint NOT NULL DEFAULT 0,
+    "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+COMMENT ON COLUMN "tier_limits"."per_transaction" IS '0 means unlimited';
+
+COMMENT ON COLUMN "tier_limits"."daily" IS 'outgoing total of all accounts of the user in the currency, 0 means unlimited';
+
+COMMENT ON COLUMN "tier_limits"."monthly" IS 'outgoing total of all accounts of the user in the currency, 0 means unlimited';
+
+COMMENT ON COLUMN "account_limits"."daily" IS 'outgoing total of the account, 0 means unlimited';
+
+COMMENT ON COLUMN "account_limits"."monthly" IS 'outgoing total of the account, 0 means unlimited';
+
+INSERT INTO "tier_limits" ("tier", "currency", "per_transaction", "daily", "monthly")
+VALUES ('standard', 'USD', 500000, 1000000, 5000000),
+       ('standard', 'EUR', 500000, 1000000, 5000000),
+       ('standard', 'CAD', 500000, 1000000, 5000000),
+       ('premium', 'USD', 5000000, 10000000, 50000000),
+       ('premium', 'EUR', 5000000, 10000000, 50000000),
+       ('premium', 'CAD', 5000000, 10000000, 50000000);
+
+-- the bank has no limits
+UPDATE "users" SET "tier" = 'internal' WHERE "username" = 'bank';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "risk_assessments";
+
+DROP INDEX IF EXISTS "transfers_status_idx";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
@@ -0,0 +1,35 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'completed';
+
+CREATE TABLE "risk_assessments" (
+    "id" bigserial PRIMARY KEY,
+    "transfer_id" bigint,
+    "username" varchar NOT NULL,
+    "from_account_id" bigint NOT NULL,
+    "to_account_id" bigint NOT NULL,
+    "amount" bigint NOT NULL,
+    "client_ip" varchar NOT NULL,
+    "user_agent" varchar NOT NULL,
+    "outcome" varchar NOT NULL,
+    "reason" varchar NOT NULL,
+    "reviewed_by" varchar NOT NULL DEFAULT '',
+    "reviewed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "risk_assessments" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "risk_assessments" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE UNIQUE INDEX ON "risk_assessments" ("transfer_id");
+
+CREATE INDEX ON "risk_assessments" ("username", "client_ip", "user_agent");
+
+CREATE INDEX ON "transfers" ("status");
+
+COMMENT ON COLUMN "transfers"."status" IS 'completed, pending_review or rejected';
+
+COMMENT ON COLUMN "risk_assessments"."transfer_id" IS 'empty for denied transfers';
+
+COMMENT ON COLUMN "risk_assessments"."outcome" IS 'allow, review or deny';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "payees";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "require_saved_payee";
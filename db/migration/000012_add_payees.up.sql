@@ -0,0 +1,19 @@
+ALTER TABLE "users" ADD COLUMN "require_saved_payee" boolean NOT NULL DEFAULT false;
+
+CREATE TABLE "payees" (
+    "id" bigserial PRIMARY KEY,
+    "owner" varchar NOT NULL,
+    "account_id" bigint NOT NULL,
+    "nickname" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "payees" ADD CONSTRAINT "owner_payee_account_key" UNIQUE ("owner", "account_id");
+
+ALTER TABLE "payees" ADD CONSTRAINT "owner_payee_nickname_key" UNIQUE ("owner", "nickname");
+
+COMMENT ON COLUMN "users"."require_saved_payee" IS 'only allow transfers to saved payees and own accounts';
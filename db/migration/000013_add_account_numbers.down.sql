@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "number";
+
+DROP FUNCTION IF EXISTS generate_account_number();
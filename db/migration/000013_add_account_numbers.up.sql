@@ -0,0 +1,11 @@
+-- account numbers are IBAN-like: country code SB, two mod-97 check digits and 12 random digits
+CREATE FUNCTION generate_account_number() RETURNS varchar AS $$
+    SELECT 'SB' || lpad((98 - mod((bban || '281100')::numeric, 97))::text, 2, '0') || bban
+    FROM (SELECT lpad(floor(random() * 1000000000000)::bigint::text, 12, '0') AS bban) AS b;
+$$ LANGUAGE sql VOLATILE;
+
+ALTER TABLE "accounts" ADD COLUMN "number" varchar NOT NULL DEFAULT (generate_account_number());
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_number_key" UNIQUE ("number");
+
+COMMENT ON COLUMN "accounts"."number" IS 'external account number with mod-97 check digits';
@@ -0,0 +1,33 @@
+CREATE TABLE "payment_requests" (
+    "id" bigserial PRIMARY KEY,
+    "requester_account_id" bigint NOT NULL,
+    "requester" varchar NOT NULL,
+    "payer" varchar NOT NULL,
+    "amount" bigint NOT NULL,
+    "currency" varchar NOT NULL,
+    "memo" varchar NOT NULL DEFAULT '',
+    "status" varchar NOT NULL DEFAULT 'pending',
+    "transfer_id" bigint,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("requester_account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("requester") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("payer") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "payment_requests" ("requester");
+
+CREATE INDEX ON "payment_requests" ("payer");
+
+CREATE UNIQUE INDEX ON "payment_requests" ("transfer_id");
+
+ALTER TABLE "payment_requests" ADD CONSTRAINT "positive_amount" CHECK ("amount" > 0);
+
+COMMENT ON COLUMN "payment_requests"."status" IS 'pending, paid or declined';
+
+COMMENT ON COLUMN "payment_requests"."transfer_id" IS 'transfer paying the request, it may still be pending review';
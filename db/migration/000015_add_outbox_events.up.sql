@@ -0,0 +1,17 @@
+CREATE TABLE "outbox_events" (
+    "id" bigserial PRIMARY KEY,
+    "aggregate_type" varchar NOT NULL,
+    "aggregate_id" varchar NOT NULL,
+    "event_type" varchar NOT NULL,
+    "payload" jsonb NOT NULL,
+    "attempts" integer NOT NULL DEFAULT 0,
+    "last_error" varchar NOT NULL DEFAULT '',
+    "published_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "outbox_events" ("id") WHERE "published_at" IS NULL;
+
+CREATE INDEX ON "outbox_events" ("aggregate_type", "aggregate_id");
+
+COMMENT ON COLUMN "outbox_events"."published_at" IS 'empty until delivered to every sink';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "owner_nickname_key";
+
+CREATE UNIQUE INDEX "owner_nickname_key" ON "accounts" ("owner", "nickname") WHERE "nickname" <> '';
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "closed_at";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,9 @@
+-- closed accounts are kept for their entries and the audit log, they cannot send or receive money
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'open';
+
+ALTER TABLE "accounts" ADD COLUMN "closed_at" timestamptz;
+
+-- the nickname of a closed account can be reused
+DROP INDEX IF EXISTS "owner_nickname_key";
+
+CREATE UNIQUE INDEX "owner_nickname_key" ON "accounts" ("owner", "nickname") WHERE "nickname" <> '' AND "status" = 'open';
@@ -0,0 +1,13 @@
+-- entries are linked to the transfer they were posted for, so that statements can show its reference and counterparty
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("account_id", "id");
+
+-- the entries of a transfer completed right away were created in the same database transaction,
+-- so they share its timestamp. Entries of transfers approved after a review are left unlinked.
+UPDATE "entries" e
+SET "transfer_id" = t."id"
+FROM "transfers" t
+WHERE e."created_at" = t."created_at" AND e."account_id" IN (t."from_account_id", t."to_account_id");
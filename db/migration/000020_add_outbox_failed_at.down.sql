@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "outbox_events_id_idx";
+
+CREATE INDEX ON "outbox_events" ("id") WHERE "published_at" IS NULL;
+
+ALTER TABLE IF EXISTS "outbox_events" DROP COLUMN IF EXISTS "failed_at";
@@ -0,0 +1,8 @@
+-- events which failed too often are set aside, so that they no longer hold back the later events of their aggregate
+ALTER TABLE "outbox_events" ADD COLUMN "failed_at" timestamptz;
+
+COMMENT ON COLUMN "outbox_events"."failed_at" IS 'set when the relay gave up, clear it to publish the event again';
+
+DROP INDEX IF EXISTS "outbox_events_id_idx";
+
+CREATE INDEX ON "outbox_events" ("id") WHERE "published_at" IS NULL AND "failed_at" IS NULL;
@@ -2,9 +2,11 @@
 INSERT INTO accounts (
     owner,
     balance,
-    currency
+    currency,
+    type,
+    nickname
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetAccount :one
@@ -23,10 +25,15 @@ ORDER BY id
 LIMIT $2
 OFFSET $3;
 
--- name: UpdateAccounts :one
-UPDATE accounts
-SET balance = $2
-WHERE id = $1
+-- name: CountAccountsByCurrency :one
+SELECT count(*) FROM accounts
+WHERE owner = $1 AND currency = $2 AND status = 'open';
+
+-- name: UpdateAccount :one
+UPDATE accounts SET
+    nickname = COALESCE(sqlc.narg(nickname), nickname),
+    type = COALESCE(sqlc.narg(type), type)
+WHERE id = sqlc.arg(id)
 RETURNING *;
 
 -- name: AddAccountBalance :one
@@ -35,6 +42,31 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
+-- name: CloseAccount :one
+UPDATE accounts
+SET status = 'closed', closed_at = now()
+WHERE id = $1
+RETURNING *;
+
 -- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: GetDefaultAccount :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 AND type IN ('checking', 'savings') AND status = 'open'
+ORDER BY type = 'checking' DESC, id
+LIMIT 1;
+
+-- name: GetSystemAccount :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 AND type = $3
+ORDER BY id
+LIMIT 1;
+
+-- name: GetAccountByNumber :one
+SELECT * FROM accounts
+WHERE number = $1 LIMIT 1;
+
+-- name: NotifyAccountChange :exec
+SELECT pg_notify(sqlc.arg(channel)::text, sqlc.arg(payload)::text);
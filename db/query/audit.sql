@@ -0,0 +1,29 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+    actor,
+    client_ip,
+    action,
+    resource_type,
+    resource_id,
+    before_state,
+    after_state,
+    prev_hash,
+    hash,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: GetLastAuditEvent :one
+SELECT * FROM audit_events
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(limit_count);
+
+-- name: LockAuditChain :exec
+SELECT pg_advisory_xact_lock(sqlc.arg(lock_key)::bigint);
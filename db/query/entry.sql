@@ -1,9 +1,12 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    description,
+    category,
+    transfer_id
 ) VALUES (
-    $1, $2
+    $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -11,8 +14,18 @@ SELECT * FROM entries
 WHERE id = $1 LIMIT 1;
 
 -- name: ListEntries :many
-SELECT * FROM entries
-WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3;
\ No newline at end of file
+-- entries can be paged by id, so that no entry is skipped or repeated when entries are added meanwhile,
+-- and come with the reference and the counterparty of their transfer
+SELECT e.*,
+       COALESCE(t.client_reference, '')::text AS transfer_reference,
+       COALESCE(c.number, '')::text AS counterparty_account_number
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END
+WHERE e.account_id = sqlc.arg(account_id) AND
+      (sqlc.narg(start_time)::timestamptz IS NULL OR e.created_at >= sqlc.narg(start_time)::timestamptz) AND
+      (sqlc.narg(end_time)::timestamptz IS NULL OR e.created_at < sqlc.narg(end_time)::timestamptz) AND
+      e.id > sqlc.arg(after_id)
+ORDER BY e.id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
@@ -0,0 +1,75 @@
+-- name: SetAccountInterestRate :one
+INSERT INTO account_interest_rates (
+    account_id,
+    rate_bps
+) VALUES (
+    $1, $2
+) ON CONFLICT (account_id) DO UPDATE
+SET rate_bps = EXCLUDED.rate_bps, updated_at = now()
+RETURNING *;
+
+-- name: GetAccountInterestRate :one
+SELECT * FROM account_interest_rates
+WHERE account_id = $1 LIMIT 1;
+
+-- name: ListInterestBearingAccounts :many
+-- The balance is the one at day_end: entries made since then are taken off the current balance.
+SELECT a.id,
+       (a.balance - COALESCE((
+           SELECT SUM(e.amount) FROM entries e
+           WHERE e.account_id = a.id AND e.created_at >= sqlc.arg(day_end)
+       ), 0))::bigint AS balance,
+       a.currency,
+       COALESCE(r.rate_bps, sqlc.arg(default_rate_bps)::integer)::integer AS rate_bps
+FROM accounts a
+LEFT JOIN account_interest_rates r ON r.account_id = a.id
+WHERE a.type = 'savings' AND a.status = 'open'
+  AND a.created_at < sqlc.arg(day_end) AND a.id > sqlc.arg(after_id)
+ORDER BY a.id
+LIMIT sqlc.arg(limit_count);
+
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+    account_id,
+    accrual_date,
+    balance,
+    rate_bps,
+    amount_micros
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (account_id, accrual_date) DO NOTHING
+RETURNING *;
+
+-- name: GetLastAccrualDate :one
+SELECT accrual_date FROM interest_accruals
+ORDER BY accrual_date DESC
+LIMIT 1;
+
+-- name: GetUnpostedInterest :one
+SELECT (
+    (SELECT COALESCE(SUM(amount_micros), 0) FROM interest_accruals
+     WHERE interest_accruals.account_id = sqlc.arg(account_id) AND accrual_date <= sqlc.arg(period_end)) -
+    (SELECT COALESCE(SUM(amount), 0) * 1000000 FROM interest_postings
+     WHERE interest_postings.account_id = sqlc.arg(account_id))
+)::bigint AS amount_micros;
+
+-- name: ListAccountsWithAccruals :many
+SELECT DISTINCT i.account_id FROM interest_accruals i
+JOIN accounts a ON a.id = i.account_id
+WHERE i.accrual_date <= sqlc.arg(period_end) AND i.account_id > sqlc.arg(after_id) AND a.status = 'open'
+ORDER BY i.account_id
+LIMIT sqlc.arg(limit_count);
+
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+    account_id,
+    period_end,
+    amount,
+    transfer_id
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetInterestPosting :one
+SELECT * FROM interest_postings
+WHERE account_id = $1 AND period_end = $2 LIMIT 1;
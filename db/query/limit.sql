@@ -0,0 +1,57 @@
+-- name: GetTierLimit :one
+SELECT * FROM tier_limits
+WHERE tier = $1 AND currency = $2 LIMIT 1;
+
+-- name: SetTierLimit :one
+INSERT INTO tier_limits (
+    tier,
+    currency,
+    per_transaction,
+    daily,
+    monthly
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (tier, currency) DO UPDATE
+SET per_transaction = EXCLUDED.per_transaction,
+    daily = EXCLUDED.daily,
+    monthly = EXCLUDED.monthly
+RETURNING *;
+
+-- name: CountTierLimits :one
+SELECT count(*) FROM tier_limits
+WHERE tier = $1;
+
+-- name: GetAccountLimit :one
+SELECT * FROM account_limits
+WHERE account_id = $1 LIMIT 1;
+
+-- name: SetAccountLimit :one
+INSERT INTO account_limits (
+    account_id,
+    per_transaction,
+    daily,
+    monthly
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (account_id) DO UPDATE
+SET per_transaction = EXCLUDED.per_transaction,
+    daily = EXCLUDED.daily,
+    monthly = EXCLUDED.monthly,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetOwnerOutgoingTotals :one
+SELECT
+    COALESCE(SUM(-e.amount) FILTER (WHERE e.created_at >= sqlc.arg(day_start)), 0)::bigint AS daily,
+    COALESCE(SUM(-e.amount), 0)::bigint AS monthly
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+WHERE a.owner = sqlc.arg(owner) AND a.currency = sqlc.arg(currency) AND
+      e.amount < 0 AND e.created_at >= sqlc.arg(month_start);
+
+-- name: GetAccountOutgoingTotals :one
+SELECT
+    COALESCE(SUM(-amount) FILTER (WHERE created_at >= sqlc.arg(day_start)), 0)::bigint AS daily,
+    COALESCE(SUM(-amount), 0)::bigint AS monthly
+FROM entries
+WHERE account_id = sqlc.arg(account_id) AND amount < 0 AND created_at >= sqlc.arg(month_start);
@@ -0,0 +1,38 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox_events (
+    aggregate_type,
+    aggregate_id,
+    event_type,
+    payload
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: TryLockOutbox :one
+-- the lock is held by the session, so that no transaction is kept open while events are published
+SELECT pg_try_advisory_lock(sqlc.arg(lock_key)::bigint);
+
+-- name: UnlockOutbox :one
+SELECT pg_advisory_unlock(sqlc.arg(lock_key)::bigint);
+
+-- name: ListUnpublishedOutboxEvents :many
+SELECT * FROM outbox_events
+WHERE published_at IS NULL AND failed_at IS NULL
+ORDER BY id
+LIMIT $1;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET published_at = now(), attempts = attempts + 1, last_error = ''
+WHERE id = $1;
+
+-- name: RecordOutboxEventFailure :one
+-- the event is set aside once it reaches max_attempts, zero retries it forever
+UPDATE outbox_events
+SET attempts = attempts + 1,
+    last_error = sqlc.arg(last_error),
+    failed_at = CASE
+        WHEN sqlc.arg(max_attempts)::integer > 0 AND attempts + 1 >= sqlc.arg(max_attempts)::integer THEN now()
+    END
+WHERE id = sqlc.arg(id)
+RETURNING *;
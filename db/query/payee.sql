@@ -0,0 +1,31 @@
+-- name: CreatePayee :one
+INSERT INTO payees (
+    owner,
+    account_id,
+    nickname
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetPayee :one
+SELECT p.*, a.owner AS payee_username, a.currency
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+WHERE p.id = $1 LIMIT 1;
+
+-- name: GetPayeeByAccount :one
+SELECT * FROM payees
+WHERE owner = $1 AND account_id = $2 LIMIT 1;
+
+-- name: ListPayees :many
+SELECT p.*, a.owner AS payee_username, a.currency
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+WHERE p.owner = $1
+ORDER BY p.nickname
+LIMIT $2
+OFFSET $3;
+
+-- name: DeletePayee :exec
+DELETE FROM payees
+WHERE id = $1 AND owner = $2;
@@ -0,0 +1,59 @@
+-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (
+    requester_account_id,
+    requester,
+    payer,
+    amount,
+    currency,
+    memo,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetPaymentRequest :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPaymentRequestForUpdate :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListIncomingPaymentRequests :many
+SELECT pr.*, a.number AS requester_account_number
+FROM payment_requests pr
+JOIN accounts a ON a.id = pr.requester_account_id
+WHERE pr.payer = $1
+ORDER BY pr.id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListOutgoingPaymentRequests :many
+SELECT * FROM payment_requests
+WHERE requester = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: DeclinePaymentRequest :one
+UPDATE payment_requests
+SET status = 'declined'
+WHERE id = $1 AND status = 'pending' AND transfer_id IS NULL
+RETURNING *;
+
+-- name: LinkPaymentRequestTransfer :one
+UPDATE payment_requests
+SET transfer_id = sqlc.arg(transfer_id), status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: MarkPaymentRequestPaid :exec
+UPDATE payment_requests
+SET status = 'paid'
+WHERE transfer_id = $1;
+
+-- name: ReleasePaymentRequest :exec
+UPDATE payment_requests
+SET transfer_id = NULL
+WHERE transfer_id = $1;
@@ -0,0 +1,41 @@
+-- name: CreateRiskAssessment :one
+INSERT INTO risk_assessments (
+    transfer_id,
+    username,
+    from_account_id,
+    to_account_id,
+    amount,
+    client_ip,
+    user_agent,
+    outcome,
+    reason
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetRiskAssessmentByTransfer :one
+SELECT * FROM risk_assessments
+WHERE transfer_id = $1 LIMIT 1;
+
+-- name: ReviewRiskAssessment :one
+UPDATE risk_assessments SET
+    reviewed_by = $2,
+    reviewed_at = now()
+WHERE transfer_id = $1
+RETURNING *;
+
+-- name: CountKnownClientTransfers :one
+SELECT COUNT(*) AS transfers,
+       COUNT(*) FILTER (WHERE r.client_ip = sqlc.arg(client_ip) AND r.user_agent = sqlc.arg(user_agent)) AS client_transfers
+FROM risk_assessments r
+JOIN transfers t ON t.id = r.transfer_id
+WHERE r.username = sqlc.arg(username) AND t.status = 'completed';
+
+-- name: ListTransfersForReview :many
+SELECT t.*, r.username, r.client_ip, r.user_agent, r.reason
+FROM transfers t
+JOIN risk_assessments r ON r.transfer_id = t.id
+WHERE t.status = 'pending_review'
+ORDER BY t.id
+LIMIT $1
+OFFSET $2;
@@ -14,3 +14,13 @@ INSERT INTO sessions (
 -- name: GetSession :one
 SELECT * FROM sessions
 WHERE id = $1 LIMIT 1;
+
+-- name: BlockSession :one
+UPDATE sessions
+SET is_blocked = true
+WHERE id = $1
+RETURNING *;
+
+-- name: CountActiveSessions :one
+SELECT COUNT(*) FROM sessions
+WHERE is_blocked = false AND expires_at > now();
@@ -2,19 +2,155 @@
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    description,
+    category,
+    client_reference,
+    fee,
+    status
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
--- name: ListTransfers :many
+-- name: GetTransferForUpdate :one
 SELECT * FROM transfers
-WHERE from_account_id = $1 OR
-      to_account_id = $2
-ORDER BY id
-LIMIT $3
-OFFSET $4;
\ No newline at end of file
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateTransferStatus :one
+UPDATE transfers SET status = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListTransfers :many
+-- the search term must have its LIKE wildcards escaped with a backslash
+SELECT t.*,
+       fa.owner AS from_account_owner, fa.number AS from_account_number,
+       ta.owner AS to_account_owner, ta.number AS to_account_number
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (t.from_account_id = sqlc.arg(from_account_id) OR
+       t.to_account_id = sqlc.arg(to_account_id)) AND
+      (sqlc.arg(search)::text = '' OR
+       t.description ILIKE '%' || sqlc.arg(search)::text || '%' ESCAPE '\' OR
+       t.category ILIKE '%' || sqlc.arg(search)::text || '%' ESCAPE '\' OR
+       t.client_reference ILIKE '%' || sqlc.arg(search)::text || '%' ESCAPE '\')
+ORDER BY t.id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SearchTransfersByCreatedAtAsc :many
+-- the search queries only differ by their static order, so that postgres can use the indexes to sort
+SELECT t.*,
+       fa.owner AS from_account_owner, fa.number AS from_account_number,
+       ta.owner AS to_account_owner, ta.number AS to_account_number
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE ((sqlc.arg(direction)::text IN ('out', 'both') AND
+        fa.owner = sqlc.arg(owner)::text AND
+        (sqlc.narg(account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(account_id)::bigint) AND
+        (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(counterparty_account_id)::bigint)) OR
+       (sqlc.arg(direction)::text IN ('in', 'both') AND
+        ta.owner = sqlc.arg(owner)::text AND
+        (sqlc.narg(account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(account_id)::bigint) AND
+        (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(counterparty_account_id)::bigint))) AND
+      (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount)::bigint) AND
+      (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount)::bigint) AND
+      (sqlc.narg(start_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(start_time)::timestamptz) AND
+      (sqlc.narg(end_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(end_time)::timestamptz) AND
+      (sqlc.narg(currency)::text IS NULL OR fa.currency = sqlc.narg(currency)::text)
+ORDER BY t.created_at ASC, t.id ASC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SearchTransfersByCreatedAtDesc :many
+SELECT t.*,
+       fa.owner AS from_account_owner, fa.number AS from_account_number,
+       ta.owner AS to_account_owner, ta.number AS to_account_number
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE ((sqlc.arg(direction)::text IN ('out', 'both') AND
+        fa.owner = sqlc.arg(owner)::text AND
+        (sqlc.narg(account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(account_id)::bigint) AND
+        (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(counterparty_account_id)::bigint)) OR
+       (sqlc.arg(direction)::text IN ('in', 'both') AND
+        ta.owner = sqlc.arg(owner)::text AND
+        (sqlc.narg(account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(account_id)::bigint) AND
+        (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(counterparty_account_id)::bigint))) AND
+      (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount)::bigint) AND
+      (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount)::bigint) AND
+      (sqlc.narg(start_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(start_time)::timestamptz) AND
+      (sqlc.narg(end_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(end_time)::timestamptz) AND
+      (sqlc.narg(currency)::text IS NULL OR fa.currency = sqlc.narg(currency)::text)
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SearchTransfersByAmountAsc :many
+SELECT t.*,
+       fa.owner AS from_account_owner, fa.number AS from_account_number,
+       ta.owner AS to_account_owner, ta.number AS to_account_number
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE ((sqlc.arg(direction)::text IN ('out', 'both') AND
+        fa.owner = sqlc.arg(owner)::text AND
+        (sqlc.narg(account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(account_id)::bigint) AND
+        (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(counterparty_account_id)::bigint)) OR
+       (sqlc.arg(direction)::text IN ('in', 'both') AND
+        ta.owner = sqlc.arg(owner)::text AND
+        (sqlc.narg(account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(account_id)::bigint) AND
+        (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(counterparty_account_id)::bigint))) AND
+      (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount)::bigint) AND
+      (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount)::bigint) AND
+      (sqlc.narg(start_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(start_time)::timestamptz) AND
+      (sqlc.narg(end_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(end_time)::timestamptz) AND
+      (sqlc.narg(currency)::text IS NULL OR fa.currency = sqlc.narg(currency)::text)
+ORDER BY t.amount ASC, t.id ASC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: SearchTransfersByAmountDesc :many
+SELECT t.*,
+       fa.owner AS from_account_owner, fa.number AS from_account_number,
+       ta.owner AS to_account_owner, ta.number AS to_account_number
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE ((sqlc.arg(direction)::text IN ('out', 'both') AND
+        fa.owner = sqlc.arg(owner)::text AND
+        (sqlc.narg(account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(account_id)::bigint) AND
+        (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(counterparty_account_id)::bigint)) OR
+       (sqlc.arg(direction)::text IN ('in', 'both') AND
+        ta.owner = sqlc.arg(owner)::text AND
+        (sqlc.narg(account_id)::bigint IS NULL OR t.to_account_id = sqlc.narg(account_id)::bigint) AND
+        (sqlc.narg(counterparty_account_id)::bigint IS NULL OR t.from_account_id = sqlc.narg(counterparty_account_id)::bigint))) AND
+      (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount)::bigint) AND
+      (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount)::bigint) AND
+      (sqlc.narg(start_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(start_time)::timestamptz) AND
+      (sqlc.narg(end_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(end_time)::timestamptz) AND
+      (sqlc.narg(currency)::text IS NULL OR fa.currency = sqlc.narg(currency)::text)
+ORDER BY t.amount DESC, t.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: CountTransfersBetween :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND to_account_id = $2 AND status = 'completed';
+
+-- name: CountTransfersSince :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2;
+
+-- name: GetTransferAmountStats :one
+SELECT COUNT(*) AS transfers,
+       COALESCE(AVG(amount), 0)::bigint AS average_amount
+FROM transfers
+WHERE from_account_id = $1 AND status = 'completed';
@@ -7,13 +7,14 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, type, nickname, number, status, closed_at
 `
 
 type AddAccountBalanceParams struct {
@@ -30,28 +31,85 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.Number,
+		&i.Status,
+		&i.ClosedAt,
 	)
 	return i, err
 }
 
+const closeAccount = `-- name: CloseAccount :one
+UPDATE accounts
+SET status = 'closed', closed_at = now()
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, type, nickname, number, status, closed_at
+`
+
+func (q *Queries) CloseAccount(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, closeAccount, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.Number,
+		&i.Status,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const countAccountsByCurrency = `-- name: CountAccountsByCurrency :one
+SELECT count(*) FROM accounts
+WHERE owner = $1 AND currency = $2 AND status = 'open'
+`
+
+type CountAccountsByCurrencyParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) CountAccountsByCurrency(ctx context.Context, arg CountAccountsByCurrencyParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAccountsByCurrency, arg.Owner, arg.Currency)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
     owner,
     balance,
-    currency
+    currency,
+    type,
+    nickname
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+    $1, $2, $3, $4, $5
+) RETURNING id, owner, balance, currency, created_at, type, nickname, number, status, closed_at
 `
 
 type CreateAccountParams struct {
 	Owner    string `json:"owner"`
 	Balance  int64  `json:"balance"`
 	Currency string `json:"currency"`
+	Type     string `json:"type"`
+	Nickname string `json:"nickname"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Type,
+		arg.Nickname,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -59,6 +117,11 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.Number,
+		&i.Status,
+		&i.ClosedAt,
 	)
 	return i, err
 }
@@ -74,7 +137,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, type, nickname, number, status, closed_at FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -87,12 +150,40 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.Number,
+		&i.Status,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const getAccountByNumber = `-- name: GetAccountByNumber :one
+SELECT id, owner, balance, currency, created_at, type, nickname, number, status, closed_at FROM accounts
+WHERE number = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountByNumber(ctx context.Context, number string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByNumber, number)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.Number,
+		&i.Status,
+		&i.ClosedAt,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, type, nickname, number, status, closed_at FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -106,12 +197,78 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.Number,
+		&i.Status,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const getDefaultAccount = `-- name: GetDefaultAccount :one
+SELECT id, owner, balance, currency, created_at, type, nickname, number, status, closed_at FROM accounts
+WHERE owner = $1 AND currency = $2 AND type IN ('checking', 'savings') AND status = 'open'
+ORDER BY type = 'checking' DESC, id
+LIMIT 1
+`
+
+type GetDefaultAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetDefaultAccount(ctx context.Context, arg GetDefaultAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getDefaultAccount, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.Number,
+		&i.Status,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const getSystemAccount = `-- name: GetSystemAccount :one
+SELECT id, owner, balance, currency, created_at, type, nickname, number, status, closed_at FROM accounts
+WHERE owner = $1 AND currency = $2 AND type = $3
+ORDER BY id
+LIMIT 1
+`
+
+type GetSystemAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+	Type     string `json:"type"`
+}
+
+func (q *Queries) GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getSystemAccount, arg.Owner, arg.Currency, arg.Type)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.Number,
+		&i.Status,
+		&i.ClosedAt,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, type, nickname, number, status, closed_at FROM accounts
 WHERE owner = $1
 ORDER BY id
 LIMIT $2
@@ -139,6 +296,11 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.Type,
+			&i.Nickname,
+			&i.Number,
+			&i.Status,
+			&i.ClosedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -153,20 +315,36 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
-const updateAccounts = `-- name: UpdateAccounts :one
-UPDATE accounts
-SET balance = $2
-WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+const notifyAccountChange = `-- name: NotifyAccountChange :exec
+SELECT pg_notify($1::text, $2::text)
+`
+
+type NotifyAccountChangeParams struct {
+	Channel string `json:"channel"`
+	Payload string `json:"payload"`
+}
+
+func (q *Queries) NotifyAccountChange(ctx context.Context, arg NotifyAccountChangeParams) error {
+	_, err := q.db.ExecContext(ctx, notifyAccountChange, arg.Channel, arg.Payload)
+	return err
+}
+
+const updateAccount = `-- name: UpdateAccount :one
+UPDATE accounts SET
+    nickname = COALESCE($1, nickname),
+    type = COALESCE($2, type)
+WHERE id = $3
+RETURNING id, owner, balance, currency, created_at, type, nickname, number, status, closed_at
 `
 
-type UpdateAccountsParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+type UpdateAccountParams struct {
+	Nickname sql.NullString `json:"nickname"`
+	Type     sql.NullString `json:"type"`
+	ID       int64          `json:"id"`
 }
 
-func (q *Queries) UpdateAccounts(ctx context.Context, arg UpdateAccountsParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, updateAccounts, arg.ID, arg.Balance)
+func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccount, arg.Nickname, arg.Type, arg.ID)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -174,6 +352,11 @@ func (q *Queries) UpdateAccounts(ctx context.Context, arg UpdateAccountsParams)
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.Type,
+		&i.Nickname,
+		&i.Number,
+		&i.Status,
+		&i.ClosedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// AccountChangesChannel is the Postgres channel notified about the entries posted to accounts.
+const AccountChangesChannel = "account_changes"
+
+// AccountChange is the payload of a notification about an entry posted to an account.
+type AccountChange struct {
+	// Account is the state of the account right after the entry was posted.
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// notifyAccountChanges notifies the listeners of AccountChangesChannel about the posted entries.
+// Postgres delivers the notifications only when the transaction commits.
+// It must be called within the same transaction as the entries.
+func notifyAccountChanges(ctx context.Context, q *Queries, accounts map[int64]Account, entries ...Entry) error {
+	for _, entry := range entries {
+		data, err := json.Marshal(AccountChange{
+			Account: accounts[entry.AccountID],
+			Entry:   entry,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot marshal account change: %w", err)
+		}
+
+		err = q.NotifyAccountChange(ctx, NotifyAccountChangeParams{
+			Channel: AccountChangesChannel,
+			Payload: string(data),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
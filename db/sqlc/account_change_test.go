@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxNotifiesAccountChanges(t *testing.T) {
+	listener := pq.NewListener(testDBSource, time.Second, time.Minute, nil)
+	defer listener.Close()
+
+	require.NoError(t, listener.Listen(AccountChangesChannel))
+
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	changes := make(map[int64]AccountChange)
+
+	// other tests may transfer concurrently, so skip their changes
+	for len(changes) < 2 {
+		select {
+		case notification := <-listener.Notify:
+			require.NotNil(t, notification)
+
+			var change AccountChange
+			require.NoError(t, json.Unmarshal([]byte(notification.Extra), &change))
+
+			if change.Entry.ID == result.FromEntry.ID || change.Entry.ID == result.ToEntry.ID {
+				changes[change.Entry.AccountID] = change
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no account change notified")
+		}
+	}
+
+	require.Equal(t, result.FromAccount.Balance, changes[account1.ID].Account.Balance)
+	require.Equal(t, -int64(10), changes[account1.ID].Entry.Amount)
+	require.Equal(t, result.ToAccount.Balance, changes[account2.ID].Account.Balance)
+	require.Equal(t, int64(10), changes[account2.ID].Entry.Amount)
+}
+
+func TestFailedTransferTxNotifiesNothing(t *testing.T) {
+	listener := pq.NewListener(testDBSource, time.Second, time.Minute, nil)
+	defer listener.Close()
+
+	require.NoError(t, listener.Listen(AccountChangesChannel))
+
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+
+	// the transfer is rolled back since the recipient doesn't exist
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   -1,
+		Amount:        10,
+	})
+	require.Error(t, err)
+
+	for {
+		select {
+		case notification := <-listener.Notify:
+			var change AccountChange
+			require.NoError(t, json.Unmarshal([]byte(notification.Extra), &change))
+			require.NotEqual(t, account.ID, change.Account.ID)
+		case <-time.After(time.Second):
+			return
+		}
+	}
+}
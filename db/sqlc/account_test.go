@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/ifantsai/simple-bank-api/validator"
 	"github.com/stretchr/testify/require"
 )
 
@@ -27,25 +28,96 @@ func TestGetAccount(t *testing.T) {
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
+func TestGetAccountByNumber(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2, err := testQueries.GetAccountByNumber(context.Background(), account1.Number)
+	require.NoError(t, err)
+
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, account1.Number, account2.Number)
+}
+
 func TestUpdateAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
 
-	arg := UpdateAccountsParams{
-		ID:      account1.ID,
-		Balance: util.RandomMoney(),
+	arg := UpdateAccountParams{
+		ID: account1.ID,
+		Nickname: sql.NullString{
+			String: util.RandomOwner(),
+			Valid:  true,
+		},
 	}
 
-	account2, err := testQueries.UpdateAccounts(context.Background(), arg)
+	account2, err := testQueries.UpdateAccount(context.Background(), arg)
 	require.NoError(t, err)
 	require.NotEmpty(t, account2)
 
 	require.Equal(t, account1.ID, account2.ID)
 	require.Equal(t, account1.Owner, account2.Owner)
-	require.Equal(t, arg.Balance, account2.Balance)
+	require.Equal(t, account1.Balance, account2.Balance)
 	require.Equal(t, account1.Currency, account2.Currency)
+	require.Equal(t, account1.Type, account2.Type)
+	require.Equal(t, arg.Nickname.String, account2.Nickname)
 	require.WithinDuration(t, account1.CreatedAt, account2.CreatedAt, time.Second)
 }
 
+func TestCreateAccountTxLimit(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	arg := CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Currency: util.USD,
+			Type:     util.Checking,
+		},
+		MaxPerCurrency: 2,
+	}
+
+	for i := 0; i < 2; i++ {
+		account, err := store.CreateAccountTx(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, user.Username, account.Owner)
+	}
+
+	_, err := store.CreateAccountTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrAccountLimitReached)
+
+	arg.Currency = util.EUR
+	_, err = store.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestCloseAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccount(t)
+
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{ID: account1.ID})
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account1.ID,
+		Amount: -account1.Balance,
+	})
+	require.NoError(t, err)
+
+	account2, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{ID: account1.ID})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, account2.Status)
+	require.True(t, account2.ClosedAt.Valid)
+
+	// the account is kept for its entries and the audit log
+	account3, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2, account3)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{ID: account1.ID})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	_, err = addMoney(context.Background(), testQueries, map[int64]int64{account1.ID: 10})
+	require.ErrorIs(t, err, ErrAccountClosed)
+}
+
 func TestDeleteAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
 	err := testQueries.DeleteAccount(context.Background(), account1.ID)
@@ -86,6 +158,7 @@ func createRandomAccount(t *testing.T) Account {
 		Owner:    user.Username,
 		Balance:  util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		Type:     util.Checking,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -95,9 +168,11 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Type, account.Type)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
+	require.NoError(t, validator.ValidateAccountNumber(account.Number))
 
 	return account
 }
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// Statuses of accounts.
+const (
+	AccountStatusOpen   = "open"
+	AccountStatusClosed = "closed"
+)
+
+var (
+	// ErrAccountNotEmpty is returned when closing an account that still holds money.
+	ErrAccountNotEmpty = errors.New("account balance is not zero")
+	// ErrAccountClosed is returned when money is moved from or to a closed account, or when it is closed again.
+	ErrAccountClosed = errors.New("account is closed")
+	// ErrAccountLimitReached is returned when a user already has the maximum number of accounts in a currency.
+	ErrAccountLimitReached = errors.New("maximum number of accounts for this currency reached")
+)
+
+// CreateAccountTxParams contains the input parameters of the create account transaction.
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	// MaxPerCurrency limits the accounts of the owner in the same currency, 0 means unlimited.
+	MaxPerCurrency int64     `json:"-"`
+	Audit          AuditMeta `json:"-"`
+}
+
+// CreateAccountTx creates a new account and records it in the audit log within a single database transaction.
+func (s *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error) {
+	var account Account
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		// lock the owner so that concurrent requests cannot exceed the limit
+		_, err := q.GetUserForUpdate(ctx, arg.Owner)
+		if err != nil {
+			return err
+		}
+
+		if arg.MaxPerCurrency > 0 {
+			count, err := q.CountAccountsByCurrency(ctx, CountAccountsByCurrencyParams{
+				Owner:    arg.Owner,
+				Currency: arg.Currency,
+			})
+			if err != nil {
+				return err
+			}
+
+			if count >= arg.MaxPerCurrency {
+				return ErrAccountLimitReached
+			}
+		}
+
+		account, err = q.CreateAccount(ctx, arg.CreateAccountParams)
+		if err != nil {
+			return err
+		}
+
+		accountID := strconv.FormatInt(account.ID, 10)
+
+		_, err = appendAuditEvent(ctx, q, arg.Audit,
+			AuditActionCreateAccount, AuditResourceAccount, accountID,
+			nil, account,
+		)
+		if err != nil {
+			return err
+		}
+
+		return addOutboxEvent(ctx, q, OutboxAggregateAccount, accountID, EventAccountCreated, account)
+	})
+
+	return account, err
+}
+
+// UpdateAccountTxParams contains the input parameters of the update account transaction.
+type UpdateAccountTxParams struct {
+	UpdateAccountParams
+	Audit AuditMeta `json:"-"`
+}
+
+// UpdateAccountTx updates the nickname or type of an account and records the change in the audit log
+// within a single database transaction. The balance can only be changed by transfers.
+func (s *SQLStore) UpdateAccountTx(ctx context.Context, arg UpdateAccountTxParams) (Account, error) {
+	var account Account
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		account, err = q.UpdateAccount(ctx, arg.UpdateAccountParams)
+		if err != nil {
+			return err
+		}
+
+		accountID := strconv.FormatInt(account.ID, 10)
+
+		_, err = appendAuditEvent(ctx, q, arg.Audit,
+			AuditActionUpdateAccount, AuditResourceAccount, accountID,
+			before, account,
+		)
+		if err != nil {
+			return err
+		}
+
+		return addOutboxEvent(ctx, q, OutboxAggregateAccount, accountID, EventAccountUpdated, account)
+	})
+
+	return account, err
+}
+
+// CloseAccountTxParams contains the input parameters of the close account transaction.
+type CloseAccountTxParams struct {
+	ID    int64     `json:"id"`
+	Audit AuditMeta `json:"-"`
+}
+
+// CloseAccountTx closes an account with zero balance and records it in the audit log
+// within a single database transaction. The account is kept with its entries, it only stops moving money.
+func (s *SQLStore) CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (Account, error) {
+	var account Account
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if before.Status == AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		if before.Balance != 0 {
+			return ErrAccountNotEmpty
+		}
+
+		account, err = q.CloseAccount(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		accountID := strconv.FormatInt(account.ID, 10)
+
+		_, err = appendAuditEvent(ctx, q, arg.Audit,
+			AuditActionCloseAccount, AuditResourceAccount, accountID,
+			before, account,
+		)
+		if err != nil {
+			return err
+		}
+
+		return addOutboxEvent(ctx, q, OutboxAggregateAccount, accountID, EventAccountClosed, account)
+	})
+
+	return account, err
+}
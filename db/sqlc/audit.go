@@ -0,0 +1,232 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Actions recorded in the audit log.
+const (
+	AuditActionTransfer        = "transfer"
+	AuditActionHoldTransfer    = "transfer.hold"
+	AuditActionApproveTransfer = "transfer.approve"
+	AuditActionRejectTransfer  = "transfer.reject"
+	AuditActionCreateAccount   = "account.create"
+	AuditActionUpdateAccount   = "account.update"
+	AuditActionCloseAccount    = "account.close"
+	AuditActionSetInterestRate = "account.interest_rate"
+	AuditActionSetAccountLimit = "account.limit"
+	AuditActionUpdateUser      = "user.update"
+	AuditActionSetUserTier     = "user.tier"
+	AuditActionSetTierLimit    = "tier_limit.update"
+	AuditActionRevokeSession   = "session.revoke"
+)
+
+// Resource types recorded in the audit log.
+const (
+	AuditResourceTransfer  = "transfer"
+	AuditResourceAccount   = "account"
+	AuditResourceUser      = "user"
+	AuditResourceSession   = "session"
+	AuditResourceTierLimit = "tier_limit"
+)
+
+const (
+	// auditSystemActor is recorded when a change is not made on behalf of a user.
+	auditSystemActor = "system"
+	// auditGenesisHash is the previous hash of the first event in the chain.
+	auditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+	// auditChainLockKey serializes appends so that every event links to its predecessor.
+	auditChainLockKey   = 0x61756469740001
+	auditVerifyPageSize = 1000
+)
+
+// AuditMeta describes who performed an audited change and from where.
+type AuditMeta struct {
+	Actor    string `json:"actor"`
+	ClientIP string `json:"client_ip"`
+}
+
+// AuditChainError reports the first event that breaks the audit hash chain.
+type AuditChainError struct {
+	EventID int64
+	Reason  string
+}
+
+func (e *AuditChainError) Error() string {
+	return fmt.Sprintf("audit chain broken at event %d: %s", e.EventID, e.Reason)
+}
+
+// AuditChainReport is the result of a successful audit chain verification.
+type AuditChainReport struct {
+	Events   int64  `json:"events"`
+	LastID   int64  `json:"last_id"`
+	LastHash string `json:"last_hash"`
+}
+
+// appendAuditEvent links a new event to the end of the audit chain.
+// It must be called within the same transaction as the audited change.
+func appendAuditEvent(
+	ctx context.Context,
+	q *Queries,
+	meta AuditMeta,
+	action, resourceType, resourceID string,
+	before, after interface{},
+) (AuditEvent, error) {
+	beforeState, err := json.Marshal(before)
+	if err != nil {
+		return AuditEvent{}, fmt.Errorf("cannot marshal audit before state: %w", err)
+	}
+
+	afterState, err := json.Marshal(after)
+	if err != nil {
+		return AuditEvent{}, fmt.Errorf("cannot marshal audit after state: %w", err)
+	}
+
+	if err := q.LockAuditChain(ctx, auditChainLockKey); err != nil {
+		return AuditEvent{}, err
+	}
+
+	prevHash := auditGenesisHash
+
+	last, err := q.GetLastAuditEvent(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return AuditEvent{}, err
+	}
+
+	if err == nil {
+		prevHash = last.Hash
+	}
+
+	if meta.Actor == "" {
+		meta.Actor = auditSystemActor
+	}
+
+	arg := CreateAuditEventParams{
+		Actor:        meta.Actor,
+		ClientIp:     meta.ClientIP,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		BeforeState:  beforeState,
+		AfterState:   afterState,
+		PrevHash:     prevHash,
+		// timestamptz only keeps microseconds
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	arg.Hash, err = AuditEventHash(AuditEvent{
+		Actor:        arg.Actor,
+		ClientIp:     arg.ClientIp,
+		Action:       arg.Action,
+		ResourceType: arg.ResourceType,
+		ResourceID:   arg.ResourceID,
+		BeforeState:  arg.BeforeState,
+		AfterState:   arg.AfterState,
+		PrevHash:     arg.PrevHash,
+		CreatedAt:    arg.CreatedAt,
+	})
+	if err != nil {
+		return AuditEvent{}, err
+	}
+
+	return q.CreateAuditEvent(ctx, arg)
+}
+
+// AuditEventHash computes the chained hash of an audit event.
+// The hash covers the previous hash and all content fields, so modifying,
+// removing or reordering any event changes every following hash.
+func AuditEventHash(event AuditEvent) (string, error) {
+	beforeState, err := canonicalJSON(event.BeforeState)
+	if err != nil {
+		return "", fmt.Errorf("cannot canonicalize before state: %w", err)
+	}
+
+	afterState, err := canonicalJSON(event.AfterState)
+	if err != nil {
+		return "", fmt.Errorf("cannot canonicalize after state: %w", err)
+	}
+
+	content, err := json.Marshal([]interface{}{
+		event.PrevHash,
+		event.Actor,
+		event.ClientIp,
+		event.Action,
+		event.ResourceType,
+		event.ResourceID,
+		beforeState,
+		afterState,
+		event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", fmt.Errorf("cannot marshal audit event: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// VerifyAuditChain walks the whole audit log in order and recomputes every hash.
+// It returns an *AuditChainError describing the first event that does not match.
+func VerifyAuditChain(ctx context.Context, q Querier) (AuditChainReport, error) {
+	report := AuditChainReport{LastHash: auditGenesisHash}
+
+	for {
+		events, err := q.ListAuditEvents(ctx, ListAuditEventsParams{
+			AfterID:    report.LastID,
+			LimitCount: auditVerifyPageSize,
+		})
+		if err != nil {
+			return report, err
+		}
+
+		for _, event := range events {
+			if event.PrevHash != report.LastHash {
+				return report, &AuditChainError{EventID: event.ID, Reason: "previous hash mismatch"}
+			}
+
+			hash, err := AuditEventHash(event)
+			if err != nil {
+				return report, &AuditChainError{EventID: event.ID, Reason: err.Error()}
+			}
+
+			if hash != event.Hash {
+				return report, &AuditChainError{EventID: event.ID, Reason: "content hash mismatch"}
+			}
+
+			report.Events++
+			report.LastID = event.ID
+			report.LastHash = event.Hash
+		}
+
+		if len(events) < auditVerifyPageSize {
+			return report, nil
+		}
+	}
+}
+
+// canonicalJSON re-encodes a JSON document with sorted object keys,
+// since jsonb does not preserve the formatting of the inserted document.
+func canonicalJSON(data json.RawMessage) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return json.RawMessage("null"), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEventHash(t *testing.T) {
+	event := AuditEvent{
+		Actor:        "alice",
+		ClientIp:     "127.0.0.1",
+		Action:       AuditActionTransfer,
+		ResourceType: AuditResourceTransfer,
+		ResourceID:   "1",
+		BeforeState:  json.RawMessage(`{"balance": 100, "currency": "USD"}`),
+		AfterState:   json.RawMessage(`{"currency":"USD","balance":90}`),
+		PrevHash:     auditGenesisHash,
+		CreatedAt:    time.Now(),
+	}
+
+	hash1, err := AuditEventHash(event)
+	require.NoError(t, err)
+	require.Len(t, hash1, 64)
+
+	// jsonb reorders keys and drops whitespace, which must not change the hash
+	event.BeforeState = json.RawMessage(`{"currency":"USD","balance":100}`)
+	hash2, err := AuditEventHash(event)
+	require.NoError(t, err)
+	require.Equal(t, hash1, hash2)
+
+	event.AfterState = json.RawMessage(`{"currency":"USD","balance":80}`)
+	hash3, err := AuditEventHash(event)
+	require.NoError(t, err)
+	require.NotEqual(t, hash1, hash3)
+}
+
+func TestTransferTxAuditEvent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Audit:         AuditMeta{Actor: account1.Owner, ClientIP: "127.0.0.1"},
+	})
+	require.NoError(t, err)
+
+	event, err := testQueries.GetLastAuditEvent(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Owner, event.Actor)
+	require.Equal(t, "127.0.0.1", event.ClientIp)
+	require.Equal(t, AuditActionTransfer, event.Action)
+	require.Equal(t, strconv.FormatInt(result.Transfer.ID, 10), event.ResourceID)
+
+	hash, err := AuditEventHash(event)
+	require.NoError(t, err)
+	require.Equal(t, event.Hash, hash)
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	account, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: 1,
+	})
+	require.NoError(t, err)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		ID:    account.ID,
+		Audit: AuditMeta{Actor: account.Owner},
+	})
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	_, err = store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    createRandomUser(t).Username,
+			Currency: account.Currency,
+			Type:     account.Type,
+		},
+	})
+	require.NoError(t, err)
+
+	report, err := VerifyAuditChain(context.Background(), testQueries)
+	require.NoError(t, err)
+	require.NotZero(t, report.Events)
+
+	last, err := testQueries.GetLastAuditEvent(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, last.ID, report.LastID)
+	require.Equal(t, last.Hash, report.LastHash)
+
+	// the audit log is append-only
+	_, err = testDB.Exec("UPDATE audit_events SET actor = 'mallory' WHERE id = $1", last.ID)
+	require.Error(t, err)
+}
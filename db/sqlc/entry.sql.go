@@ -7,36 +7,53 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    description,
+    category,
+    transfer_id
 ) VALUES (
-    $1, $2
-) RETURNING id, account_id, amount, created_at
+    $1, $2, $3, $4, $5
+) RETURNING id, account_id, amount, created_at, description, category, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID   int64         `json:"account_id"`
+	Amount      int64         `json:"amount"`
+	Description string        `json:"description"`
+	Category    string        `json:"category"`
+	TransferID  sql.NullInt64 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.Description,
+		arg.Category,
+		arg.TransferID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Description,
+		&i.Category,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, description, category, transfer_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,38 +65,78 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Description,
+		&i.Category,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
-WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3
+SELECT e.id, e.account_id, e.amount, e.created_at, e.description, e.category, e.transfer_id,
+       COALESCE(t.client_reference, '')::text AS transfer_reference,
+       COALESCE(c.number, '')::text AS counterparty_account_number
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN accounts c ON c.id = CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END
+WHERE e.account_id = $1 AND
+      ($2::timestamptz IS NULL OR e.created_at >= $2::timestamptz) AND
+      ($3::timestamptz IS NULL OR e.created_at < $3::timestamptz) AND
+      e.id > $4
+ORDER BY e.id
+LIMIT $6
+OFFSET $5
 `
 
 type ListEntriesParams struct {
-	AccountID int64 `json:"account_id"`
-	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
+	AccountID int64        `json:"account_id"`
+	StartTime sql.NullTime `json:"start_time"`
+	EndTime   sql.NullTime `json:"end_time"`
+	AfterID   int64        `json:"after_id"`
+	Offset    int32        `json:"offset"`
+	Limit     int32        `json:"limit"`
+}
+
+type ListEntriesRow struct {
+	ID                        int64         `json:"id"`
+	AccountID                 int64         `json:"account_id"`
+	Amount                    int64         `json:"amount"`
+	CreatedAt                 time.Time     `json:"created_at"`
+	Description               string        `json:"description"`
+	Category                  string        `json:"category"`
+	TransferID                sql.NullInt64 `json:"transfer_id"`
+	TransferReference         string        `json:"transfer_reference"`
+	CounterpartyAccountNumber string        `json:"counterparty_account_number"`
 }
 
-func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+// entries can be paged by id, so that no entry is skipped or repeated when entries are added meanwhile,
+// and come with the reference and the counterparty of their transfer
+func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]ListEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEntries,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.AfterID,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Entry{}
+	items := []ListEntriesRow{}
 	for rows.Next() {
-		var i Entry
+		var i ListEntriesRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Description,
+			&i.Category,
+			&i.TransferID,
+			&i.TransferReference,
+			&i.CounterpartyAccountNumber,
 		); err != nil {
 			return nil, err
 		}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -49,6 +50,74 @@ func TestListEntries(t *testing.T) {
 	}
 }
 
+func TestListEntriesInRange(t *testing.T) {
+	account := createRandomAccount(t)
+	entry1 := createRandomEntry(t, account)
+	entry2 := createRandomEntry(t, account)
+
+	arg := ListEntriesParams{
+		AccountID: account.ID,
+		StartTime: sql.NullTime{Time: entry2.CreatedAt, Valid: true},
+		EndTime:   sql.NullTime{Time: entry2.CreatedAt.Add(time.Microsecond), Valid: true},
+		Limit:     5,
+	}
+
+	entries, err := testQueries.ListEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, entry2.ID, entries[0].ID)
+
+	arg.EndTime = sql.NullTime{Time: entry2.CreatedAt, Valid: true}
+	arg.StartTime = sql.NullTime{Time: entry1.CreatedAt, Valid: true}
+
+	entries, err = testQueries.ListEntries(context.Background(), arg)
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		require.NotEqual(t, entry2.ID, entry.ID)
+	}
+}
+
+func TestListEntriesOfTransfer(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	transfer := createRandomTransfer(t, account1, account2)
+
+	entry1, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID:  account1.ID,
+		Amount:     -transfer.Amount,
+		TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	entry2 := createRandomEntry(t, account1)
+
+	arg := ListEntriesParams{
+		AccountID: account1.ID,
+		StartTime: sql.NullTime{Time: entry1.CreatedAt, Valid: true},
+		EndTime:   sql.NullTime{Time: entry2.CreatedAt.Add(time.Second), Valid: true},
+		Limit:     1,
+	}
+
+	entries, err := testQueries.ListEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, entry1.ID, entries[0].ID)
+	require.Equal(t, transfer.ID, entries[0].TransferID.Int64)
+	require.Equal(t, transfer.ClientReference, entries[0].TransferReference)
+	require.Equal(t, account2.Number, entries[0].CounterpartyAccountNumber)
+
+	arg.AfterID = entries[0].ID
+
+	entries, err = testQueries.ListEntries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, entry2.ID, entries[0].ID)
+	require.False(t, entries[0].TransferID.Valid)
+	require.Empty(t, entries[0].TransferReference)
+	require.Empty(t, entries[0].CounterpartyAccountNumber)
+}
+
 func createRandomEntry(t *testing.T, account Account) Entry {
 	arg := CreateEntryParams{
 		AccountID: account.ID,
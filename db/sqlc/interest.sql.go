@@ -0,0 +1,285 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: interest.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createInterestAccrual = `-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+    account_id,
+    accrual_date,
+    balance,
+    rate_bps,
+    amount_micros
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (account_id, accrual_date) DO NOTHING
+RETURNING id, account_id, accrual_date, balance, rate_bps, amount_micros, created_at
+`
+
+type CreateInterestAccrualParams struct {
+	AccountID    int64     `json:"account_id"`
+	AccrualDate  time.Time `json:"accrual_date"`
+	Balance      int64     `json:"balance"`
+	RateBps      int32     `json:"rate_bps"`
+	AmountMicros int64     `json:"amount_micros"`
+}
+
+func (q *Queries) CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error) {
+	row := q.db.QueryRowContext(ctx, createInterestAccrual,
+		arg.AccountID,
+		arg.AccrualDate,
+		arg.Balance,
+		arg.RateBps,
+		arg.AmountMicros,
+	)
+	var i InterestAccrual
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.AccrualDate,
+		&i.Balance,
+		&i.RateBps,
+		&i.AmountMicros,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createInterestPosting = `-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+    account_id,
+    period_end,
+    amount,
+    transfer_id
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING id, account_id, period_end, amount, transfer_id, created_at
+`
+
+type CreateInterestPostingParams struct {
+	AccountID  int64         `json:"account_id"`
+	PeriodEnd  time.Time     `json:"period_end"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+func (q *Queries) CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error) {
+	row := q.db.QueryRowContext(ctx, createInterestPosting,
+		arg.AccountID,
+		arg.PeriodEnd,
+		arg.Amount,
+		arg.TransferID,
+	)
+	var i InterestPosting
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.PeriodEnd,
+		&i.Amount,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getAccountInterestRate = `-- name: GetAccountInterestRate :one
+SELECT account_id, rate_bps, updated_at FROM account_interest_rates
+WHERE account_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountInterestRate(ctx context.Context, accountID int64) (AccountInterestRate, error) {
+	row := q.db.QueryRowContext(ctx, getAccountInterestRate, accountID)
+	var i AccountInterestRate
+	err := row.Scan(&i.AccountID, &i.RateBps, &i.UpdatedAt)
+	return i, err
+}
+
+const getInterestPosting = `-- name: GetInterestPosting :one
+SELECT id, account_id, period_end, amount, transfer_id, created_at FROM interest_postings
+WHERE account_id = $1 AND period_end = $2 LIMIT 1
+`
+
+type GetInterestPostingParams struct {
+	AccountID int64     `json:"account_id"`
+	PeriodEnd time.Time `json:"period_end"`
+}
+
+func (q *Queries) GetInterestPosting(ctx context.Context, arg GetInterestPostingParams) (InterestPosting, error) {
+	row := q.db.QueryRowContext(ctx, getInterestPosting, arg.AccountID, arg.PeriodEnd)
+	var i InterestPosting
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.PeriodEnd,
+		&i.Amount,
+		&i.TransferID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLastAccrualDate = `-- name: GetLastAccrualDate :one
+SELECT accrual_date FROM interest_accruals
+ORDER BY accrual_date DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLastAccrualDate(ctx context.Context) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getLastAccrualDate)
+	var accrual_date time.Time
+	err := row.Scan(&accrual_date)
+	return accrual_date, err
+}
+
+const getUnpostedInterest = `-- name: GetUnpostedInterest :one
+SELECT (
+    (SELECT COALESCE(SUM(amount_micros), 0) FROM interest_accruals
+     WHERE interest_accruals.account_id = $1 AND accrual_date <= $2) -
+    (SELECT COALESCE(SUM(amount), 0) * 1000000 FROM interest_postings
+     WHERE interest_postings.account_id = $1)
+)::bigint AS amount_micros
+`
+
+type GetUnpostedInterestParams struct {
+	AccountID int64     `json:"account_id"`
+	PeriodEnd time.Time `json:"period_end"`
+}
+
+func (q *Queries) GetUnpostedInterest(ctx context.Context, arg GetUnpostedInterestParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUnpostedInterest, arg.AccountID, arg.PeriodEnd)
+	var amount_micros int64
+	err := row.Scan(&amount_micros)
+	return amount_micros, err
+}
+
+const listAccountsWithAccruals = `-- name: ListAccountsWithAccruals :many
+SELECT DISTINCT i.account_id FROM interest_accruals i
+JOIN accounts a ON a.id = i.account_id
+WHERE i.accrual_date <= $1 AND i.account_id > $2 AND a.status = 'open'
+ORDER BY i.account_id
+LIMIT $3
+`
+
+type ListAccountsWithAccrualsParams struct {
+	PeriodEnd  time.Time `json:"period_end"`
+	AfterID    int64     `json:"after_id"`
+	LimitCount int32     `json:"limit_count"`
+}
+
+func (q *Queries) ListAccountsWithAccruals(ctx context.Context, arg ListAccountsWithAccrualsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsWithAccruals, arg.PeriodEnd, arg.AfterID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var account_id int64
+		if err := rows.Scan(&account_id); err != nil {
+			return nil, err
+		}
+		items = append(items, account_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestBearingAccounts = `-- name: ListInterestBearingAccounts :many
+SELECT a.id,
+       (a.balance - COALESCE((
+           SELECT SUM(e.amount) FROM entries e
+           WHERE e.account_id = a.id AND e.created_at >= $1
+       ), 0))::bigint AS balance,
+       a.currency,
+       COALESCE(r.rate_bps, $2::integer)::integer AS rate_bps
+FROM accounts a
+LEFT JOIN account_interest_rates r ON r.account_id = a.id
+WHERE a.type = 'savings' AND a.status = 'open'
+  AND a.created_at < $1 AND a.id > $3
+ORDER BY a.id
+LIMIT $4
+`
+
+type ListInterestBearingAccountsParams struct {
+	DayEnd         time.Time `json:"day_end"`
+	DefaultRateBps int32     `json:"default_rate_bps"`
+	AfterID        int64     `json:"after_id"`
+	LimitCount     int32     `json:"limit_count"`
+}
+
+type ListInterestBearingAccountsRow struct {
+	ID       int64  `json:"id"`
+	Balance  int64  `json:"balance"`
+	Currency string `json:"currency"`
+	RateBps  int32  `json:"rate_bps"`
+}
+
+// The balance is the one at day_end: entries made since then are taken off the current balance.
+func (q *Queries) ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestBearingAccounts,
+		arg.DayEnd,
+		arg.DefaultRateBps,
+		arg.AfterID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListInterestBearingAccountsRow{}
+	for rows.Next() {
+		var i ListInterestBearingAccountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Balance,
+			&i.Currency,
+			&i.RateBps,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAccountInterestRate = `-- name: SetAccountInterestRate :one
+INSERT INTO account_interest_rates (
+    account_id,
+    rate_bps
+) VALUES (
+    $1, $2
+) ON CONFLICT (account_id) DO UPDATE
+SET rate_bps = EXCLUDED.rate_bps, updated_at = now()
+RETURNING account_id, rate_bps, updated_at
+`
+
+type SetAccountInterestRateParams struct {
+	AccountID int64 `json:"account_id"`
+	RateBps   int32 `json:"rate_bps"`
+}
+
+func (q *Queries) SetAccountInterestRate(ctx context.Context, arg SetAccountInterestRateParams) (AccountInterestRate, error) {
+	row := q.db.QueryRowContext(ctx, setAccountInterestRate, arg.AccountID, arg.RateBps)
+	var i AccountInterestRate
+	err := row.Scan(&i.AccountID, &i.RateBps, &i.UpdatedAt)
+	return i, err
+}
@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ifantsai/simple-bank-api/util"
+)
+
+const (
+	// MicrosPerUnit is the number of accrual units in one minor currency unit.
+	MicrosPerUnit = 1_000_000
+	// interestCategory is the transfer category of interest postings.
+	interestCategory = "interest"
+)
+
+var (
+	// ErrInterestAlreadyPosted is returned when interest of an account was already posted for a period.
+	ErrInterestAlreadyPosted = errors.New("interest already posted for this period")
+	// ErrNotSavingsAccount is returned when setting the interest rate of an account which doesn't earn interest.
+	ErrNotSavingsAccount = errors.New("only savings accounts earn interest")
+)
+
+// PostInterestTxParams contains the input parameters of the post interest transaction.
+type PostInterestTxParams struct {
+	AccountID        int64     `json:"account_id"`
+	ExpenseAccountID int64     `json:"expense_account_id"`
+	PeriodEnd        time.Time `json:"period_end"`
+}
+
+// PostInterestTxResult is the result of the post interest transaction.
+type PostInterestTxResult struct {
+	Posting InterestPosting `json:"posting"`
+	// Transfer is empty when the accrued interest is less than one minor unit.
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// PostInterestTx pays the whole minor units of interest accrued until the period end
+// from the bank's interest expense account. The fraction below one minor unit is carried
+// over to the next period. It posts at most once per account and period.
+func (s *SQLStore) PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error) {
+	var result PostInterestTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		// serialize postings of the same account
+		_, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.GetInterestPosting(ctx, GetInterestPostingParams{
+			AccountID: arg.AccountID,
+			PeriodEnd: arg.PeriodEnd,
+		})
+		if err == nil {
+			return ErrInterestAlreadyPosted
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		micros, err := q.GetUnpostedInterest(ctx, GetUnpostedInterestParams{
+			AccountID: arg.AccountID,
+			PeriodEnd: arg.PeriodEnd,
+		})
+		if err != nil {
+			return err
+		}
+
+		posting := CreateInterestPostingParams{
+			AccountID: arg.AccountID,
+			PeriodEnd: arg.PeriodEnd,
+			Amount:    micros / MicrosPerUnit,
+		}
+
+		if posting.Amount > 0 {
+			result.Transfer, err = transfer(ctx, q, TransferTxParams{
+				FromAccountID: arg.ExpenseAccountID,
+				ToAccountID:   arg.AccountID,
+				Amount:        posting.Amount,
+				Description:   fmt.Sprintf("Interest until %s", arg.PeriodEnd.Format("2006-01-02")),
+				Category:      interestCategory,
+			})
+			if err != nil {
+				return err
+			}
+
+			posting.TransferID = sql.NullInt64{
+				Int64: result.Transfer.Transfer.ID,
+				Valid: true,
+			}
+		}
+
+		result.Posting, err = q.CreateInterestPosting(ctx, posting)
+
+		return err
+	})
+
+	return result, err
+}
+
+// SetAccountInterestRateTxParams contains the input parameters of the set account interest rate transaction.
+type SetAccountInterestRateTxParams struct {
+	AccountID int64     `json:"account_id"`
+	RateBps   int32     `json:"rate_bps"`
+	Audit     AuditMeta `json:"-"`
+}
+
+// SetAccountInterestRateTx sets the interest rate of an open savings account, which replaces the default rate
+// from the next accrual, and records the change in the audit log within a single database transaction.
+func (s *SQLStore) SetAccountInterestRateTx(
+	ctx context.Context, arg SetAccountInterestRateTxParams,
+) (AccountInterestRate, error) {
+	var rate AccountInterestRate
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Status == AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		if account.Type != util.Savings {
+			return ErrNotSavingsAccount
+		}
+
+		var before interface{}
+
+		previous, err := q.GetAccountInterestRate(ctx, arg.AccountID)
+		switch {
+		case err == nil:
+			before = previous
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		rate, err = q.SetAccountInterestRate(ctx, SetAccountInterestRateParams{
+			AccountID: arg.AccountID,
+			RateBps:   arg.RateBps,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = appendAuditEvent(ctx, q, arg.Audit,
+			AuditActionSetInterestRate, AuditResourceAccount, strconv.FormatInt(arg.AccountID, 10),
+			before, rate,
+		)
+
+		return err
+	})
+
+	return rate, err
+}
@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Scopes of transfer limits.
+const (
+	LimitScopeUser    = "user"
+	LimitScopeAccount = "account"
+)
+
+// Periods of transfer limits.
+const (
+	LimitPeriodTransaction = "transaction"
+	LimitPeriodDaily       = "daily"
+	LimitPeriodMonthly     = "monthly"
+)
+
+// LimitExceededError is returned when a transfer would exceed a transfer limit.
+type LimitExceededError struct {
+	Scope  string `json:"scope"`
+	Period string `json:"period"`
+	Limit  int64  `json:"limit"`
+	// Remaining is the amount that can still be sent in the period.
+	Remaining int64 `json:"remaining"`
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s %s limit of %d exceeded, remaining allowance is %d", e.Scope, e.Period, e.Limit, e.Remaining)
+}
+
+// transferLimits are the limits of one scope, 0 means unlimited.
+type transferLimits struct {
+	perTransaction int64
+	daily          int64
+	monthly        int64
+}
+
+// checkTransferLimits enforces the limits of the sender's tier and account.
+// Daily and monthly limits are checked against the outgoing entries of the current UTC day and month,
+// including fees. The sender is locked so that concurrent transfers cannot exceed the limits together.
+// It must be called within a database transaction.
+func checkTransferLimits(ctx context.Context, q *Queries, arg TransferTxParams, now time.Time) error {
+	account, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return err
+	}
+
+	user, err := q.GetUserForUpdate(ctx, account.Owner)
+	if err != nil {
+		return err
+	}
+
+	now = now.UTC()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	tierLimit, err := q.GetTierLimit(ctx, GetTierLimitParams{
+		Tier:     user.Tier,
+		Currency: account.Currency,
+	})
+
+	switch {
+	case err == nil:
+		totals, err := q.GetOwnerOutgoingTotals(ctx, GetOwnerOutgoingTotalsParams{
+			DayStart:   dayStart,
+			Owner:      account.Owner,
+			Currency:   account.Currency,
+			MonthStart: monthStart,
+		})
+		if err != nil {
+			return err
+		}
+
+		limits := transferLimits{
+			perTransaction: tierLimit.PerTransaction,
+			daily:          tierLimit.Daily,
+			monthly:        tierLimit.Monthly,
+		}
+
+		if err := limits.check(LimitScopeUser, arg, totals.Daily, totals.Monthly); err != nil {
+			return err
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	accountLimit, err := q.GetAccountLimit(ctx, account.ID)
+
+	switch {
+	case err == nil:
+		totals, err := q.GetAccountOutgoingTotals(ctx, GetAccountOutgoingTotalsParams{
+			DayStart:   dayStart,
+			AccountID:  account.ID,
+			MonthStart: monthStart,
+		})
+		if err != nil {
+			return err
+		}
+
+		limits := transferLimits{
+			perTransaction: accountLimit.PerTransaction,
+			daily:          accountLimit.Daily,
+			monthly:        accountLimit.Monthly,
+		}
+
+		return limits.check(LimitScopeAccount, arg, totals.Daily, totals.Monthly)
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	default:
+		return err
+	}
+}
+
+func (l transferLimits) check(scope string, arg TransferTxParams, daily, monthly int64) error {
+	if l.perTransaction > 0 && arg.Amount > l.perTransaction {
+		return &LimitExceededError{
+			Scope:     scope,
+			Period:    LimitPeriodTransaction,
+			Limit:     l.perTransaction,
+			Remaining: l.perTransaction,
+		}
+	}
+
+	outgoing := arg.Amount + arg.Fee
+
+	if l.daily > 0 && daily+outgoing > l.daily {
+		return &LimitExceededError{
+			Scope:     scope,
+			Period:    LimitPeriodDaily,
+			Limit:     l.daily,
+			Remaining: remainingAllowance(l.daily, daily),
+		}
+	}
+
+	if l.monthly > 0 && monthly+outgoing > l.monthly {
+		return &LimitExceededError{
+			Scope:     scope,
+			Period:    LimitPeriodMonthly,
+			Limit:     l.monthly,
+			Remaining: remainingAllowance(l.monthly, monthly),
+		}
+	}
+
+	return nil
+}
+
+func remainingAllowance(limit, used int64) int64 {
+	if used >= limit {
+		return 0
+	}
+
+	return limit - used
+}
@@ -0,0 +1,208 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: limit.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const countTierLimits = `-- name: CountTierLimits :one
+SELECT count(*) FROM tier_limits
+WHERE tier = $1
+`
+
+func (q *Queries) CountTierLimits(ctx context.Context, tier string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTierLimits, tier)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getAccountLimit = `-- name: GetAccountLimit :one
+SELECT account_id, per_transaction, daily, monthly, updated_at FROM account_limits
+WHERE account_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountLimit(ctx context.Context, accountID int64) (AccountLimit, error) {
+	row := q.db.QueryRowContext(ctx, getAccountLimit, accountID)
+	var i AccountLimit
+	err := row.Scan(
+		&i.AccountID,
+		&i.PerTransaction,
+		&i.Daily,
+		&i.Monthly,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getAccountOutgoingTotals = `-- name: GetAccountOutgoingTotals :one
+SELECT
+    COALESCE(SUM(-amount) FILTER (WHERE created_at >= $1), 0)::bigint AS daily,
+    COALESCE(SUM(-amount), 0)::bigint AS monthly
+FROM entries
+WHERE account_id = $2 AND amount < 0 AND created_at >= $3
+`
+
+type GetAccountOutgoingTotalsParams struct {
+	DayStart   time.Time `json:"day_start"`
+	AccountID  int64     `json:"account_id"`
+	MonthStart time.Time `json:"month_start"`
+}
+
+type GetAccountOutgoingTotalsRow struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+func (q *Queries) GetAccountOutgoingTotals(ctx context.Context, arg GetAccountOutgoingTotalsParams) (GetAccountOutgoingTotalsRow, error) {
+	row := q.db.QueryRowContext(ctx, getAccountOutgoingTotals, arg.DayStart, arg.AccountID, arg.MonthStart)
+	var i GetAccountOutgoingTotalsRow
+	err := row.Scan(&i.Daily, &i.Monthly)
+	return i, err
+}
+
+const getOwnerOutgoingTotals = `-- name: GetOwnerOutgoingTotals :one
+SELECT
+    COALESCE(SUM(-e.amount) FILTER (WHERE e.created_at >= $1), 0)::bigint AS daily,
+    COALESCE(SUM(-e.amount), 0)::bigint AS monthly
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+WHERE a.owner = $2 AND a.currency = $3 AND
+      e.amount < 0 AND e.created_at >= $4
+`
+
+type GetOwnerOutgoingTotalsParams struct {
+	DayStart   time.Time `json:"day_start"`
+	Owner      string    `json:"owner"`
+	Currency   string    `json:"currency"`
+	MonthStart time.Time `json:"month_start"`
+}
+
+type GetOwnerOutgoingTotalsRow struct {
+	Daily   int64 `json:"daily"`
+	Monthly int64 `json:"monthly"`
+}
+
+func (q *Queries) GetOwnerOutgoingTotals(ctx context.Context, arg GetOwnerOutgoingTotalsParams) (GetOwnerOutgoingTotalsRow, error) {
+	row := q.db.QueryRowContext(ctx, getOwnerOutgoingTotals,
+		arg.DayStart,
+		arg.Owner,
+		arg.Currency,
+		arg.MonthStart,
+	)
+	var i GetOwnerOutgoingTotalsRow
+	err := row.Scan(&i.Daily, &i.Monthly)
+	return i, err
+}
+
+const getTierLimit = `-- name: GetTierLimit :one
+SELECT tier, currency, per_transaction, daily, monthly FROM tier_limits
+WHERE tier = $1 AND currency = $2 LIMIT 1
+`
+
+type GetTierLimitParams struct {
+	Tier     string `json:"tier"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetTierLimit(ctx context.Context, arg GetTierLimitParams) (TierLimit, error) {
+	row := q.db.QueryRowContext(ctx, getTierLimit, arg.Tier, arg.Currency)
+	var i TierLimit
+	err := row.Scan(
+		&i.Tier,
+		&i.Currency,
+		&i.PerTransaction,
+		&i.Daily,
+		&i.Monthly,
+	)
+	return i, err
+}
+
+const setAccountLimit = `-- name: SetAccountLimit :one
+INSERT INTO account_limits (
+    account_id,
+    per_transaction,
+    daily,
+    monthly
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (account_id) DO UPDATE
+SET per_transaction = EXCLUDED.per_transaction,
+    daily = EXCLUDED.daily,
+    monthly = EXCLUDED.monthly,
+    updated_at = now()
+RETURNING account_id, per_transaction, daily, monthly, updated_at
+`
+
+type SetAccountLimitParams struct {
+	AccountID      int64 `json:"account_id"`
+	PerTransaction int64 `json:"per_transaction"`
+	Daily          int64 `json:"daily"`
+	Monthly        int64 `json:"monthly"`
+}
+
+func (q *Queries) SetAccountLimit(ctx context.Context, arg SetAccountLimitParams) (AccountLimit, error) {
+	row := q.db.QueryRowContext(ctx, setAccountLimit,
+		arg.AccountID,
+		arg.PerTransaction,
+		arg.Daily,
+		arg.Monthly,
+	)
+	var i AccountLimit
+	err := row.Scan(
+		&i.AccountID,
+		&i.PerTransaction,
+		&i.Daily,
+		&i.Monthly,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setTierLimit = `-- name: SetTierLimit :one
+INSERT INTO tier_limits (
+    tier,
+    currency,
+    per_transaction,
+    daily,
+    monthly
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (tier, currency) DO UPDATE
+SET per_transaction = EXCLUDED.per_transaction,
+    daily = EXCLUDED.daily,
+    monthly = EXCLUDED.monthly
+RETURNING tier, currency, per_transaction, daily, monthly
+`
+
+type SetTierLimitParams struct {
+	Tier           string `json:"tier"`
+	Currency       string `json:"currency"`
+	PerTransaction int64  `json:"per_transaction"`
+	Daily          int64  `json:"daily"`
+	Monthly        int64  `json:"monthly"`
+}
+
+func (q *Queries) SetTierLimit(ctx context.Context, arg SetTierLimitParams) (TierLimit, error) {
+	row := q.db.QueryRowContext(ctx, setTierLimit,
+		arg.Tier,
+		arg.Currency,
+		arg.PerTransaction,
+		arg.Daily,
+		arg.Monthly,
+	)
+	var i TierLimit
+	err := row.Scan(
+		&i.Tier,
+		&i.Currency,
+		&i.PerTransaction,
+		&i.Daily,
+		&i.Monthly,
+	)
+	return i, err
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+)
+
+// ErrUnknownTier is returned when assigning a user to a tier which has no transfer limits.
+var ErrUnknownTier = errors.New("tier has no transfer limits")
+
+// SetAccountLimitTxParams contains the input parameters of the set account limit transaction.
+type SetAccountLimitTxParams struct {
+	SetAccountLimitParams
+	Audit AuditMeta `json:"-"`
+}
+
+// SetAccountLimitTx sets the transfer limits of an open account
+// and records the change in the audit log within a single database transaction.
+func (s *SQLStore) SetAccountLimitTx(ctx context.Context, arg SetAccountLimitTxParams) (AccountLimit, error) {
+	var limit AccountLimit
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Status == AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		var before interface{}
+
+		previous, err := q.GetAccountLimit(ctx, arg.AccountID)
+		switch {
+		case err == nil:
+			before = previous
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		limit, err = q.SetAccountLimit(ctx, arg.SetAccountLimitParams)
+		if err != nil {
+			return err
+		}
+
+		_, err = appendAuditEvent(ctx, q, arg.Audit,
+			AuditActionSetAccountLimit, AuditResourceAccount, strconv.FormatInt(arg.AccountID, 10),
+			before, limit,
+		)
+
+		return err
+	})
+
+	return limit, err
+}
+
+// SetTierLimitTxParams contains the input parameters of the set tier limit transaction.
+type SetTierLimitTxParams struct {
+	SetTierLimitParams
+	Audit AuditMeta `json:"-"`
+}
+
+// SetTierLimitTx creates or replaces the transfer limits of a tier in a currency
+// and records the change in the audit log within a single database transaction.
+func (s *SQLStore) SetTierLimitTx(ctx context.Context, arg SetTierLimitTxParams) (TierLimit, error) {
+	var limit TierLimit
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var before interface{}
+
+		previous, err := q.GetTierLimit(ctx, GetTierLimitParams{
+			Tier:     arg.Tier,
+			Currency: arg.Currency,
+		})
+		switch {
+		case err == nil:
+			before = previous
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		limit, err = q.SetTierLimit(ctx, arg.SetTierLimitParams)
+		if err != nil {
+			return err
+		}
+
+		_, err = appendAuditEvent(ctx, q, arg.Audit,
+			AuditActionSetTierLimit, AuditResourceTierLimit, limit.Tier+"/"+limit.Currency,
+			before, limit,
+		)
+
+		return err
+	})
+
+	return limit, err
+}
+
+// SetUserTierTxParams contains the input parameters of the set user tier transaction.
+type SetUserTierTxParams struct {
+	UpdateUserTierParams
+	Audit AuditMeta `json:"-"`
+}
+
+// SetUserTierTx assigns a user to a tier which has transfer limits
+// and records the change in the audit log within a single database transaction.
+func (s *SQLStore) SetUserTierTx(ctx context.Context, arg SetUserTierTxParams) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		count, err := q.CountTierLimits(ctx, arg.Tier)
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return ErrUnknownTier
+		}
+
+		user, err = q.UpdateUserTier(ctx, arg.UpdateUserTierParams)
+		if err != nil {
+			return err
+		}
+
+		_, err = appendAuditEvent(ctx, q, arg.Audit,
+			AuditActionSetUserTier, AuditResourceUser, user.Username,
+			newAuditUser(before), newAuditUser(user),
+		)
+
+		return err
+	})
+
+	return user, err
+}
@@ -11,8 +11,9 @@ import (
 )
 
 var (
-	testQueries *Queries
-	testDB      *sql.DB
+	testQueries  *Queries
+	testDB       *sql.DB
+	testDBSource string
 )
 
 func TestMain(m *testing.M) {
@@ -21,6 +22,8 @@ func TestMain(m *testing.M) {
 		log.Fatal("cannot load config:", err)
 	}
 
+	testDBSource = config.DBSource
+
 	testDB, err = sql.Open(config.DBDriver, config.DBSource)
 	if err != nil {
 		log.Fatal("cannot connect to db:", err)
@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,16 +18,143 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	Type      string    `json:"type"`
+	Nickname  string    `json:"nickname"`
+	// external account number with mod-97 check digits
+	Number   string       `json:"number"`
+	Status   string       `json:"status"`
+	ClosedAt sql.NullTime `json:"closed_at"`
+}
+
+type AccountInterestRate struct {
+	AccountID int64 `json:"account_id"`
+	// annual rate in basis points
+	RateBps   int32     `json:"rate_bps"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AccountLimit struct {
+	AccountID      int64 `json:"account_id"`
+	PerTransaction int64 `json:"per_transaction"`
+	// outgoing total of the account, 0 means unlimited
+	Daily int64 `json:"daily"`
+	// outgoing total of the account, 0 means unlimited
+	Monthly   int64     `json:"monthly"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type AuditEvent struct {
+	ID           int64           `json:"id"`
+	Actor        string          `json:"actor"`
+	ClientIp     string          `json:"client_ip"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	BeforeState  json.RawMessage `json:"before_state"`
+	AfterState   json.RawMessage `json:"after_state"`
+	// hash of the previous event in the chain
+	PrevHash string `json:"prev_hash"`
+	// sha256 over prev_hash and the event content
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
 	// can be negative or positive
-	Amount    int64     `json:"amount"`
+	Amount      int64         `json:"amount"`
+	CreatedAt   time.Time     `json:"created_at"`
+	Description string        `json:"description"`
+	Category    string        `json:"category"`
+	TransferID  sql.NullInt64 `json:"transfer_id"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	Balance     int64     `json:"balance"`
+	RateBps     int32     `json:"rate_bps"`
+	// accrued interest in millionths of the minor unit
+	AmountMicros int64     `json:"amount_micros"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type InterestPosting struct {
+	ID        int64     `json:"id"`
+	AccountID int64     `json:"account_id"`
+	PeriodEnd time.Time `json:"period_end"`
+	// posted interest in minor units
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int32           `json:"attempts"`
+	LastError     string          `json:"last_error"`
+	// empty until delivered to every sink
+	PublishedAt sql.NullTime `json:"published_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	// set when the relay gave up, clear it to publish the event again
+	FailedAt sql.NullTime `json:"failed_at"`
+}
+
+type Payee struct {
+	ID        int64     `json:"id"`
+	Owner     string    `json:"owner"`
+	AccountID int64     `json:"account_id"`
+	Nickname  string    `json:"nickname"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type PaymentRequest struct {
+	ID                 int64  `json:"id"`
+	RequesterAccountID int64  `json:"requester_account_id"`
+	Requester          string `json:"requester"`
+	Payer              string `json:"payer"`
+	Amount             int64  `json:"amount"`
+	Currency           string `json:"currency"`
+	Memo               string `json:"memo"`
+	// pending, paid or declined
+	Status string `json:"status"`
+	// transfer paying the request, it may still be pending review
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type RateLimitBucket struct {
+	Key       string    `json:"key"`
+	Tokens    float64   `json:"tokens"`
+	Allowed   bool      `json:"allowed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type RiskAssessment struct {
+	ID int64 `json:"id"`
+	// empty for denied transfers
+	TransferID    sql.NullInt64 `json:"transfer_id"`
+	Username      string        `json:"username"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	ClientIp      string        `json:"client_ip"`
+	UserAgent     string        `json:"user_agent"`
+	// allow, review or deny
+	Outcome    string    `json:"outcome"`
+	Reason     string    `json:"reason"`
+	ReviewedBy string    `json:"reviewed_by"`
+	ReviewedAt time.Time `json:"reviewed_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
 type Session struct {
 	ID           uuid.UUID `json:"id"`
 	Username     string    `json:"username"`
@@ -37,13 +166,31 @@ type Session struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+type TierLimit struct {
+	Tier     string `json:"tier"`
+	Currency string `json:"currency"`
+	// 0 means unlimited
+	PerTransaction int64 `json:"per_transaction"`
+	// outgoing total of all accounts of the user in the currency, 0 means unlimited
+	Daily int64 `json:"daily"`
+	// outgoing total of all accounts of the user in the currency, 0 means unlimited
+	Monthly int64 `json:"monthly"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	// must be positive
-	Amount    int64     `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	Amount          int64     `json:"amount"`
+	CreatedAt       time.Time `json:"created_at"`
+	Description     string    `json:"description"`
+	Category        string    `json:"category"`
+	ClientReference string    `json:"client_reference"`
+	// charged to the sender on top of the amount
+	Fee int64 `json:"fee"`
+	// completed, pending_review or rejected
+	Status string `json:"status"`
 }
 
 type User struct {
@@ -53,4 +200,38 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Tier              string    `json:"tier"`
+	Role              string    `json:"role"`
+	// only allow transfers to saved payees and own accounts
+	RequireSavedPayee bool `json:"require_saved_payee"`
+}
+
+type WebhookDelivery struct {
+	ID             int64 `json:"id"`
+	SubscriptionID int64 `json:"subscription_id"`
+	// outbox event the delivery was created for
+	EventID   int64           `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	// pending, succeeded or failed
+	Status         string       `json:"status"`
+	Attempts       int32        `json:"attempts"`
+	NextAttemptAt  time.Time    `json:"next_attempt_at"`
+	LastStatusCode int32        `json:"last_status_code"`
+	LastError      string       `json:"last_error"`
+	DeliveredAt    sql.NullTime `json:"delivered_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Url   string `json:"url"`
+	// key of the HMAC-SHA256 payload signatures
+	Secret string `json:"secret"`
+	// transfer.received, transfer.sent or balance.low
+	EventTypes []string `json:"event_types"`
+	// balance.low is sent when a transfer leaves less than this
+	LowBalanceThreshold int64     `json:"low_balance_threshold"`
+	CreatedAt           time.Time `json:"created_at"`
 }
@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Aggregate types of outbox events, events of the same aggregate are published in order.
+const (
+	OutboxAggregateTransfer = "transfer"
+	OutboxAggregateAccount  = "account"
+	OutboxAggregateUser     = "user"
+)
+
+// Types of outbox events.
+const (
+	EventTransferCompleted = "transfer.completed"
+	EventTransferHeld      = "transfer.held"
+	EventTransferApproved  = "transfer.approved"
+	EventTransferRejected  = "transfer.rejected"
+	EventAccountCreated    = "account.created"
+	EventAccountUpdated    = "account.updated"
+	EventAccountClosed     = "account.closed"
+	EventUserCreated       = "user.created"
+)
+
+// TransferEvent is the payload of the events of transfers which moved money.
+type TransferEvent struct {
+	Transfer
+	// FromAccountBalance is the balance of the sender right after the transfer, including the fee.
+	FromAccountBalance int64 `json:"from_account_balance"`
+}
+
+func newTransferEvent(result TransferTxResult) TransferEvent {
+	return TransferEvent{
+		Transfer:           result.Transfer,
+		FromAccountBalance: result.FromAccount.Balance,
+	}
+}
+
+// outboxLockKey makes sure that only one relay publishes events at a time.
+const outboxLockKey = 0x6f7574626f7801
+
+// outboxMarkTimeout bounds recording the outcomes of published events,
+// which still happens when the relay is stopped, so that the events are not published again.
+const outboxMarkTimeout = 5 * time.Second
+
+// PublishOutboxParams contains the input parameters of publishing outbox events.
+type PublishOutboxParams struct {
+	Limit int32
+	// Publish delivers an event. The event stays unpublished and is retried when it returns an error.
+	Publish func(ctx context.Context, event OutboxEvent) error
+	// MaxAttempts is the number of attempts after which a failing event is set aside, zero retries it forever.
+	MaxAttempts int32
+}
+
+// PublishOutboxResult is the result of publishing outbox events.
+type PublishOutboxResult struct {
+	Published int `json:"published"`
+	Failed    int `json:"failed"`
+	// SetAside is the number of failed events which reached the maximum attempts and are not retried.
+	SetAside int `json:"set_aside"`
+	// Locked is false when another relay is publishing events.
+	Locked bool `json:"locked"`
+}
+
+// addOutboxEvent records an event to be published by the relay.
+// It must be called within the same transaction as the change it describes.
+func addOutboxEvent(
+	ctx context.Context,
+	q *Queries,
+	aggregateType, aggregateID, eventType string,
+	payload interface{},
+) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("cannot marshal outbox event payload: %w", err)
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       data,
+	})
+
+	return err
+}
+
+// PublishOutbox publishes the oldest unpublished events in order.
+// The events are published without holding a database transaction open: they are read first,
+// and the outcomes are recorded afterwards within a single short transaction. A session lock
+// on a dedicated connection keeps other relays from publishing at the same time.
+// Once an event of an aggregate fails, the later events of the same aggregate are held back
+// until the next run, so that every aggregate is published in order and at least once.
+// An event which keeps failing is set aside after the maximum attempts, so that it no longer
+// holds back its aggregate, and is left in the table to be inspected.
+func (s *SQLStore) PublishOutbox(ctx context.Context, arg PublishOutboxParams) (PublishOutboxResult, error) {
+	var result PublishOutboxResult
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+
+	session := New(traceDB(conn))
+
+	locked, err := session.TryLockOutbox(ctx, outboxLockKey)
+	if err != nil || !locked {
+		return result, err
+	}
+
+	result.Locked = true
+
+	defer releaseOutboxLock(conn, session)
+
+	events, err := session.ListUnpublishedOutboxEvents(ctx, arg.Limit)
+	if err != nil {
+		return result, err
+	}
+
+	failed := make(map[string]bool)
+	failures := make(map[int64]string)
+	published := make([]int64, 0, len(events))
+
+	for _, event := range events {
+		if ctx.Err() != nil {
+			break
+		}
+
+		aggregate := event.AggregateType + "/" + event.AggregateID
+		if failed[aggregate] {
+			continue
+		}
+
+		if err := arg.Publish(ctx, event); err != nil {
+			failed[aggregate] = true
+			failures[event.ID] = err.Error()
+
+			continue
+		}
+
+		published = append(published, event.ID)
+	}
+
+	markCtx, cancel := context.WithTimeout(context.Background(), outboxMarkTimeout)
+	defer cancel()
+
+	err = s.execTx(markCtx, func(q *Queries) error {
+		for _, id := range published {
+			if err := q.MarkOutboxEventPublished(markCtx, id); err != nil {
+				return err
+			}
+		}
+
+		for id, lastError := range failures {
+			event, err := q.RecordOutboxEventFailure(markCtx, RecordOutboxEventFailureParams{
+				ID:          id,
+				LastError:   lastError,
+				MaxAttempts: arg.MaxAttempts,
+			})
+			if err != nil {
+				return err
+			}
+
+			if event.FailedAt.Valid {
+				result.SetAside++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Published = len(published)
+	result.Failed = len(failures)
+
+	return result, nil
+}
+
+// releaseOutboxLock releases the session lock of the relay. The connection is discarded instead
+// when the lock cannot be released, since it would otherwise keep the lock in the pool.
+func releaseOutboxLock(conn *sql.Conn, session *Queries) {
+	ctx, cancel := context.WithTimeout(context.Background(), outboxMarkTimeout)
+	defer cancel()
+
+	if unlocked, err := session.UnlockOutbox(ctx, outboxLockKey); err == nil && unlocked {
+		return
+	}
+
+	_ = conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishOutbox(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Currency: util.USD,
+			Type:     util.Checking,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = store.UpdateAccountTx(context.Background(), UpdateAccountTxParams{
+		UpdateAccountParams: UpdateAccountParams{ID: account.ID},
+	})
+	require.NoError(t, err)
+
+	accountID := strconv.FormatInt(account.ID, 10)
+	isAccountEvent := func(event OutboxEvent) bool {
+		return event.AggregateType == OutboxAggregateAccount && event.AggregateID == accountID
+	}
+
+	// a failed event holds back the later events of its aggregate
+	var attempted []string
+
+	_, err = store.PublishOutbox(context.Background(), PublishOutboxParams{
+		Limit: 10000,
+		Publish: func(_ context.Context, event OutboxEvent) error {
+			if isAccountEvent(event) {
+				attempted = append(attempted, event.EventType)
+
+				return errors.New("sink unavailable")
+			}
+
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{EventAccountCreated}, attempted)
+
+	var published []string
+
+	result, err := store.PublishOutbox(context.Background(), PublishOutboxParams{
+		Limit: 10000,
+		Publish: func(_ context.Context, event OutboxEvent) error {
+			if isAccountEvent(event) {
+				published = append(published, event.EventType)
+			}
+
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, result.Locked)
+	require.Equal(t, []string{EventAccountCreated, EventAccountUpdated}, published)
+}
+
+func TestPublishOutboxSetsAsidePoisonEvent(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Currency: util.USD,
+			Type:     util.Checking,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = store.UpdateAccountTx(context.Background(), UpdateAccountTxParams{
+		UpdateAccountParams: UpdateAccountParams{ID: account.ID},
+	})
+	require.NoError(t, err)
+
+	accountID := strconv.FormatInt(account.ID, 10)
+
+	// the sinks can never handle the created event, which holds back the updated event until it is set aside
+	var published []string
+
+	publish := func(_ context.Context, event OutboxEvent) error {
+		if event.AggregateType != OutboxAggregateAccount || event.AggregateID != accountID {
+			return nil
+		}
+
+		if event.EventType == EventAccountCreated {
+			return errors.New("malformed event")
+		}
+
+		published = append(published, event.EventType)
+
+		return nil
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = store.PublishOutbox(context.Background(), PublishOutboxParams{
+			Limit:       10000,
+			Publish:     publish,
+			MaxAttempts: 2,
+		})
+		require.NoError(t, err)
+		require.Empty(t, published)
+	}
+
+	_, err = store.PublishOutbox(context.Background(), PublishOutboxParams{
+		Limit:       10000,
+		Publish:     publish,
+		MaxAttempts: 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{EventAccountUpdated}, published)
+
+	events, err := testQueries.ListUnpublishedOutboxEvents(context.Background(), 10000)
+	require.NoError(t, err)
+
+	for _, event := range events {
+		require.False(t, event.AggregateType == OutboxAggregateAccount && event.AggregateID == accountID)
+	}
+}
+
+func TestPublishOutboxSingleRelay(t *testing.T) {
+	store := NewStore(testDB)
+	createRandomUser(t)
+
+	var concurrent PublishOutboxResult
+
+	result, err := store.PublishOutbox(context.Background(), PublishOutboxParams{
+		Limit: 1,
+		Publish: func(ctx context.Context, _ OutboxEvent) error {
+			// another relay cannot publish while the events are published
+			var err error
+			concurrent, err = store.PublishOutbox(ctx, PublishOutboxParams{
+				Limit:   1,
+				Publish: func(context.Context, OutboxEvent) error { return nil },
+			})
+
+			return err
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, result.Locked)
+	require.Equal(t, 1, result.Published)
+	require.False(t, concurrent.Locked)
+
+	// the lock is released afterwards
+	result, err = store.PublishOutbox(context.Background(), PublishOutboxParams{
+		Limit:   1,
+		Publish: func(context.Context, OutboxEvent) error { return nil },
+	})
+	require.NoError(t, err)
+	require.True(t, result.Locked)
+}
@@ -0,0 +1,167 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: payee.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createPayee = `-- name: CreatePayee :one
+INSERT INTO payees (
+    owner,
+    account_id,
+    nickname
+) VALUES (
+    $1, $2, $3
+) RETURNING id, owner, account_id, nickname, created_at
+`
+
+type CreatePayeeParams struct {
+	Owner     string `json:"owner"`
+	AccountID int64  `json:"account_id"`
+	Nickname  string `json:"nickname"`
+}
+
+func (q *Queries) CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error) {
+	row := q.db.QueryRowContext(ctx, createPayee, arg.Owner, arg.AccountID, arg.Nickname)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Nickname,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deletePayee = `-- name: DeletePayee :exec
+DELETE FROM payees
+WHERE id = $1 AND owner = $2
+`
+
+type DeletePayeeParams struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+}
+
+func (q *Queries) DeletePayee(ctx context.Context, arg DeletePayeeParams) error {
+	_, err := q.db.ExecContext(ctx, deletePayee, arg.ID, arg.Owner)
+	return err
+}
+
+const getPayee = `-- name: GetPayee :one
+SELECT p.id, p.owner, p.account_id, p.nickname, p.created_at, a.owner AS payee_username, a.currency
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+WHERE p.id = $1 LIMIT 1
+`
+
+type GetPayeeRow struct {
+	ID            int64     `json:"id"`
+	Owner         string    `json:"owner"`
+	AccountID     int64     `json:"account_id"`
+	Nickname      string    `json:"nickname"`
+	CreatedAt     time.Time `json:"created_at"`
+	PayeeUsername string    `json:"payee_username"`
+	Currency      string    `json:"currency"`
+}
+
+func (q *Queries) GetPayee(ctx context.Context, id int64) (GetPayeeRow, error) {
+	row := q.db.QueryRowContext(ctx, getPayee, id)
+	var i GetPayeeRow
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Nickname,
+		&i.CreatedAt,
+		&i.PayeeUsername,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const getPayeeByAccount = `-- name: GetPayeeByAccount :one
+SELECT id, owner, account_id, nickname, created_at FROM payees
+WHERE owner = $1 AND account_id = $2 LIMIT 1
+`
+
+type GetPayeeByAccountParams struct {
+	Owner     string `json:"owner"`
+	AccountID int64  `json:"account_id"`
+}
+
+func (q *Queries) GetPayeeByAccount(ctx context.Context, arg GetPayeeByAccountParams) (Payee, error) {
+	row := q.db.QueryRowContext(ctx, getPayeeByAccount, arg.Owner, arg.AccountID)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.AccountID,
+		&i.Nickname,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listPayees = `-- name: ListPayees :many
+SELECT p.id, p.owner, p.account_id, p.nickname, p.created_at, a.owner AS payee_username, a.currency
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+WHERE p.owner = $1
+ORDER BY p.nickname
+LIMIT $2
+OFFSET $3
+`
+
+type ListPayeesParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+type ListPayeesRow struct {
+	ID            int64     `json:"id"`
+	Owner         string    `json:"owner"`
+	AccountID     int64     `json:"account_id"`
+	Nickname      string    `json:"nickname"`
+	CreatedAt     time.Time `json:"created_at"`
+	PayeeUsername string    `json:"payee_username"`
+	Currency      string    `json:"currency"`
+}
+
+func (q *Queries) ListPayees(ctx context.Context, arg ListPayeesParams) ([]ListPayeesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPayees, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPayeesRow{}
+	for rows.Next() {
+		var i ListPayeesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.AccountID,
+			&i.Nickname,
+			&i.CreatedAt,
+			&i.PayeeUsername,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
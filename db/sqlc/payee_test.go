@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPayee(t *testing.T) {
+	payee1 := createRandomPayee(t)
+
+	payee2, err := testQueries.GetPayee(context.Background(), payee1.ID)
+	require.NoError(t, err)
+
+	account, err := testQueries.GetAccount(context.Background(), payee1.AccountID)
+	require.NoError(t, err)
+
+	require.Equal(t, payee1.ID, payee2.ID)
+	require.Equal(t, payee1.Owner, payee2.Owner)
+	require.Equal(t, payee1.AccountID, payee2.AccountID)
+	require.Equal(t, payee1.Nickname, payee2.Nickname)
+	require.Equal(t, account.Owner, payee2.PayeeUsername)
+	require.Equal(t, account.Currency, payee2.Currency)
+}
+
+func TestCreatePayeeDuplicate(t *testing.T) {
+	payee := createRandomPayee(t)
+
+	_, err := testQueries.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner:     payee.Owner,
+		AccountID: payee.AccountID,
+		Nickname:  util.RandomOwner(),
+	})
+	require.Error(t, err)
+}
+
+func TestDeletePayee(t *testing.T) {
+	payee := createRandomPayee(t)
+
+	err := testQueries.DeletePayee(context.Background(), DeletePayeeParams{ID: payee.ID, Owner: payee.Owner})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetPayee(context.Background(), payee.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func createRandomPayee(t *testing.T) Payee {
+	user := createRandomUser(t)
+	account := createRandomAccount(t)
+
+	arg := CreatePayeeParams{
+		Owner:     user.Username,
+		AccountID: account.ID,
+		Nickname:  util.RandomOwner(),
+	}
+
+	payee, err := testQueries.CreatePayee(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.NotZero(t, payee.ID)
+	require.Equal(t, arg.Owner, payee.Owner)
+	require.Equal(t, arg.AccountID, payee.AccountID)
+	require.Equal(t, arg.Nickname, payee.Nickname)
+
+	return payee
+}
@@ -0,0 +1,308 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: payment_request.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createPaymentRequest = `-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (
+    requester_account_id,
+    requester,
+    payer,
+    amount,
+    currency,
+    memo,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, requester_account_id, requester, payer, amount, currency, memo, status, transfer_id, expires_at, created_at
+`
+
+type CreatePaymentRequestParams struct {
+	RequesterAccountID int64     `json:"requester_account_id"`
+	Requester          string    `json:"requester"`
+	Payer              string    `json:"payer"`
+	Amount             int64     `json:"amount"`
+	Currency           string    `json:"currency"`
+	Memo               string    `json:"memo"`
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error) {
+	row := q.db.QueryRowContext(ctx, createPaymentRequest,
+		arg.RequesterAccountID,
+		arg.Requester,
+		arg.Payer,
+		arg.Amount,
+		arg.Currency,
+		arg.Memo,
+		arg.ExpiresAt,
+	)
+	var i PaymentRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterAccountID,
+		&i.Requester,
+		&i.Payer,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const declinePaymentRequest = `-- name: DeclinePaymentRequest :one
+UPDATE payment_requests
+SET status = 'declined'
+WHERE id = $1 AND status = 'pending' AND transfer_id IS NULL
+RETURNING id, requester_account_id, requester, payer, amount, currency, memo, status, transfer_id, expires_at, created_at
+`
+
+func (q *Queries) DeclinePaymentRequest(ctx context.Context, id int64) (PaymentRequest, error) {
+	row := q.db.QueryRowContext(ctx, declinePaymentRequest, id)
+	var i PaymentRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterAccountID,
+		&i.Requester,
+		&i.Payer,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPaymentRequest = `-- name: GetPaymentRequest :one
+SELECT id, requester_account_id, requester, payer, amount, currency, memo, status, transfer_id, expires_at, created_at FROM payment_requests
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentRequest, id)
+	var i PaymentRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterAccountID,
+		&i.Requester,
+		&i.Payer,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPaymentRequestForUpdate = `-- name: GetPaymentRequestForUpdate :one
+SELECT id, requester_account_id, requester, payer, amount, currency, memo, status, transfer_id, expires_at, created_at FROM payment_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error) {
+	row := q.db.QueryRowContext(ctx, getPaymentRequestForUpdate, id)
+	var i PaymentRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterAccountID,
+		&i.Requester,
+		&i.Payer,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const linkPaymentRequestTransfer = `-- name: LinkPaymentRequestTransfer :one
+UPDATE payment_requests
+SET transfer_id = $1, status = $2
+WHERE id = $3
+RETURNING id, requester_account_id, requester, payer, amount, currency, memo, status, transfer_id, expires_at, created_at
+`
+
+type LinkPaymentRequestTransferParams struct {
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	Status     string        `json:"status"`
+	ID         int64         `json:"id"`
+}
+
+func (q *Queries) LinkPaymentRequestTransfer(ctx context.Context, arg LinkPaymentRequestTransferParams) (PaymentRequest, error) {
+	row := q.db.QueryRowContext(ctx, linkPaymentRequestTransfer, arg.TransferID, arg.Status, arg.ID)
+	var i PaymentRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterAccountID,
+		&i.Requester,
+		&i.Payer,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.TransferID,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listIncomingPaymentRequests = `-- name: ListIncomingPaymentRequests :many
+SELECT pr.id, pr.requester_account_id, pr.requester, pr.payer, pr.amount, pr.currency, pr.memo, pr.status, pr.transfer_id, pr.expires_at, pr.created_at, a.number AS requester_account_number
+FROM payment_requests pr
+JOIN accounts a ON a.id = pr.requester_account_id
+WHERE pr.payer = $1
+ORDER BY pr.id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListIncomingPaymentRequestsParams struct {
+	Payer  string `json:"payer"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+type ListIncomingPaymentRequestsRow struct {
+	ID                     int64         `json:"id"`
+	RequesterAccountID     int64         `json:"requester_account_id"`
+	Requester              string        `json:"requester"`
+	Payer                  string        `json:"payer"`
+	Amount                 int64         `json:"amount"`
+	Currency               string        `json:"currency"`
+	Memo                   string        `json:"memo"`
+	Status                 string        `json:"status"`
+	TransferID             sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt              time.Time     `json:"expires_at"`
+	CreatedAt              time.Time     `json:"created_at"`
+	RequesterAccountNumber string        `json:"requester_account_number"`
+}
+
+func (q *Queries) ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]ListIncomingPaymentRequestsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listIncomingPaymentRequests, arg.Payer, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListIncomingPaymentRequestsRow{}
+	for rows.Next() {
+		var i ListIncomingPaymentRequestsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RequesterAccountID,
+			&i.Requester,
+			&i.Payer,
+			&i.Amount,
+			&i.Currency,
+			&i.Memo,
+			&i.Status,
+			&i.TransferID,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+			&i.RequesterAccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutgoingPaymentRequests = `-- name: ListOutgoingPaymentRequests :many
+SELECT id, requester_account_id, requester, payer, amount, currency, memo, status, transfer_id, expires_at, created_at FROM payment_requests
+WHERE requester = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3
+`
+
+type ListOutgoingPaymentRequestsParams struct {
+	Requester string `json:"requester"`
+	Limit     int32  `json:"limit"`
+	Offset    int32  `json:"offset"`
+}
+
+func (q *Queries) ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]PaymentRequest, error) {
+	rows, err := q.db.QueryContext(ctx, listOutgoingPaymentRequests, arg.Requester, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PaymentRequest{}
+	for rows.Next() {
+		var i PaymentRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.RequesterAccountID,
+			&i.Requester,
+			&i.Payer,
+			&i.Amount,
+			&i.Currency,
+			&i.Memo,
+			&i.Status,
+			&i.TransferID,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPaymentRequestPaid = `-- name: MarkPaymentRequestPaid :exec
+UPDATE payment_requests
+SET status = 'paid'
+WHERE transfer_id = $1
+`
+
+func (q *Queries) MarkPaymentRequestPaid(ctx context.Context, transferID sql.NullInt64) error {
+	_, err := q.db.ExecContext(ctx, markPaymentRequestPaid, transferID)
+	return err
+}
+
+const releasePaymentRequest = `-- name: ReleasePaymentRequest :exec
+UPDATE payment_requests
+SET transfer_id = NULL
+WHERE transfer_id = $1
+`
+
+func (q *Queries) ReleasePaymentRequest(ctx context.Context, transferID sql.NullInt64) error {
+	_, err := q.db.ExecContext(ctx, releasePaymentRequest, transferID)
+	return err
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Statuses of payment requests.
+const (
+	PaymentRequestStatusPending  = "pending"
+	PaymentRequestStatusPaid     = "paid"
+	PaymentRequestStatusDeclined = "declined"
+)
+
+var (
+	// ErrPaymentRequestNotPending is returned when paying or declining a payment request
+	// that is already paid, declined or being paid by a transfer held for review.
+	ErrPaymentRequestNotPending = errors.New("payment request is not pending")
+	// ErrPaymentRequestExpired is returned when paying an expired payment request.
+	ErrPaymentRequestExpired = errors.New("payment request has expired")
+)
+
+// checkPaymentRequest locks a payment request and checks that it can still be paid.
+// It must be called within a database transaction.
+func checkPaymentRequest(ctx context.Context, q *Queries, id int64, now time.Time) error {
+	request, err := q.GetPaymentRequestForUpdate(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if request.Status != PaymentRequestStatusPending || request.TransferID.Valid {
+		return ErrPaymentRequestNotPending
+	}
+
+	if !now.Before(request.ExpiresAt) {
+		return ErrPaymentRequestExpired
+	}
+
+	return nil
+}
+
+// linkPaymentRequest links a payment request to the transfer paying it.
+// The request is only paid once the transfer is completed, a transfer held for review keeps it pending.
+// It must be called within a database transaction.
+func linkPaymentRequest(ctx context.Context, q *Queries, id int64, transfer Transfer) (*PaymentRequest, error) {
+	status := PaymentRequestStatusPaid
+	if transfer.Status != TransferStatusCompleted {
+		status = PaymentRequestStatusPending
+	}
+
+	request, err := q.LinkPaymentRequestTransfer(ctx, LinkPaymentRequestTransferParams{
+		ID:         id,
+		TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+		Status:     status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
@@ -6,29 +6,118 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	// the claimed deliveries are leased until next_attempt_at so that other workers skip them
+	ClaimDueWebhookDeliveries(ctx context.Context, arg ClaimDueWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	CloseAccount(ctx context.Context, id int64) (Account, error)
+	CountAccountsByCurrency(ctx context.Context, arg CountAccountsByCurrencyParams) (int64, error)
+	CountActiveSessions(ctx context.Context) (int64, error)
+	CountKnownClientTransfers(ctx context.Context, arg CountKnownClientTransfersParams) (CountKnownClientTransfersRow, error)
+	CountTierLimits(ctx context.Context, tier string) (int64, error)
+	CountTransfersBetween(ctx context.Context, arg CountTransfersBetweenParams) (int64, error)
+	CountTransfersSince(ctx context.Context, arg CountTransfersSinceParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error)
+	CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	CreateRiskAssessment(ctx context.Context, arg CreateRiskAssessmentParams) (RiskAssessment, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
+	DeclinePaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeletePayee(ctx context.Context, arg DeletePayeeParams) error
+	DeleteRateLimitBuckets(ctx context.Context, updatedBefore time.Time) (int64, error)
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByNumber(ctx context.Context, number string) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountInterestRate(ctx context.Context, accountID int64) (AccountInterestRate, error)
+	GetAccountLimit(ctx context.Context, accountID int64) (AccountLimit, error)
+	GetAccountOutgoingTotals(ctx context.Context, arg GetAccountOutgoingTotalsParams) (GetAccountOutgoingTotalsRow, error)
+	GetDefaultAccount(ctx context.Context, arg GetDefaultAccountParams) (Account, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetInterestPosting(ctx context.Context, arg GetInterestPostingParams) (InterestPosting, error)
+	GetLastAccrualDate(ctx context.Context) (time.Time, error)
+	GetLastAuditEvent(ctx context.Context) (AuditEvent, error)
+	GetOwnerOutgoingTotals(ctx context.Context, arg GetOwnerOutgoingTotalsParams) (GetOwnerOutgoingTotalsRow, error)
+	GetPayee(ctx context.Context, id int64) (GetPayeeRow, error)
+	GetPayeeByAccount(ctx context.Context, arg GetPayeeByAccountParams) (Payee, error)
+	GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	GetRiskAssessmentByTransfer(ctx context.Context, transferID sql.NullInt64) (RiskAssessment, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error)
+	GetTierLimit(ctx context.Context, arg GetTierLimitParams) (TierLimit, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferAmountStats(ctx context.Context, fromAccountID int64) (GetTransferAmountStatsRow, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetUnpostedInterest(ctx context.Context, arg GetUnpostedInterestParams) (int64, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	LinkPaymentRequestTransfer(ctx context.Context, arg LinkPaymentRequestTransferParams) (PaymentRequest, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
-	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
-	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
-	UpdateAccounts(ctx context.Context, arg UpdateAccountsParams) (Account, error)
+	ListAccountsWithAccruals(ctx context.Context, arg ListAccountsWithAccrualsParams) ([]int64, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	// entries can be paged by id, so that no entry is skipped or repeated when entries are added meanwhile,
+	// and come with the reference and the counterparty of their transfer
+	ListEntries(ctx context.Context, arg ListEntriesParams) ([]ListEntriesRow, error)
+	ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]ListIncomingPaymentRequestsRow, error)
+	// The balance is the one at day_end: entries made since then are taken off the current balance.
+	ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error)
+	ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListPayees(ctx context.Context, arg ListPayeesParams) ([]ListPayeesRow, error)
+	// the search term must have its LIKE wildcards escaped with a backslash
+	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]ListTransfersRow, error)
+	ListTransfersForReview(ctx context.Context, arg ListTransfersForReviewParams) ([]ListTransfersForReviewRow, error)
+	ListUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]OutboxEvent, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptions(ctx context.Context, arg ListWebhookSubscriptionsParams) ([]WebhookSubscription, error)
+	ListWebhookSubscriptionsByOwners(ctx context.Context, owners []string) ([]WebhookSubscription, error)
+	LockAuditChain(ctx context.Context, lockKey int64) error
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	MarkPaymentRequestPaid(ctx context.Context, transferID sql.NullInt64) error
+	NotifyAccountChange(ctx context.Context, arg NotifyAccountChangeParams) error
+	// the event is set aside once it reaches max_attempts, zero retries it forever
+	RecordOutboxEventFailure(ctx context.Context, arg RecordOutboxEventFailureParams) (OutboxEvent, error)
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) (WebhookDelivery, error)
+	RedeliverWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	ReleasePaymentRequest(ctx context.Context, transferID sql.NullInt64) error
+	// the lease is only renewed while the worker still holds it, an expired lease may have been claimed by another worker
+	RenewWebhookDeliveryLease(ctx context.Context, arg RenewWebhookDeliveryLeaseParams) (WebhookDelivery, error)
+	ReviewRiskAssessment(ctx context.Context, arg ReviewRiskAssessmentParams) (RiskAssessment, error)
+	SearchTransfersByAmountAsc(ctx context.Context, arg SearchTransfersByAmountAscParams) ([]SearchTransfersByAmountAscRow, error)
+	SearchTransfersByAmountDesc(ctx context.Context, arg SearchTransfersByAmountDescParams) ([]SearchTransfersByAmountDescRow, error)
+	// the search queries only differ by their static order, so that postgres can use the indexes to sort
+	SearchTransfersByCreatedAtAsc(ctx context.Context, arg SearchTransfersByCreatedAtAscParams) ([]SearchTransfersByCreatedAtAscRow, error)
+	SearchTransfersByCreatedAtDesc(ctx context.Context, arg SearchTransfersByCreatedAtDescParams) ([]SearchTransfersByCreatedAtDescRow, error)
+	SetAccountInterestRate(ctx context.Context, arg SetAccountInterestRateParams) (AccountInterestRate, error)
+	SetAccountLimit(ctx context.Context, arg SetAccountLimitParams) (AccountLimit, error)
+	SetTierLimit(ctx context.Context, arg SetTierLimitParams) (TierLimit, error)
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (TakeRateLimitTokenRow, error)
+	// the lock is held by the session, so that no transaction is kept open while events are published
+	TryLockOutbox(ctx context.Context, lockKey int64) (bool, error)
+	UnlockOutbox(ctx context.Context, lockKey int64) (bool, error)
+	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,227 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: risk.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const countKnownClientTransfers = `-- name: CountKnownClientTransfers :one
+SELECT COUNT(*) AS transfers,
+       COUNT(*) FILTER (WHERE r.client_ip = $1 AND r.user_agent = $2) AS client_transfers
+FROM risk_assessments r
+JOIN transfers t ON t.id = r.transfer_id
+WHERE r.username = $3 AND t.status = 'completed'
+`
+
+type CountKnownClientTransfersParams struct {
+	ClientIp  string `json:"client_ip"`
+	UserAgent string `json:"user_agent"`
+	Username  string `json:"username"`
+}
+
+type CountKnownClientTransfersRow struct {
+	Transfers       int64 `json:"transfers"`
+	ClientTransfers int64 `json:"client_transfers"`
+}
+
+func (q *Queries) CountKnownClientTransfers(ctx context.Context, arg CountKnownClientTransfersParams) (CountKnownClientTransfersRow, error) {
+	row := q.db.QueryRowContext(ctx, countKnownClientTransfers, arg.ClientIp, arg.UserAgent, arg.Username)
+	var i CountKnownClientTransfersRow
+	err := row.Scan(&i.Transfers, &i.ClientTransfers)
+	return i, err
+}
+
+const createRiskAssessment = `-- name: CreateRiskAssessment :one
+INSERT INTO risk_assessments (
+    transfer_id,
+    username,
+    from_account_id,
+    to_account_id,
+    amount,
+    client_ip,
+    user_agent,
+    outcome,
+    reason
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, transfer_id, username, from_account_id, to_account_id, amount, client_ip, user_agent, outcome, reason, reviewed_by, reviewed_at, created_at
+`
+
+type CreateRiskAssessmentParams struct {
+	TransferID    sql.NullInt64 `json:"transfer_id"`
+	Username      string        `json:"username"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	ClientIp      string        `json:"client_ip"`
+	UserAgent     string        `json:"user_agent"`
+	Outcome       string        `json:"outcome"`
+	Reason        string        `json:"reason"`
+}
+
+func (q *Queries) CreateRiskAssessment(ctx context.Context, arg CreateRiskAssessmentParams) (RiskAssessment, error) {
+	row := q.db.QueryRowContext(ctx, createRiskAssessment,
+		arg.TransferID,
+		arg.Username,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ClientIp,
+		arg.UserAgent,
+		arg.Outcome,
+		arg.Reason,
+	)
+	var i RiskAssessment
+	err := row.Scan(
+		&i.ID,
+		&i.TransferID,
+		&i.Username,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.ClientIp,
+		&i.UserAgent,
+		&i.Outcome,
+		&i.Reason,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getRiskAssessmentByTransfer = `-- name: GetRiskAssessmentByTransfer :one
+SELECT id, transfer_id, username, from_account_id, to_account_id, amount, client_ip, user_agent, outcome, reason, reviewed_by, reviewed_at, created_at FROM risk_assessments
+WHERE transfer_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetRiskAssessmentByTransfer(ctx context.Context, transferID sql.NullInt64) (RiskAssessment, error) {
+	row := q.db.QueryRowContext(ctx, getRiskAssessmentByTransfer, transferID)
+	var i RiskAssessment
+	err := row.Scan(
+		&i.ID,
+		&i.TransferID,
+		&i.Username,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.ClientIp,
+		&i.UserAgent,
+		&i.Outcome,
+		&i.Reason,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listTransfersForReview = `-- name: ListTransfersForReview :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.description, t.category, t.client_reference, t.fee, t.status, r.username, r.client_ip, r.user_agent, r.reason
+FROM transfers t
+JOIN risk_assessments r ON r.transfer_id = t.id
+WHERE t.status = 'pending_review'
+ORDER BY t.id
+LIMIT $1
+OFFSET $2
+`
+
+type ListTransfersForReviewParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListTransfersForReviewRow struct {
+	ID              int64     `json:"id"`
+	FromAccountID   int64     `json:"from_account_id"`
+	ToAccountID     int64     `json:"to_account_id"`
+	Amount          int64     `json:"amount"`
+	CreatedAt       time.Time `json:"created_at"`
+	Description     string    `json:"description"`
+	Category        string    `json:"category"`
+	ClientReference string    `json:"client_reference"`
+	Fee             int64     `json:"fee"`
+	Status          string    `json:"status"`
+	Username        string    `json:"username"`
+	ClientIp        string    `json:"client_ip"`
+	UserAgent       string    `json:"user_agent"`
+	Reason          string    `json:"reason"`
+}
+
+func (q *Queries) ListTransfersForReview(ctx context.Context, arg ListTransfersForReviewParams) ([]ListTransfersForReviewRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersForReview, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransfersForReviewRow{}
+	for rows.Next() {
+		var i ListTransfersForReviewRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Description,
+			&i.Category,
+			&i.ClientReference,
+			&i.Fee,
+			&i.Status,
+			&i.Username,
+			&i.ClientIp,
+			&i.UserAgent,
+			&i.Reason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reviewRiskAssessment = `-- name: ReviewRiskAssessment :one
+UPDATE risk_assessments SET
+    reviewed_by = $2,
+    reviewed_at = now()
+WHERE transfer_id = $1
+RETURNING id, transfer_id, username, from_account_id, to_account_id, amount, client_ip, user_agent, outcome, reason, reviewed_by, reviewed_at, created_at
+`
+
+type ReviewRiskAssessmentParams struct {
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ReviewedBy string        `json:"reviewed_by"`
+}
+
+func (q *Queries) ReviewRiskAssessment(ctx context.Context, arg ReviewRiskAssessmentParams) (RiskAssessment, error) {
+	row := q.db.QueryRowContext(ctx, reviewRiskAssessment, arg.TransferID, arg.ReviewedBy)
+	var i RiskAssessment
+	err := row.Scan(
+		&i.ID,
+		&i.TransferID,
+		&i.Username,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.ClientIp,
+		&i.UserAgent,
+		&i.Outcome,
+		&i.Reason,
+		&i.ReviewedBy,
+		&i.ReviewedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
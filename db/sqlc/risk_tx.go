@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/ifantsai/simple-bank-api/util"
+)
+
+// Outcomes of the risk evaluation of transfers.
+const (
+	RiskOutcomeAllow  = "allow"
+	RiskOutcomeReview = "review"
+	RiskOutcomeDeny   = "deny"
+)
+
+// ErrTransferNotPending is returned when reviewing a transfer that is not held for review.
+var ErrTransferNotPending = errors.New("transfer is not pending review")
+
+// RiskDecision is the outcome of the risk evaluation of a transfer and the context it was made in.
+type RiskDecision struct {
+	Outcome   string
+	Reason    string
+	Username  string
+	ClientIP  string
+	UserAgent string
+}
+
+// ReviewTransferTxParams contains the input parameters of the review transfer transaction.
+type ReviewTransferTxParams struct {
+	TransferID int64     `json:"transfer_id"`
+	Approve    bool      `json:"approve"`
+	Audit      AuditMeta `json:"-"`
+}
+
+// holdTransfer records a transfer held for review without moving any money.
+// It must be called within a database transaction.
+func holdTransfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	var (
+		result TransferTxResult
+		err    error
+	)
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID:   arg.FromAccountID,
+		ToAccountID:     arg.ToAccountID,
+		Amount:          arg.Amount,
+		Description:     arg.Description,
+		Category:        arg.Category,
+		ClientReference: arg.ClientReference,
+		Fee:             arg.Fee,
+		Status:          TransferStatusPendingReview,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.RiskAssessment, err = recordRiskDecision(ctx, q, arg, result.Transfer.ID)
+	if err != nil {
+		return result, err
+	}
+
+	transferID := strconv.FormatInt(result.Transfer.ID, 10)
+
+	_, err = appendAuditEvent(ctx, q, arg.Audit,
+		AuditActionHoldTransfer, AuditResourceTransfer, transferID,
+		nil, result,
+	)
+	if err != nil {
+		return result, err
+	}
+
+	err = addOutboxEvent(ctx, q, OutboxAggregateTransfer, transferID, EventTransferHeld, result.Transfer)
+
+	return result, err
+}
+
+// recordRiskDecision persists the risk decision of a transfer.
+// It must be called within a database transaction.
+func recordRiskDecision(ctx context.Context, q *Queries, arg TransferTxParams, transferID int64) (*RiskAssessment, error) {
+	assessment, err := q.CreateRiskAssessment(ctx, CreateRiskAssessmentParams{
+		TransferID:    sql.NullInt64{Int64: transferID, Valid: true},
+		Username:      arg.Risk.Username,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ClientIp:      arg.Risk.ClientIP,
+		UserAgent:     arg.Risk.UserAgent,
+		Outcome:       arg.Risk.Outcome,
+		Reason:        arg.Risk.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &assessment, nil
+}
+
+// ReviewTransferTx approves or rejects a transfer held for review within a single database transaction.
+// An approved transfer is executed after checking the transfer limits of the sender again.
+func (s *SQLStore) ReviewTransferTx(ctx context.Context, arg ReviewTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		pending, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if pending.Status != TransferStatusPendingReview {
+			return ErrTransferNotPending
+		}
+
+		action, event, status := AuditActionRejectTransfer, EventTransferRejected, TransferStatusRejected
+
+		if arg.Approve {
+			action, event, status = AuditActionApproveTransfer, EventTransferApproved, TransferStatusCompleted
+
+			if err := postPendingTransfer(ctx, q, pending, arg.Audit, &result); err != nil {
+				return err
+			}
+		}
+
+		result.Transfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+			ID:     pending.ID,
+			Status: status,
+		})
+		if err != nil {
+			return err
+		}
+
+		// a payment request paid by the transfer is paid once approved and can be paid again once rejected
+		if arg.Approve {
+			err = q.MarkPaymentRequestPaid(ctx, sql.NullInt64{Int64: pending.ID, Valid: true})
+		} else {
+			err = q.ReleasePaymentRequest(ctx, sql.NullInt64{Int64: pending.ID, Valid: true})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		assessment, err := q.ReviewRiskAssessment(ctx, ReviewRiskAssessmentParams{
+			TransferID: sql.NullInt64{Int64: pending.ID, Valid: true},
+			ReviewedBy: arg.Audit.Actor,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.RiskAssessment = &assessment
+
+		transferID := strconv.FormatInt(pending.ID, 10)
+
+		_, err = appendAuditEvent(ctx, q, arg.Audit,
+			action, AuditResourceTransfer, transferID,
+			pending, result,
+		)
+		if err != nil {
+			return err
+		}
+
+		if arg.Approve {
+			return addOutboxEvent(ctx, q, OutboxAggregateTransfer, transferID, event, newTransferEvent(result))
+		}
+
+		return addOutboxEvent(ctx, q, OutboxAggregateTransfer, transferID, event, result.Transfer)
+	})
+
+	return result, err
+}
+
+// postPendingTransfer moves the money of an approved transfer.
+// It must be called within a database transaction.
+func postPendingTransfer(ctx context.Context, q *Queries, pending Transfer, audit AuditMeta, result *TransferTxResult) error {
+	arg := TransferTxParams{
+		FromAccountID:   pending.FromAccountID,
+		ToAccountID:     pending.ToAccountID,
+		Amount:          pending.Amount,
+		Description:     pending.Description,
+		Category:        pending.Category,
+		ClientReference: pending.ClientReference,
+		Fee:             pending.Fee,
+		Audit:           audit,
+	}
+
+	if err := checkTransferLimits(ctx, q, arg, time.Now()); err != nil {
+		return err
+	}
+
+	if arg.Fee > 0 {
+		fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		feeAccount, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+			Owner:    util.BankUsername,
+			Currency: fromAccount.Currency,
+			Type:     util.FeeRevenue,
+		})
+		if err != nil {
+			return err
+		}
+
+		arg.FeeAccountID = feeAccount.ID
+	}
+
+	return postTransfer(ctx, q, pending.ID, arg, result)
+}
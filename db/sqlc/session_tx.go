@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// RevokeSessionTxParams contains the input parameters of the revoke session transaction.
+type RevokeSessionTxParams struct {
+	ID    uuid.UUID `json:"id"`
+	Audit AuditMeta `json:"-"`
+}
+
+// auditSession is the state of a session recorded in the audit log, without the refresh token.
+type auditSession struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	UserAgent string    `json:"user_agent"`
+	ClientIP  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+}
+
+func newAuditSession(session Session) auditSession {
+	return auditSession{
+		ID:        session.ID,
+		Username:  session.Username,
+		UserAgent: session.UserAgent,
+		ClientIP:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+	}
+}
+
+// RevokeSessionTx blocks a session and records it in the audit log within a single database transaction.
+func (s *SQLStore) RevokeSessionTx(ctx context.Context, arg RevokeSessionTxParams) (Session, error) {
+	var session Session
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetSession(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		session, err = q.BlockSession(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = appendAuditEvent(ctx, q, arg.Audit,
+			AuditActionRevokeSession, AuditResourceSession, session.ID.String(),
+			newAuditSession(before), newAuditSession(session),
+		)
+
+		return err
+	})
+
+	return session, err
+}
@@ -4,12 +4,32 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"strconv"
+	"time"
 )
 
+// feeCategory is the entry category of transfer fees.
+const feeCategory = "fee"
+
 // Store provides all functions to execute db queries and transactions.
 type Store interface {
 	Querier
+	SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]TransferWithAccounts, error)
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error)
+	UpdateAccountTx(ctx context.Context, arg UpdateAccountTxParams) (Account, error)
+	CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (Account, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (User, error)
+	RevokeSessionTx(ctx context.Context, arg RevokeSessionTxParams) (Session, error)
+	PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error)
+	SetAccountInterestRateTx(ctx context.Context, arg SetAccountInterestRateTxParams) (AccountInterestRate, error)
+	SetAccountLimitTx(ctx context.Context, arg SetAccountLimitTxParams) (AccountLimit, error)
+	SetTierLimitTx(ctx context.Context, arg SetTierLimitTxParams) (TierLimit, error)
+	SetUserTierTx(ctx context.Context, arg SetUserTierTxParams) (User, error)
+	ReviewTransferTx(ctx context.Context, arg ReviewTransferTxParams) (TransferTxResult, error)
+	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (User, error)
+	PublishOutbox(ctx context.Context, arg PublishOutboxParams) (PublishOutboxResult, error)
 }
 
 // SQLStore provides all functions to execute SQL queries and transactions.
@@ -20,16 +40,34 @@ type SQLStore struct {
 
 func NewStore(db *sql.DB) Store {
 	return &SQLStore{
-		Queries: New(db),
+		Queries: New(traceDB(db)),
 		db:      db,
 	}
 }
 
+// Statuses of transfers.
+const (
+	TransferStatusCompleted     = "completed"
+	TransferStatusPendingReview = "pending_review"
+	TransferStatusRejected      = "rejected"
+)
+
 // TransferTxParams contains the input parameters of the transfer transaction.
 type TransferTxParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID   int64  `json:"from_account_id"`
+	ToAccountID     int64  `json:"to_account_id"`
+	Amount          int64  `json:"amount"`
+	Description     string `json:"description"`
+	Category        string `json:"category"`
+	ClientReference string `json:"client_reference"`
+	// Fee is charged to the sender on top of the amount and credited to the fee account.
+	Fee          int64     `json:"fee"`
+	FeeAccountID int64     `json:"fee_account_id"`
+	Audit        AuditMeta `json:"-"`
+	// Risk is recorded with the transfer. Transfers held for review are not executed until approved.
+	Risk *RiskDecision `json:"-"`
+	// PaymentRequestID is the payment request paid by the transfer, if any.
+	PaymentRequestID int64 `json:"payment_request_id"`
 }
 
 // TransferTxResult is the result of the transfer transaction.
@@ -39,53 +77,187 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// FeeEntry and FeeRevenueEntry are empty when no fee is charged.
+	FeeEntry        Entry `json:"fee_entry"`
+	FeeRevenueEntry Entry `json:"fee_revenue_entry"`
+	// RiskAssessment is set when the transfer was evaluated for risk.
+	RiskAssessment *RiskAssessment `json:"risk_assessment,omitempty"`
+	// PaymentRequest is set when the transfer pays a payment request.
+	PaymentRequest *PaymentRequest `json:"payment_request,omitempty"`
 }
 
 // TransferTx performs a money transfer from one account to the other.
-// It creates a transfer record, add acount entries, and update accounts balance within s single database transaction.
+// It checks the transfer limits of the sender, creates a transfer record, add acount entries,
+// and update accounts balance within s single database transaction.
+// A transfer held for review is only recorded, its money is moved when a banker approves it.
+// A transfer paying a payment request is linked to the request.
 func (s *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := s.execTx(ctx, func(q *Queries) error {
+		now := time.Now()
+
+		if err := checkTransferLimits(ctx, q, arg, now); err != nil {
+			return err
+		}
+
+		if arg.PaymentRequestID != 0 {
+			if err := checkPaymentRequest(ctx, q, arg.PaymentRequestID, now); err != nil {
+				return err
+			}
+		}
+
 		var err error
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+		if arg.Risk != nil && arg.Risk.Outcome == RiskOutcomeReview {
+			result, err = holdTransfer(ctx, q, arg)
+		} else {
+			result, err = transfer(ctx, q, arg)
+			if err == nil && arg.Risk != nil {
+				result.RiskAssessment, err = recordRiskDecision(ctx, q, arg, result.Transfer.ID)
+			}
+		}
+
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+		if arg.PaymentRequestID != 0 {
+			result.PaymentRequest, err = linkPaymentRequest(ctx, q, arg.PaymentRequestID, result.Transfer)
+		}
+
+		return err
+	})
+
+	return result, err
+}
+
+// transfer creates a transfer record, adds account entries and updates accounts balance.
+// It must be called within a database transaction.
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	var (
+		result TransferTxResult
+		err    error
+	)
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID:   arg.FromAccountID,
+		ToAccountID:     arg.ToAccountID,
+		Amount:          arg.Amount,
+		Description:     arg.Description,
+		Category:        arg.Category,
+		ClientReference: arg.ClientReference,
+		Fee:             arg.Fee,
+		Status:          TransferStatusCompleted,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if err := postTransfer(ctx, q, result.Transfer.ID, arg, &result); err != nil {
+		return result, err
+	}
+
+	transferID := strconv.FormatInt(result.Transfer.ID, 10)
+
+	_, err = appendAuditEvent(ctx, q, arg.Audit,
+		AuditActionTransfer, AuditResourceTransfer, transferID,
+		accountsBeforeTransfer(arg, result),
+		result,
+	)
+	if err != nil {
+		return result, err
+	}
+
+	err = addOutboxEvent(ctx, q, OutboxAggregateTransfer, transferID, EventTransferCompleted, newTransferEvent(result))
+
+	return result, err
+}
+
+// postTransfer adds the account entries of a transfer, updates accounts balance
+// and notifies the watchers of the accounts.
+// It must be called within a database transaction.
+func postTransfer(ctx context.Context, q *Queries, transferID int64, arg TransferTxParams, result *TransferTxResult) error {
+	var err error
+
+	entryTransferID := sql.NullInt64{Int64: transferID, Valid: true}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:   arg.FromAccountID,
+		Amount:      -arg.Amount,
+		Description: arg.Description,
+		Category:    arg.Category,
+		TransferID:  entryTransferID,
+	})
+	if err != nil {
+		return err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:   arg.ToAccountID,
+		Amount:      arg.Amount,
+		Description: arg.Description,
+		Category:    arg.Category,
+		TransferID:  entryTransferID,
+	})
+	if err != nil {
+		return err
+	}
+
+	balanceChanges := map[int64]int64{
+		arg.FromAccountID: -arg.Amount,
+		arg.ToAccountID:   arg.Amount,
+	}
+
+	if arg.Fee > 0 {
+		result.FeeEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:   arg.FromAccountID,
+			Amount:      -arg.Fee,
+			Description: arg.Description,
+			Category:    feeCategory,
+			TransferID:  entryTransferID,
 		})
 		if err != nil {
 			return err
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+		result.FeeRevenueEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:   arg.FeeAccountID,
+			Amount:      arg.Fee,
+			Description: arg.Description,
+			Category:    feeCategory,
+			TransferID:  entryTransferID,
 		})
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err =
-				addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.FromAccount, result.ToAccount, err =
-				addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
-		}
+		balanceChanges[arg.FromAccountID] -= arg.Fee
+		balanceChanges[arg.FeeAccountID] += arg.Fee
+	}
 
+	accounts, err := addMoney(ctx, q, balanceChanges)
+	if err != nil {
 		return err
-	})
+	}
 
-	return result, err
+	result.FromAccount, result.ToAccount = accounts[arg.FromAccountID], accounts[arg.ToAccountID]
+
+	entries := []Entry{result.FromEntry, result.ToEntry}
+	if arg.Fee > 0 {
+		entries = append(entries, result.FeeEntry, result.FeeRevenueEntry)
+	}
+
+	return notifyAccountChanges(ctx, q, accounts, entries...)
+}
+
+// accountsBeforeTransfer returns the state of the accounts before a posted transfer for the audit log.
+func accountsBeforeTransfer(arg TransferTxParams, result TransferTxResult) map[string]Account {
+	fromAccountBefore, toAccountBefore := result.FromAccount, result.ToAccount
+	fromAccountBefore.Balance += arg.Amount + arg.Fee
+	toAccountBefore.Balance -= arg.Amount
+
+	return map[string]Account{"from_account": fromAccountBefore, "to_account": toAccountBefore}
 }
 
 // execTx executes a function within a database transaction.
@@ -95,7 +267,7 @@ func (s *SQLStore) execTx(ctx context.Context, fn func(queries *Queries) error)
 		return err
 	}
 
-	if err := fn(New(tx)); err != nil {
+	if err := fn(New(traceDB(tx))); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
 		}
@@ -106,26 +278,33 @@ func (s *SQLStore) execTx(ctx context.Context, fn func(queries *Queries) error)
 	return tx.Commit()
 }
 
-func addMoney(
-	ctx context.Context,
-	q *Queries,
-	accountID1, amount1, accountID2, amount2 int64,
-) (account1 Account, account2 Account, err error) {
-	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID1,
-		Amount: amount1,
-	})
-	if err != nil {
-		return
+// addMoney adds the amounts to the balances of the accounts, which must be open.
+// The accounts are updated in order of their IDs to avoid deadlocks between concurrent transactions.
+func addMoney(ctx context.Context, q *Queries, amounts map[int64]int64) (map[int64]Account, error) {
+	accountIDs := make([]int64, 0, len(amounts))
+	for accountID := range amounts {
+		accountIDs = append(accountIDs, accountID)
 	}
 
-	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountID2,
-		Amount: amount2,
-	})
-	if err != nil {
-		return
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+
+	accounts := make(map[int64]Account, len(amounts))
+
+	for _, accountID := range accountIDs {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     accountID,
+			Amount: amounts[accountID],
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if account.Status == AccountStatusClosed {
+			return nil, ErrAccountClosed
+		}
+
+		accounts[accountID] = account
 	}
 
-	return
+	return accounts, nil
 }
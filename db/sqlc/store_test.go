@@ -2,8 +2,11 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
+	"github.com/ifantsai/simple-bank-api/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -157,3 +160,317 @@ func TestTransferTxDeadLock(t *testing.T) {
 	require.Equal(t, account1.Balance, updateAccount1.Balance)
 	require.Equal(t, account2.Balance, updateAccount2.Balance)
 }
+
+func TestTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	feeAccount, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Owner:    util.BankUsername,
+		Currency: account1.Currency,
+		Type:     util.FeeRevenue,
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Fee:           3,
+		FeeAccountID:  feeAccount.ID,
+	}
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Fee, result.Transfer.Fee)
+	require.Equal(t, account1.Balance-arg.Amount-arg.Fee, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+arg.Amount, result.ToAccount.Balance)
+
+	require.Equal(t, account1.ID, result.FeeEntry.AccountID)
+	require.Equal(t, -arg.Fee, result.FeeEntry.Amount)
+	require.Equal(t, feeCategory, result.FeeEntry.Category)
+
+	require.Equal(t, feeAccount.ID, result.FeeRevenueEntry.AccountID)
+	require.Equal(t, arg.Fee, result.FeeRevenueEntry.Amount)
+
+	updatedFeeAccount, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, arg.Fee, updatedFeeAccount.Balance-feeAccount.Balance)
+}
+
+func TestTransferTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	tierLimit, err := testQueries.GetTierLimit(context.Background(), GetTierLimitParams{
+		Tier:     "standard",
+		Currency: account1.Currency,
+	})
+	require.NoError(t, err)
+
+	// the tier caps every single transfer
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        tierLimit.PerTransaction + 1,
+	})
+
+	var limitErr *LimitExceededError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitScopeUser, limitErr.Scope)
+	require.Equal(t, LimitPeriodTransaction, limitErr.Period)
+	require.Equal(t, tierLimit.PerTransaction, limitErr.Remaining)
+
+	_, err = testQueries.SetAccountLimit(context.Background(), SetAccountLimitParams{
+		AccountID: account1.ID,
+		Daily:     25,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        20,
+	})
+	require.NoError(t, err)
+
+	// only 5 of the daily account limit are left
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitScopeAccount, limitErr.Scope)
+	require.Equal(t, LimitPeriodDaily, limitErr.Period)
+	require.Equal(t, int64(25), limitErr.Limit)
+	require.Equal(t, int64(5), limitErr.Remaining)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+}
+
+func TestSetUserTierTx(t *testing.T) {
+	store := NewStore(testDB)
+	user1 := createRandomUser(t)
+
+	_, err := store.SetUserTierTx(context.Background(), SetUserTierTxParams{
+		UpdateUserTierParams: UpdateUserTierParams{
+			Username: user1.Username,
+			Tier:     util.RandomOwner(),
+		},
+	})
+	require.ErrorIs(t, err, ErrUnknownTier)
+
+	tierLimit, err := store.SetTierLimitTx(context.Background(), SetTierLimitTxParams{
+		SetTierLimitParams: SetTierLimitParams{
+			Tier:           util.RandomOwner(),
+			Currency:       util.USD,
+			PerTransaction: 100,
+		},
+	})
+	require.NoError(t, err)
+
+	user2, err := store.SetUserTierTx(context.Background(), SetUserTierTxParams{
+		UpdateUserTierParams: UpdateUserTierParams{
+			Username: user1.Username,
+			Tier:     tierLimit.Tier,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, tierLimit.Tier, user2.Tier)
+}
+
+func TestReviewTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	hold := func() TransferTxResult {
+		result, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+			Risk: &RiskDecision{
+				Outcome:  RiskOutcomeReview,
+				Reason:   "new payee",
+				Username: account1.Owner,
+				ClientIP: "127.0.0.1",
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, TransferStatusPendingReview, result.Transfer.Status)
+		require.Equal(t, RiskOutcomeReview, result.RiskAssessment.Outcome)
+		require.Empty(t, result.FromEntry)
+
+		return result
+	}
+
+	// held transfers move no money
+	rejected := hold()
+
+	result, err := store.ReviewTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: rejected.Transfer.ID,
+		Audit:      AuditMeta{Actor: account2.Owner},
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusRejected, result.Transfer.Status)
+	require.Equal(t, account2.Owner, result.RiskAssessment.ReviewedBy)
+
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+
+	approved := hold()
+
+	result, err = store.ReviewTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: approved.Transfer.ID,
+		Approve:    true,
+		Audit:      AuditMeta{Actor: account2.Owner},
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusCompleted, result.Transfer.Status)
+	require.Equal(t, account1.Balance-10, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+10, result.ToAccount.Balance)
+
+	// a transfer is reviewed only once
+	_, err = store.ReviewTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: approved.Transfer.ID,
+		Approve:    true,
+	})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestTransferTxPaymentRequest(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	request, err := testQueries.CreatePaymentRequest(context.Background(), CreatePaymentRequestParams{
+		RequesterAccountID: account2.ID,
+		Requester:          account2.Owner,
+		Payer:              account1.Owner,
+		Amount:             10,
+		Currency:           account2.Currency,
+		ExpiresAt:          time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	pay := func(risk *RiskDecision) (TransferTxResult, error) {
+		return store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID:    account1.ID,
+			ToAccountID:      account2.ID,
+			Amount:           request.Amount,
+			Risk:             risk,
+			PaymentRequestID: request.ID,
+		})
+	}
+
+	// a transfer held for review keeps the request pending until it is approved
+	held, err := pay(&RiskDecision{Outcome: RiskOutcomeReview, Username: account1.Owner})
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestStatusPending, held.PaymentRequest.Status)
+	require.Equal(t, held.Transfer.ID, held.PaymentRequest.TransferID.Int64)
+
+	_, err = pay(nil)
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+
+	// a rejected transfer releases the request
+	_, err = store.ReviewTransferTx(context.Background(), ReviewTransferTxParams{TransferID: held.Transfer.ID})
+	require.NoError(t, err)
+
+	result, err := pay(nil)
+	require.NoError(t, err)
+	require.Equal(t, PaymentRequestStatusPaid, result.PaymentRequest.Status)
+	require.Equal(t, result.Transfer.ID, result.PaymentRequest.TransferID.Int64)
+	require.Equal(t, account1.Balance-request.Amount, result.FromAccount.Balance)
+
+	_, err = testQueries.DeclinePaymentRequest(context.Background(), request.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// expired requests cannot be paid
+	expired, err := testQueries.CreatePaymentRequest(context.Background(), CreatePaymentRequestParams{
+		RequesterAccountID: account2.ID,
+		Requester:          account2.Owner,
+		Payer:              account1.Owner,
+		Amount:             10,
+		Currency:           account2.Currency,
+		ExpiresAt:          time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID:    account1.ID,
+		ToAccountID:      account2.ID,
+		Amount:           expired.Amount,
+		PaymentRequestID: expired.ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestExpired)
+}
+
+func TestPostInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	expenseAccount, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Owner:    util.BankUsername,
+		Currency: account.Currency,
+		Type:     util.InterestExpense,
+	})
+	require.NoError(t, err)
+
+	periodEnd := time.Date(2022, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	for _, day := range []int{30, 31} {
+		_, err := testQueries.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+			AccountID:    account.ID,
+			AccrualDate:  time.Date(2022, time.January, day, 0, 0, 0, 0, time.UTC),
+			Balance:      account.Balance,
+			RateBps:      150,
+			AmountMicros: 1_250_000,
+		})
+		require.NoError(t, err)
+	}
+
+	arg := PostInterestTxParams{
+		AccountID:        account.ID,
+		ExpenseAccountID: expenseAccount.ID,
+		PeriodEnd:        periodEnd,
+	}
+
+	result, err := store.PostInterestTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// 2.5 units accrued, 2 are posted and half a unit is carried over
+	require.Equal(t, int64(2), result.Posting.Amount)
+	require.True(t, result.Posting.TransferID.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Posting.TransferID.Int64)
+	require.Equal(t, account.Balance+2, result.Transfer.ToAccount.Balance)
+	require.Equal(t, expenseAccount.ID, result.Transfer.FromAccount.ID)
+
+	// posting the same period again has no effect
+	_, err = store.PostInterestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInterestAlreadyPosted)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+2, updatedAccount.Balance)
+
+	unposted, err := testQueries.GetUnpostedInterest(context.Background(), GetUnpostedInterestParams{
+		AccountID: account.ID,
+		PeriodEnd: periodEnd,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(500_000), unposted)
+}
@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Sort orders of transfer searches.
+const (
+	TransferSortCreatedAtAsc  = "created_at_asc"
+	TransferSortCreatedAtDesc = "created_at_desc"
+	TransferSortAmountAsc     = "amount_asc"
+	TransferSortAmountDesc    = "amount_desc"
+)
+
+// TransferWithAccounts is a transfer with the owners and account numbers of its accounts,
+// the rows of transfer lists and searches convert to it.
+type TransferWithAccounts ListTransfersRow
+
+// SearchTransfersParams contains the filters of a transfer search and its sort order.
+type SearchTransfersParams struct {
+	Direction             string         `json:"direction"`
+	Owner                 string         `json:"owner"`
+	AccountID             sql.NullInt64  `json:"account_id"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	MinAmount             sql.NullInt64  `json:"min_amount"`
+	MaxAmount             sql.NullInt64  `json:"max_amount"`
+	StartTime             sql.NullTime   `json:"start_time"`
+	EndTime               sql.NullTime   `json:"end_time"`
+	Currency              sql.NullString `json:"currency"`
+	Sort                  string         `json:"sort"`
+	Offset                int32          `json:"offset"`
+	Limit                 int32          `json:"limit"`
+}
+
+// SearchTransfers searches the transfers of a user with the query of the sort order.
+// Each order has its own query, since postgres cannot use the indexes to sort by an expression of a parameter.
+func (q *Queries) SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]TransferWithAccounts, error) {
+	filter := SearchTransfersByCreatedAtDescParams{
+		Direction:             arg.Direction,
+		Owner:                 arg.Owner,
+		AccountID:             arg.AccountID,
+		CounterpartyAccountID: arg.CounterpartyAccountID,
+		MinAmount:             arg.MinAmount,
+		MaxAmount:             arg.MaxAmount,
+		StartTime:             arg.StartTime,
+		EndTime:               arg.EndTime,
+		Currency:              arg.Currency,
+		Offset:                arg.Offset,
+		Limit:                 arg.Limit,
+	}
+
+	switch arg.Sort {
+	case TransferSortCreatedAtAsc:
+		return toTransfersWithAccounts(q.SearchTransfersByCreatedAtAsc(ctx, SearchTransfersByCreatedAtAscParams(filter)))
+	case TransferSortCreatedAtDesc:
+		return toTransfersWithAccounts(q.SearchTransfersByCreatedAtDesc(ctx, filter))
+	case TransferSortAmountAsc:
+		return toTransfersWithAccounts(q.SearchTransfersByAmountAsc(ctx, SearchTransfersByAmountAscParams(filter)))
+	case TransferSortAmountDesc:
+		return toTransfersWithAccounts(q.SearchTransfersByAmountDesc(ctx, SearchTransfersByAmountDescParams(filter)))
+	default:
+		return nil, fmt.Errorf("unknown transfer sort order %q", arg.Sort)
+	}
+}
+
+type transferSearchRow interface {
+	SearchTransfersByCreatedAtAscRow | SearchTransfersByCreatedAtDescRow |
+		SearchTransfersByAmountAscRow | SearchTransfersByAmountDescRow
+}
+
+func toTransfersWithAccounts[R transferSearchRow](rows []R, err error) ([]TransferWithAccounts, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := make([]TransferWithAccounts, len(rows))
+	for i, row := range rows {
+		transfers[i] = TransferWithAccounts(row)
+	}
+
+	return transfers, nil
+}
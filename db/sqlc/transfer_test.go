@@ -2,9 +2,11 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
+	"github.com/IfanTsai/go-lib/utils/randutils"
 	"github.com/ifantsai/simple-bank-api/util"
 	"github.com/stretchr/testify/require"
 )
@@ -52,14 +54,106 @@ func TestListTransfers(t *testing.T) {
 		require.NotEmpty(t, transfer)
 		require.Equal(t, transfer.FromAccountID, account1.ID)
 		require.Equal(t, transfer.ToAccountID, account2.ID)
+		require.Equal(t, account1.Number, transfer.FromAccountNumber)
+		require.Equal(t, account2.Owner, transfer.ToAccountOwner)
 	}
 }
 
+func TestListTransfersSearch(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for i := 0; i < 5; i++ {
+		createRandomTransfer(t, account1, account2)
+	}
+
+	transfer := createRandomTransfer(t, account1, account2)
+
+	arg := ListTransfersParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account1.ID,
+		Search:        transfer.Description[2:8],
+		Limit:         5,
+		Offset:        0,
+	}
+
+	transfers, err := testQueries.ListTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, transfer.ID, transfers[0].ID)
+}
+
+func TestSearchTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	account3 := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		createRandomTransfer(t, account1, account2)
+		createRandomTransfer(t, account2, account1)
+		createRandomTransfer(t, account3, account2)
+	}
+
+	transfers, err := testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		Direction: "out",
+		Owner:     account1.Owner,
+		Sort:      "amount_desc",
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+
+	for i, transfer := range transfers {
+		require.Equal(t, account1.ID, transfer.FromAccountID)
+
+		if i > 0 {
+			require.LessOrEqual(t, transfer.Amount, transfers[i-1].Amount)
+		}
+	}
+
+	transfers, err = testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		Direction:             "in",
+		Owner:                 account2.Owner,
+		CounterpartyAccountID: sql.NullInt64{Int64: account3.ID, Valid: true},
+		Sort:                  "created_at_asc",
+		Limit:                 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+
+	for _, transfer := range transfers {
+		require.Equal(t, account3.ID, transfer.FromAccountID)
+		require.Equal(t, account2.ID, transfer.ToAccountID)
+		require.Equal(t, account3.Number, transfer.FromAccountNumber)
+		require.Equal(t, account3.Owner, transfer.FromAccountOwner)
+	}
+
+	transfers, err = testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		Direction: "both",
+		Owner:     account3.Owner,
+		MinAmount: sql.NullInt64{Int64: 1001, Valid: true},
+		Sort:      "created_at_desc",
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+	_, err = testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		Direction: "both",
+		Owner:     account3.Owner,
+		Sort:      "id",
+		Limit:     10,
+	})
+	require.Error(t, err)
+}
+
 func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 	arg := CreateTransferParams{
-		FromAccountID: account1.ID,
-		ToAccountID:   account2.ID,
-		Amount:        util.RandomMoney(),
+		FromAccountID:   account1.ID,
+		ToAccountID:     account2.ID,
+		Amount:          util.RandomMoney(),
+		Description:     randutils.RandomString(12),
+		Category:        "general",
+		ClientReference: randutils.RandomString(8),
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -68,6 +162,9 @@ func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
+	require.Equal(t, arg.Description, transfer.Description)
+	require.Equal(t, arg.Category, transfer.Category)
+	require.Equal(t, arg.ClientReference, transfer.ClientReference)
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -7,26 +7,81 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const countTransfersBetween = `-- name: CountTransfersBetween :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND to_account_id = $2 AND status = 'completed'
+`
+
+type CountTransfersBetweenParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+}
+
+func (q *Queries) CountTransfersBetween(ctx context.Context, arg CountTransfersBetweenParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransfersBetween, arg.FromAccountID, arg.ToAccountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTransfersSince = `-- name: CountTransfersSince :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2
+`
+
+type CountTransfersSinceParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (q *Queries) CountTransfersSince(ctx context.Context, arg CountTransfersSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransfersSince, arg.FromAccountID, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    description,
+    category,
+    client_reference,
+    fee,
+    status
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, from_account_id, to_account_id, amount, created_at, description, category, client_reference, fee, status
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID   int64  `json:"from_account_id"`
+	ToAccountID     int64  `json:"to_account_id"`
+	Amount          int64  `json:"amount"`
+	Description     string `json:"description"`
+	Category        string `json:"category"`
+	ClientReference string `json:"client_reference"`
+	Fee             int64  `json:"fee"`
+	Status          string `json:"status"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Description,
+		arg.Category,
+		arg.ClientReference,
+		arg.Fee,
+		arg.Status,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +89,17 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Description,
+		&i.Category,
+		&i.ClientReference,
+		&i.Fee,
+		&i.Status,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, description, category, client_reference, fee, status FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,46 +112,238 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Description,
+		&i.Category,
+		&i.ClientReference,
+		&i.Fee,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getTransferAmountStats = `-- name: GetTransferAmountStats :one
+SELECT COUNT(*) AS transfers,
+       COALESCE(AVG(amount), 0)::bigint AS average_amount
+FROM transfers
+WHERE from_account_id = $1 AND status = 'completed'
+`
+
+type GetTransferAmountStatsRow struct {
+	Transfers     int64 `json:"transfers"`
+	AverageAmount int64 `json:"average_amount"`
+}
+
+func (q *Queries) GetTransferAmountStats(ctx context.Context, fromAccountID int64) (GetTransferAmountStatsRow, error) {
+	row := q.db.QueryRowContext(ctx, getTransferAmountStats, fromAccountID)
+	var i GetTransferAmountStatsRow
+	err := row.Scan(&i.Transfers, &i.AverageAmount)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, description, category, client_reference, fee, status FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Description,
+		&i.Category,
+		&i.ClientReference,
+		&i.Fee,
+		&i.Status,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE from_account_id = $1 OR
-      to_account_id = $2
-ORDER BY id
-LIMIT $3
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.description, t.category, t.client_reference, t.fee, t.status,
+       fa.owner AS from_account_owner, fa.number AS from_account_number,
+       ta.owner AS to_account_owner, ta.number AS to_account_number
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (t.from_account_id = $1 OR
+       t.to_account_id = $2) AND
+      ($3::text = '' OR
+       t.description ILIKE '%' || $3::text || '%' ESCAPE '\' OR
+       t.category ILIKE '%' || $3::text || '%' ESCAPE '\' OR
+       t.client_reference ILIKE '%' || $3::text || '%' ESCAPE '\')
+ORDER BY t.id
+LIMIT $5
 OFFSET $4
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Search        string `json:"search"`
+	Offset        int32  `json:"offset"`
+	Limit         int32  `json:"limit"`
+}
+
+type ListTransfersRow struct {
+	ID                int64     `json:"id"`
+	FromAccountID     int64     `json:"from_account_id"`
+	ToAccountID       int64     `json:"to_account_id"`
+	Amount            int64     `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+	Description       string    `json:"description"`
+	Category          string    `json:"category"`
+	ClientReference   string    `json:"client_reference"`
+	Fee               int64     `json:"fee"`
+	Status            string    `json:"status"`
+	FromAccountOwner  string    `json:"from_account_owner"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountOwner    string    `json:"to_account_owner"`
+	ToAccountNumber   string    `json:"to_account_number"`
 }
 
-func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+// the search term must have its LIKE wildcards escaped with a backslash
+func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]ListTransfersRow, error) {
 	rows, err := q.db.QueryContext(ctx, listTransfers,
 		arg.FromAccountID,
 		arg.ToAccountID,
+		arg.Search,
+		arg.Offset,
 		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransfersRow{}
+	for rows.Next() {
+		var i ListTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Description,
+			&i.Category,
+			&i.ClientReference,
+			&i.Fee,
+			&i.Status,
+			&i.FromAccountOwner,
+			&i.FromAccountNumber,
+			&i.ToAccountOwner,
+			&i.ToAccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersByAmountAsc = `-- name: SearchTransfersByAmountAsc :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.description, t.category, t.client_reference, t.fee, t.status,
+       fa.owner AS from_account_owner, fa.number AS from_account_number,
+       ta.owner AS to_account_owner, ta.number AS to_account_number
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (($1::text IN ('out', 'both') AND
+        fa.owner = $2::text AND
+        ($3::bigint IS NULL OR t.from_account_id = $3::bigint) AND
+        ($4::bigint IS NULL OR t.to_account_id = $4::bigint)) OR
+       ($1::text IN ('in', 'both') AND
+        ta.owner = $2::text AND
+        ($3::bigint IS NULL OR t.to_account_id = $3::bigint) AND
+        ($4::bigint IS NULL OR t.from_account_id = $4::bigint))) AND
+      ($5::bigint IS NULL OR t.amount >= $5::bigint) AND
+      ($6::bigint IS NULL OR t.amount <= $6::bigint) AND
+      ($7::timestamptz IS NULL OR t.created_at >= $7::timestamptz) AND
+      ($8::timestamptz IS NULL OR t.created_at < $8::timestamptz) AND
+      ($9::text IS NULL OR fa.currency = $9::text)
+ORDER BY t.amount ASC, t.id ASC
+LIMIT $11
+OFFSET $10
+`
+
+type SearchTransfersByAmountAscParams struct {
+	Direction             string         `json:"direction"`
+	Owner                 string         `json:"owner"`
+	AccountID             sql.NullInt64  `json:"account_id"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	MinAmount             sql.NullInt64  `json:"min_amount"`
+	MaxAmount             sql.NullInt64  `json:"max_amount"`
+	StartTime             sql.NullTime   `json:"start_time"`
+	EndTime               sql.NullTime   `json:"end_time"`
+	Currency              sql.NullString `json:"currency"`
+	Offset                int32          `json:"offset"`
+	Limit                 int32          `json:"limit"`
+}
+
+type SearchTransfersByAmountAscRow struct {
+	ID                int64     `json:"id"`
+	FromAccountID     int64     `json:"from_account_id"`
+	ToAccountID       int64     `json:"to_account_id"`
+	Amount            int64     `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+	Description       string    `json:"description"`
+	Category          string    `json:"category"`
+	ClientReference   string    `json:"client_reference"`
+	Fee               int64     `json:"fee"`
+	Status            string    `json:"status"`
+	FromAccountOwner  string    `json:"from_account_owner"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountOwner    string    `json:"to_account_owner"`
+	ToAccountNumber   string    `json:"to_account_number"`
+}
+
+func (q *Queries) SearchTransfersByAmountAsc(ctx context.Context, arg SearchTransfersByAmountAscParams) ([]SearchTransfersByAmountAscRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersByAmountAsc,
+		arg.Direction,
+		arg.Owner,
+		arg.AccountID,
+		arg.CounterpartyAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Currency,
 		arg.Offset,
+		arg.Limit,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Transfer{}
+	items := []SearchTransfersByAmountAscRow{}
 	for rows.Next() {
-		var i Transfer
+		var i SearchTransfersByAmountAscRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.FromAccountID,
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Description,
+			&i.Category,
+			&i.ClientReference,
+			&i.Fee,
+			&i.Status,
+			&i.FromAccountOwner,
+			&i.FromAccountNumber,
+			&i.ToAccountOwner,
+			&i.ToAccountNumber,
 		); err != nil {
 			return nil, err
 		}
@@ -105,3 +357,351 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const searchTransfersByAmountDesc = `-- name: SearchTransfersByAmountDesc :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.description, t.category, t.client_reference, t.fee, t.status,
+       fa.owner AS from_account_owner, fa.number AS from_account_number,
+       ta.owner AS to_account_owner, ta.number AS to_account_number
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (($1::text IN ('out', 'both') AND
+        fa.owner = $2::text AND
+        ($3::bigint IS NULL OR t.from_account_id = $3::bigint) AND
+        ($4::bigint IS NULL OR t.to_account_id = $4::bigint)) OR
+       ($1::text IN ('in', 'both') AND
+        ta.owner = $2::text AND
+        ($3::bigint IS NULL OR t.to_account_id = $3::bigint) AND
+        ($4::bigint IS NULL OR t.from_account_id = $4::bigint))) AND
+      ($5::bigint IS NULL OR t.amount >= $5::bigint) AND
+      ($6::bigint IS NULL OR t.amount <= $6::bigint) AND
+      ($7::timestamptz IS NULL OR t.created_at >= $7::timestamptz) AND
+      ($8::timestamptz IS NULL OR t.created_at < $8::timestamptz) AND
+      ($9::text IS NULL OR fa.currency = $9::text)
+ORDER BY t.amount DESC, t.id DESC
+LIMIT $11
+OFFSET $10
+`
+
+type SearchTransfersByAmountDescParams struct {
+	Direction             string         `json:"direction"`
+	Owner                 string         `json:"owner"`
+	AccountID             sql.NullInt64  `json:"account_id"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	MinAmount             sql.NullInt64  `json:"min_amount"`
+	MaxAmount             sql.NullInt64  `json:"max_amount"`
+	StartTime             sql.NullTime   `json:"start_time"`
+	EndTime               sql.NullTime   `json:"end_time"`
+	Currency              sql.NullString `json:"currency"`
+	Offset                int32          `json:"offset"`
+	Limit                 int32          `json:"limit"`
+}
+
+type SearchTransfersByAmountDescRow struct {
+	ID                int64     `json:"id"`
+	FromAccountID     int64     `json:"from_account_id"`
+	ToAccountID       int64     `json:"to_account_id"`
+	Amount            int64     `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+	Description       string    `json:"description"`
+	Category          string    `json:"category"`
+	ClientReference   string    `json:"client_reference"`
+	Fee               int64     `json:"fee"`
+	Status            string    `json:"status"`
+	FromAccountOwner  string    `json:"from_account_owner"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountOwner    string    `json:"to_account_owner"`
+	ToAccountNumber   string    `json:"to_account_number"`
+}
+
+func (q *Queries) SearchTransfersByAmountDesc(ctx context.Context, arg SearchTransfersByAmountDescParams) ([]SearchTransfersByAmountDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersByAmountDesc,
+		arg.Direction,
+		arg.Owner,
+		arg.AccountID,
+		arg.CounterpartyAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Currency,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchTransfersByAmountDescRow{}
+	for rows.Next() {
+		var i SearchTransfersByAmountDescRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Description,
+			&i.Category,
+			&i.ClientReference,
+			&i.Fee,
+			&i.Status,
+			&i.FromAccountOwner,
+			&i.FromAccountNumber,
+			&i.ToAccountOwner,
+			&i.ToAccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersByCreatedAtAsc = `-- name: SearchTransfersByCreatedAtAsc :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.description, t.category, t.client_reference, t.fee, t.status,
+       fa.owner AS from_account_owner, fa.number AS from_account_number,
+       ta.owner AS to_account_owner, ta.number AS to_account_number
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (($1::text IN ('out', 'both') AND
+        fa.owner = $2::text AND
+        ($3::bigint IS NULL OR t.from_account_id = $3::bigint) AND
+        ($4::bigint IS NULL OR t.to_account_id = $4::bigint)) OR
+       ($1::text IN ('in', 'both') AND
+        ta.owner = $2::text AND
+        ($3::bigint IS NULL OR t.to_account_id = $3::bigint) AND
+        ($4::bigint IS NULL OR t.from_account_id = $4::bigint))) AND
+      ($5::bigint IS NULL OR t.amount >= $5::bigint) AND
+      ($6::bigint IS NULL OR t.amount <= $6::bigint) AND
+      ($7::timestamptz IS NULL OR t.created_at >= $7::timestamptz) AND
+      ($8::timestamptz IS NULL OR t.created_at < $8::timestamptz) AND
+      ($9::text IS NULL OR fa.currency = $9::text)
+ORDER BY t.created_at ASC, t.id ASC
+LIMIT $11
+OFFSET $10
+`
+
+type SearchTransfersByCreatedAtAscParams struct {
+	Direction             string         `json:"direction"`
+	Owner                 string         `json:"owner"`
+	AccountID             sql.NullInt64  `json:"account_id"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	MinAmount             sql.NullInt64  `json:"min_amount"`
+	MaxAmount             sql.NullInt64  `json:"max_amount"`
+	StartTime             sql.NullTime   `json:"start_time"`
+	EndTime               sql.NullTime   `json:"end_time"`
+	Currency              sql.NullString `json:"currency"`
+	Offset                int32          `json:"offset"`
+	Limit                 int32          `json:"limit"`
+}
+
+type SearchTransfersByCreatedAtAscRow struct {
+	ID                int64     `json:"id"`
+	FromAccountID     int64     `json:"from_account_id"`
+	ToAccountID       int64     `json:"to_account_id"`
+	Amount            int64     `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+	Description       string    `json:"description"`
+	Category          string    `json:"category"`
+	ClientReference   string    `json:"client_reference"`
+	Fee               int64     `json:"fee"`
+	Status            string    `json:"status"`
+	FromAccountOwner  string    `json:"from_account_owner"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountOwner    string    `json:"to_account_owner"`
+	ToAccountNumber   string    `json:"to_account_number"`
+}
+
+// the search queries only differ by their static order, so that postgres can use the indexes to sort
+func (q *Queries) SearchTransfersByCreatedAtAsc(ctx context.Context, arg SearchTransfersByCreatedAtAscParams) ([]SearchTransfersByCreatedAtAscRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersByCreatedAtAsc,
+		arg.Direction,
+		arg.Owner,
+		arg.AccountID,
+		arg.CounterpartyAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Currency,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchTransfersByCreatedAtAscRow{}
+	for rows.Next() {
+		var i SearchTransfersByCreatedAtAscRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Description,
+			&i.Category,
+			&i.ClientReference,
+			&i.Fee,
+			&i.Status,
+			&i.FromAccountOwner,
+			&i.FromAccountNumber,
+			&i.ToAccountOwner,
+			&i.ToAccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersByCreatedAtDesc = `-- name: SearchTransfersByCreatedAtDesc :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.description, t.category, t.client_reference, t.fee, t.status,
+       fa.owner AS from_account_owner, fa.number AS from_account_number,
+       ta.owner AS to_account_owner, ta.number AS to_account_number
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+WHERE (($1::text IN ('out', 'both') AND
+        fa.owner = $2::text AND
+        ($3::bigint IS NULL OR t.from_account_id = $3::bigint) AND
+        ($4::bigint IS NULL OR t.to_account_id = $4::bigint)) OR
+       ($1::text IN ('in', 'both') AND
+        ta.owner = $2::text AND
+        ($3::bigint IS NULL OR t.to_account_id = $3::bigint) AND
+        ($4::bigint IS NULL OR t.from_account_id = $4::bigint))) AND
+      ($5::bigint IS NULL OR t.amount >= $5::bigint) AND
+      ($6::bigint IS NULL OR t.amount <= $6::bigint) AND
+      ($7::timestamptz IS NULL OR t.created_at >= $7::timestamptz) AND
+      ($8::timestamptz IS NULL OR t.created_at < $8::timestamptz) AND
+      ($9::text IS NULL OR fa.currency = $9::text)
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $11
+OFFSET $10
+`
+
+type SearchTransfersByCreatedAtDescParams struct {
+	Direction             string         `json:"direction"`
+	Owner                 string         `json:"owner"`
+	AccountID             sql.NullInt64  `json:"account_id"`
+	CounterpartyAccountID sql.NullInt64  `json:"counterparty_account_id"`
+	MinAmount             sql.NullInt64  `json:"min_amount"`
+	MaxAmount             sql.NullInt64  `json:"max_amount"`
+	StartTime             sql.NullTime   `json:"start_time"`
+	EndTime               sql.NullTime   `json:"end_time"`
+	Currency              sql.NullString `json:"currency"`
+	Offset                int32          `json:"offset"`
+	Limit                 int32          `json:"limit"`
+}
+
+type SearchTransfersByCreatedAtDescRow struct {
+	ID                int64     `json:"id"`
+	FromAccountID     int64     `json:"from_account_id"`
+	ToAccountID       int64     `json:"to_account_id"`
+	Amount            int64     `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+	Description       string    `json:"description"`
+	Category          string    `json:"category"`
+	ClientReference   string    `json:"client_reference"`
+	Fee               int64     `json:"fee"`
+	Status            string    `json:"status"`
+	FromAccountOwner  string    `json:"from_account_owner"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountOwner    string    `json:"to_account_owner"`
+	ToAccountNumber   string    `json:"to_account_number"`
+}
+
+func (q *Queries) SearchTransfersByCreatedAtDesc(ctx context.Context, arg SearchTransfersByCreatedAtDescParams) ([]SearchTransfersByCreatedAtDescRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersByCreatedAtDesc,
+		arg.Direction,
+		arg.Owner,
+		arg.AccountID,
+		arg.CounterpartyAccountID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Currency,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchTransfersByCreatedAtDescRow{}
+	for rows.Next() {
+		var i SearchTransfersByCreatedAtDescRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Description,
+			&i.Category,
+			&i.ClientReference,
+			&i.Fee,
+			&i.Status,
+			&i.FromAccountOwner,
+			&i.FromAccountNumber,
+			&i.ToAccountOwner,
+			&i.ToAccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers SET status = $2
+WHERE id = $1
+RETURNING id, from_account_id, to_account_id, amount, created_at, description, category, client_reference, fee, status
+`
+
+type UpdateTransferStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferStatus, arg.ID, arg.Status)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Description,
+		&i.Category,
+		&i.ClientReference,
+		&i.Fee,
+		&i.Status,
+	)
+	return i, err
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// CreateUserTxParams contains the input parameters of the create user transaction.
+type CreateUserTxParams struct {
+	CreateUserParams
+}
+
+// CreateUserTx creates a user and records the user.created event within a single database transaction.
+func (s *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		user, err = q.CreateUser(ctx, arg.CreateUserParams)
+		if err != nil {
+			return err
+		}
+
+		return addOutboxEvent(ctx, q, OutboxAggregateUser, user.Username, EventUserCreated, newAuditUser(user))
+	})
+
+	return user, err
+}
+
+// UpdateUserTxParams contains the input parameters of the update user transaction.
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	Audit AuditMeta `json:"-"`
+}
+
+// auditUser is the state of a user recorded in the audit log, without credentials.
+type auditUser struct {
+	Username          string    `json:"username"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	RequireSavedPayee bool      `json:"require_saved_payee"`
+	Tier              string    `json:"tier"`
+}
+
+func newAuditUser(user User) auditUser {
+	return auditUser{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: user.PasswordChangedAt,
+		RequireSavedPayee: user.RequireSavedPayee,
+		Tier:              user.Tier,
+	}
+}
+
+// UpdateUserTx updates a user and records the change in the audit log within a single database transaction.
+func (s *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.UpdateUser(ctx, arg.UpdateUserParams)
+		if err != nil {
+			return err
+		}
+
+		_, err = appendAuditEvent(ctx, q, arg.Audit,
+			AuditActionUpdateUser, AuditResourceUser, user.Username,
+			newAuditUser(before), newAuditUser(user),
+		)
+
+		return err
+	})
+
+	return user, err
+}
@@ -7,15 +7,23 @@ CREATE TABLE "users" (
   "hashed_password" varchar NOT NULL,
   "full_name" varchar NOT NULL,
   "email" varchar UNIQUE NOT NULL,
+  "tier" varchar NOT NULL DEFAULT 'standard',
+  "role" varchar NOT NULL DEFAULT 'depositor',
+  "require_saved_payee" boolean NOT NULL DEFAULT false,
   "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
 CREATE TABLE "accounts" (
   "id" bigserial PRIMARY KEY,
+  "number" varchar UNIQUE NOT NULL DEFAULT (generate_account_number()),
   "owner" varchar NOT NULL,
   "balance" bigint NOT NULL,
   "currency" varchar NOT NULL,
+  "type" varchar NOT NULL DEFAULT 'checking',
+  "nickname" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'open',
+  "closed_at" timestamptz,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -23,6 +31,9 @@ CREATE TABLE "entries" (
   "id" bigserial PRIMARY KEY,
   "account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "category" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint,
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -31,6 +42,11 @@ CREATE TABLE "transfers" (
   "from_account_id" bigint NOT NULL,
   "to_account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
+  "fee" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'completed',
+  "description" varchar NOT NULL DEFAULT '',
+  "category" varchar NOT NULL DEFAULT '',
+  "client_reference" varchar NOT NULL DEFAULT '',
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
@@ -45,28 +61,309 @@ CREATE TABLE "session" (
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "resource_type" varchar NOT NULL,
+  "resource_id" varchar NOT NULL,
+  "before_state" jsonb NOT NULL,
+  "after_state" jsonb NOT NULL,
+  "prev_hash" varchar NOT NULL,
+  "hash" varchar UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL
+);
+
+CREATE TABLE "account_interest_rates" (
+  "account_id" bigint PRIMARY KEY,
+  "rate_bps" integer NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "rate_bps" integer NOT NULL,
+  "amount_micros" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_postings" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period_end" date NOT NULL,
+  "amount" bigint NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "tier_limits" (
+  "tier" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "per_transaction" bigint NOT NULL DEFAULT 0,
+  "daily" bigint NOT NULL DEFAULT 0,
+  "monthly" bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY ("tier", "currency")
+);
+
+CREATE TABLE "account_limits" (
+  "account_id" bigint PRIMARY KEY,
+  "per_transaction" bigint NOT NULL DEFAULT 0,
+  "daily" bigint NOT NULL DEFAULT 0,
+  "monthly" bigint NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "risk_assessments" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint UNIQUE,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "outcome" varchar NOT NULL,
+  "reason" varchar NOT NULL,
+  "reviewed_by" varchar NOT NULL DEFAULT '',
+  "reviewed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "payment_requests" (
+  "id" bigserial PRIMARY KEY,
+  "requester_account_id" bigint NOT NULL,
+  "requester" varchar NOT NULL,
+  "payer" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "memo" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "transfer_id" bigint UNIQUE,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "outbox_events" (
+  "id" bigserial PRIMARY KEY,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "published_at" timestamptz,
+  "failed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_subscriptions" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "event_types" varchar[] NOT NULL,
+  "low_balance_threshold" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "subscription_id" bigint NOT NULL,
+  "event_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_status_code" integer NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "rate_limit_buckets" (
+  "key" varchar PRIMARY KEY,
+  "tokens" double precision NOT NULL,
+  "allowed" boolean NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "payees" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "nickname" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
 CREATE INDEX ON "accounts" ("owner");
 
-CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
+CREATE INDEX ON "accounts" ("owner", "currency");
+
+CREATE UNIQUE INDEX ON "accounts" ("owner", "nickname") WHERE "nickname" <> '' AND "status" = 'open';
 
 CREATE INDEX ON "entries" ("account_id");
 
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+CREATE INDEX ON "entries" ("account_id", "id");
+
 CREATE INDEX ON "transfers" ("from_account_id");
 
 CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at");
+
+CREATE INDEX ON "transfers" ("from_account_id", "amount");
+
+CREATE INDEX ON "transfers" ("to_account_id", "amount");
+
+CREATE INDEX ON "transfers" USING GIN ("description");
+
+CREATE INDEX ON "transfers" ("category");
+
+CREATE INDEX ON "transfers" ("client_reference");
+
+CREATE INDEX ON "transfers" ("status");
+
 COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
 
 COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
 
+COMMENT ON COLUMN "transfers"."fee" IS 'charged to the sender on top of the amount';
+
+COMMENT ON COLUMN "transfers"."status" IS 'completed, pending_review or rejected';
+
+CREATE INDEX ON "audit_events" ("actor");
+
+CREATE INDEX ON "audit_events" ("resource_type", "resource_id");
+
+COMMENT ON COLUMN "audit_events"."prev_hash" IS 'hash of the previous event in the chain';
+
+COMMENT ON COLUMN "audit_events"."hash" IS 'sha256 over prev_hash and the event content';
+
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+
+CREATE UNIQUE INDEX ON "interest_postings" ("account_id", "period_end");
+
+COMMENT ON COLUMN "account_interest_rates"."rate_bps" IS 'annual rate in basis points';
+
+COMMENT ON COLUMN "interest_accruals"."amount_micros" IS 'accrued interest in millionths of the minor unit';
+
+COMMENT ON COLUMN "interest_postings"."amount" IS 'posted interest in minor units';
+
+COMMENT ON COLUMN "tier_limits"."per_transaction" IS '0 means unlimited';
+
+COMMENT ON COLUMN "tier_limits"."daily" IS 'outgoing total of all accounts of the user in the currency, 0 means unlimited';
+
+COMMENT ON COLUMN "tier_limits"."monthly" IS 'outgoing total of all accounts of the user in the currency, 0 means unlimited';
+
+COMMENT ON COLUMN "account_limits"."daily" IS 'outgoing total of the account, 0 means unlimited';
+
+COMMENT ON COLUMN "account_limits"."monthly" IS 'outgoing total of the account, 0 means unlimited';
+
+CREATE INDEX ON "risk_assessments" ("username", "client_ip", "user_agent");
+
+COMMENT ON COLUMN "risk_assessments"."transfer_id" IS 'empty for denied transfers';
+
+COMMENT ON COLUMN "risk_assessments"."outcome" IS 'allow, review or deny';
+
+CREATE UNIQUE INDEX ON "payees" ("owner", "account_id");
+
+CREATE UNIQUE INDEX ON "payees" ("owner", "nickname");
+
+COMMENT ON COLUMN "accounts"."number" IS 'external account number with mod-97 check digits';
+
+COMMENT ON COLUMN "accounts"."status" IS 'open or closed, closed accounts cannot send or receive money';
+
+CREATE INDEX ON "payment_requests" ("requester");
+
+CREATE INDEX ON "payment_requests" ("payer");
+
+COMMENT ON COLUMN "payment_requests"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "payment_requests"."status" IS 'pending, paid or declined';
+
+COMMENT ON COLUMN "payment_requests"."transfer_id" IS 'transfer paying the request, it may still be pending review';
+
+CREATE INDEX ON "outbox_events" ("id") WHERE "published_at" IS NULL AND "failed_at" IS NULL;
+
+CREATE INDEX ON "outbox_events" ("aggregate_type", "aggregate_id");
+
+COMMENT ON COLUMN "outbox_events"."published_at" IS 'empty until delivered to every sink';
+
+COMMENT ON COLUMN "outbox_events"."failed_at" IS 'set when the relay gave up, clear it to publish the event again';
+
+CREATE INDEX ON "webhook_subscriptions" ("owner");
+
+CREATE UNIQUE INDEX ON "webhook_deliveries" ("subscription_id", "event_id", "event_type");
+
+CREATE INDEX ON "webhook_deliveries" ("next_attempt_at") WHERE "status" = 'pending';
+
+CREATE INDEX ON "rate_limit_buckets" ("updated_at");
+
+COMMENT ON COLUMN "webhook_subscriptions"."secret" IS 'key of the HMAC-SHA256 payload signatures';
+
+COMMENT ON COLUMN "webhook_subscriptions"."event_types" IS 'transfer.received, transfer.sent or balance.low';
+
+COMMENT ON COLUMN "webhook_subscriptions"."low_balance_threshold" IS 'balance.low is sent when a transfer leaves less than this';
+
+COMMENT ON COLUMN "webhook_deliveries"."event_id" IS 'outbox event the delivery was created for';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded or failed';
+
+COMMENT ON COLUMN "rate_limit_buckets"."key" IS 'method and client IP or username';
+
+COMMENT ON COLUMN "rate_limit_buckets"."allowed" IS 'whether the last request took a token';
+
+COMMENT ON COLUMN "users"."require_saved_payee" IS 'only allow transfers to saved payees and own accounts';
+
 ALTER TABLE "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
 
 ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
 ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "session" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "account_interest_rates" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "account_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "risk_assessments" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "risk_assessments" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("requester_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("requester") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("payer") REFERENCES "users" ("username");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
@@ -0,0 +1,238 @@
+package fee
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+
+	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/pkg/errors"
+)
+
+// Types of fee rules.
+const (
+	// Flat charges a fixed amount.
+	Flat = "flat"
+	// Percent charges a share of the amount in basis points.
+	Percent = "percent"
+	// Tiered charges a flat amount plus a share of the amount depending on the tier the amount falls in.
+	Tiered = "tiered"
+)
+
+const basisPointsPerUnit = 10_000
+
+// Condition restricts when a rule applies. A zero condition applies to every transfer.
+type Condition struct {
+	// Instant applies the rule only to instant transfers.
+	Instant bool `json:"instant"`
+	// MinAmount applies the rule only if the amount is at least this threshold.
+	MinAmount int64 `json:"min_amount"`
+}
+
+// Tier is a price band of a tiered rule.
+type Tier struct {
+	// UpTo is the inclusive upper bound of the amount, 0 means unbounded.
+	UpTo int64 `json:"up_to"`
+	Flat int64 `json:"flat"`
+	Bps  int64 `json:"bps"`
+}
+
+// Rule is a single pricing rule.
+type Rule struct {
+	Name string `json:"name"`
+	// Currency applies the rule only to transfers sent in this currency, empty means all currencies.
+	Currency string    `json:"currency"`
+	When     Condition `json:"when"`
+	Type     string    `json:"type"`
+	Flat     int64     `json:"flat"`
+	Bps      int64     `json:"bps"`
+	Tiers    []Tier    `json:"tiers"`
+	// Min and Max bound the fee charged by this rule, 0 means unbounded.
+	Min int64 `json:"min"`
+	Max int64 `json:"max"`
+}
+
+// Transfer describes the transfer to be priced. Both accounts of a transfer have the same currency.
+type Transfer struct {
+	Amount   int64
+	Currency string
+	Instant  bool
+}
+
+// Charge is the fee charged by a single rule.
+type Charge struct {
+	Rule   string `json:"rule"`
+	Amount int64  `json:"amount"`
+}
+
+// Quote is the price of a transfer.
+type Quote struct {
+	Amount   int64    `json:"amount"`
+	Fee      int64    `json:"fee"`
+	Total    int64    `json:"total"`
+	Currency string   `json:"currency"`
+	Charges  []Charge `json:"charges"`
+}
+
+// Schedule evaluates the fee rules of transfers.
+type Schedule struct {
+	rules []Rule
+}
+
+// NewSchedule creates a new fee schedule after validating the rules.
+func NewSchedule(rules []Rule) (*Schedule, error) {
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid fee rule %d (%s)", i, rule.Name)
+		}
+	}
+
+	return &Schedule{rules: rules}, nil
+}
+
+// LoadSchedule reads the fee rules from a JSON file.
+// An empty path returns a schedule that charges no fees.
+func LoadSchedule(path string) (*Schedule, error) {
+	if path == "" {
+		return &Schedule{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read fee rules")
+	}
+
+	// unknown keys are rejected, since a rule with an ignored condition would apply to every transfer
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var rules []Rule
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, errors.Wrap(err, "cannot parse fee rules")
+	}
+
+	return NewSchedule(rules)
+}
+
+// Quote prices a transfer. The fees of all matching rules are added up.
+func (s *Schedule) Quote(transfer Transfer) Quote {
+	quote := Quote{
+		Amount:   transfer.Amount,
+		Currency: transfer.Currency,
+		Charges:  []Charge{},
+	}
+
+	for _, rule := range s.rules {
+		if !rule.matches(transfer) {
+			continue
+		}
+
+		amount := rule.fee(transfer.Amount)
+		if amount <= 0 {
+			continue
+		}
+
+		quote.Charges = append(quote.Charges, Charge{Rule: rule.Name, Amount: amount})
+		quote.Fee += amount
+	}
+
+	quote.Total = quote.Amount + quote.Fee
+
+	return quote
+}
+
+func (r Rule) validate() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if r.Currency != "" && !util.IsSupportedCurrency(r.Currency) {
+		return errors.Errorf("unsupported currency %s", r.Currency)
+	}
+
+	if r.Flat < 0 || r.Bps < 0 || r.Min < 0 || r.Max < 0 || r.When.MinAmount < 0 {
+		return errors.New("amounts must not be negative")
+	}
+
+	if r.Max > 0 && r.Min > r.Max {
+		return errors.New("min must not be greater than max")
+	}
+
+	switch r.Type {
+	case Flat, Percent:
+	case Tiered:
+		if len(r.Tiers) == 0 {
+			return errors.New("tiered rule needs at least one tier")
+		}
+
+		for i, tier := range r.Tiers {
+			if tier.Flat < 0 || tier.Bps < 0 {
+				return errors.Errorf("tier %d amounts must not be negative", i)
+			}
+
+			last := i == len(r.Tiers)-1
+			if tier.UpTo == 0 && !last {
+				return errors.New("only the last tier can be unbounded")
+			}
+
+			if i > 0 && tier.UpTo != 0 && tier.UpTo <= r.Tiers[i-1].UpTo {
+				return errors.Errorf("tier %d must have a greater upper bound than the previous tier", i)
+			}
+		}
+	default:
+		return errors.Errorf("unsupported rule type %q", r.Type)
+	}
+
+	return nil
+}
+
+func (r Rule) matches(transfer Transfer) bool {
+	if r.Currency != "" && r.Currency != transfer.Currency {
+		return false
+	}
+
+	if r.When.Instant && !transfer.Instant {
+		return false
+	}
+
+	return transfer.Amount >= r.When.MinAmount
+}
+
+func (r Rule) fee(amount int64) int64 {
+	var fee int64
+
+	switch r.Type {
+	case Flat:
+		fee = r.Flat
+	case Percent:
+		fee = r.Flat + percentage(amount, r.Bps)
+	case Tiered:
+		for _, tier := range r.Tiers {
+			if tier.UpTo == 0 || amount <= tier.UpTo {
+				fee = tier.Flat + percentage(amount, tier.Bps)
+
+				break
+			}
+		}
+	}
+
+	if fee < r.Min {
+		fee = r.Min
+	}
+
+	if r.Max > 0 && fee > r.Max {
+		fee = r.Max
+	}
+
+	return fee
+}
+
+// percentage returns the basis points of the amount, rounded half up to the minor unit.
+func percentage(amount, bps int64) int64 {
+	fee := new(big.Int).Mul(big.NewInt(amount), big.NewInt(bps))
+	fee.Add(fee, big.NewInt(basisPointsPerUnit/2))
+	fee.Quo(fee, big.NewInt(basisPointsPerUnit))
+
+	return fee.Int64()
+}
@@ -0,0 +1,185 @@
+package fee
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuote(t *testing.T) {
+	schedule, err := NewSchedule([]Rule{
+		{
+			Name: "instant",
+			When: Condition{Instant: true},
+			Type: Percent,
+			Bps:  50,
+			Min:  25,
+			Max:  1000,
+		},
+		{
+			Name:     "large_usd",
+			Currency: util.USD,
+			When:     Condition{MinAmount: 100_000},
+			Type:     Tiered,
+			Tiers: []Tier{
+				{UpTo: 1_000_000, Flat: 100},
+				{Flat: 100, Bps: 5},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		transfer Transfer
+		charges  []Charge
+	}{
+		{
+			name:     "NoFee",
+			transfer: Transfer{Amount: 500, Currency: util.USD},
+			charges:  []Charge{},
+		},
+		{
+			name:     "InstantMinimum",
+			transfer: Transfer{Amount: 500, Currency: util.EUR, Instant: true},
+			charges:  []Charge{{Rule: "instant", Amount: 25}},
+		},
+		{
+			name:     "InstantPercent",
+			transfer: Transfer{Amount: 10_099, Currency: util.EUR, Instant: true},
+			charges:  []Charge{{Rule: "instant", Amount: 50}},
+		},
+		{
+			name:     "InstantMaximum",
+			transfer: Transfer{Amount: 90_000, Currency: util.CAD, Instant: true},
+			charges:  []Charge{{Rule: "instant", Amount: 450}},
+		},
+		{
+			name:     "FirstTier",
+			transfer: Transfer{Amount: 1_000_000, Currency: util.USD},
+			charges:  []Charge{{Rule: "large_usd", Amount: 100}},
+		},
+		{
+			name:     "LastTier",
+			transfer: Transfer{Amount: 2_000_000, Currency: util.USD},
+			charges:  []Charge{{Rule: "large_usd", Amount: 1100}},
+		},
+		{
+			name:     "OtherCurrencyBelowThreshold",
+			transfer: Transfer{Amount: 2_000_000, Currency: util.EUR},
+			charges:  []Charge{},
+		},
+		{
+			name: "Combined",
+			transfer: Transfer{
+				Amount:   2_000_000,
+				Currency: util.USD,
+				Instant:  true,
+			},
+			charges: []Charge{
+				{Rule: "instant", Amount: 1000},
+				{Rule: "large_usd", Amount: 1100},
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			quote := schedule.Quote(tc.transfer)
+
+			var fee int64
+			for _, charge := range tc.charges {
+				fee += charge.Amount
+			}
+
+			require.Equal(t, tc.charges, quote.Charges)
+			require.Equal(t, tc.transfer.Amount, quote.Amount)
+			require.Equal(t, fee, quote.Fee)
+			require.Equal(t, tc.transfer.Amount+fee, quote.Total)
+			require.Equal(t, tc.transfer.Currency, quote.Currency)
+		})
+	}
+}
+
+func TestNewScheduleInvalidRules(t *testing.T) {
+	testCases := []struct {
+		name string
+		rule Rule
+	}{
+		{
+			name: "NoName",
+			rule: Rule{Type: Flat},
+		},
+		{
+			name: "UnknownType",
+			rule: Rule{Name: "rule", Type: "free"},
+		},
+		{
+			name: "UnsupportedCurrency",
+			rule: Rule{Name: "rule", Type: Flat, Currency: "JPY"},
+		},
+		{
+			name: "NegativeAmount",
+			rule: Rule{Name: "rule", Type: Flat, Flat: -1},
+		},
+		{
+			name: "MinGreaterThanMax",
+			rule: Rule{Name: "rule", Type: Percent, Min: 10, Max: 5},
+		},
+		{
+			name: "NoTiers",
+			rule: Rule{Name: "rule", Type: Tiered},
+		},
+		{
+			name: "UnboundedTierNotLast",
+			rule: Rule{Name: "rule", Type: Tiered, Tiers: []Tier{{Flat: 1}, {UpTo: 100, Flat: 2}}},
+		},
+		{
+			name: "UnorderedTiers",
+			rule: Rule{Name: "rule", Type: Tiered, Tiers: []Tier{{UpTo: 100, Flat: 1}, {UpTo: 50, Flat: 2}}},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewSchedule([]Rule{tc.rule})
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLoadSchedule(t *testing.T) {
+	schedule, err := LoadSchedule("")
+	require.NoError(t, err)
+	require.Zero(t, schedule.Quote(Transfer{Amount: 100, Instant: true}).Fee)
+
+	path := filepath.Join(t.TempDir(), "fee_rules.json")
+	err = os.WriteFile(path, []byte(`[{"name": "flat", "type": "flat", "flat": 7}]`), 0o600)
+	require.NoError(t, err)
+
+	schedule, err = LoadSchedule(path)
+	require.NoError(t, err)
+	require.Equal(t, int64(7), schedule.Quote(Transfer{Amount: 100}).Fee)
+
+	_, err = LoadSchedule(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+
+	// a condition which isn't supported must not be ignored
+	err = os.WriteFile(path, []byte(`[{"name": "fx", "when": {"cross_currency": true}, "type": "flat", "flat": 7}]`), 0o600)
+	require.NoError(t, err)
+
+	_, err = LoadSchedule(path)
+	require.Error(t, err)
+}
+
+func TestRepositoryFeeRules(t *testing.T) {
+	_, err := LoadSchedule("../fee_rules.json")
+	require.NoError(t, err)
+}
@@ -5,8 +5,13 @@ import (
 	"strings"
 
 	"github.com/IfanTsai/go-lib/user/token"
+	"github.com/ifantsai/simple-bank-api/pb"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 const (
@@ -49,3 +54,92 @@ func (s *GRPCServer) authorizeUser(ctx context.Context) (*token.Payload, error)
 
 	return payload, nil
 }
+
+type payloadContextKey struct{}
+
+// payloadFromContext returns the payload of the access token of a private method, which is verified by the auth interceptor.
+func payloadFromContext(ctx context.Context) *token.Payload {
+	payload, _ := ctx.Value(payloadContextKey{}).(*token.Payload)
+
+	return payload
+}
+
+// methodAuth returns the auth requirement a method declares with the auth option.
+func methodAuth(fullMethod string) pb.Auth {
+	name := strings.ReplaceAll(strings.TrimPrefix(fullMethod, "/"), "/", ".")
+
+	descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return pb.Auth_AUTH_UNSPECIFIED
+	}
+
+	method, ok := descriptor.(protoreflect.MethodDescriptor)
+	if !ok {
+		return pb.Auth_AUTH_UNSPECIFIED
+	}
+
+	auth, _ := proto.GetExtension(method.Options(), pb.E_Auth).(pb.Auth)
+
+	return auth
+}
+
+// checkMethodAuth checks that every method of a service declares whether it is public or private,
+// so that a new method cannot be served without authentication by mistake.
+func checkMethodAuth(service grpc.ServiceDesc) error {
+	for _, method := range service.Methods {
+		if methodAuth("/"+service.ServiceName+"/"+method.MethodName) == pb.Auth_AUTH_UNSPECIFIED {
+			return errors.Errorf("method %s of %s does not declare auth", method.MethodName, service.ServiceName)
+		}
+	}
+
+	for _, stream := range service.Streams {
+		if methodAuth("/"+service.ServiceName+"/"+stream.StreamName) == pb.Auth_AUTH_UNSPECIFIED {
+			return errors.Errorf("method %s of %s does not declare auth", stream.StreamName, service.ServiceName)
+		}
+	}
+
+	return nil
+}
+
+// authenticate verifies the access token of private methods and adds its payload to the context.
+// Methods of other services, like the health checks, don't declare auth and are public.
+func authenticate(
+	ctx context.Context, fullMethod string, authorize func(ctx context.Context) (*token.Payload, error),
+) (context.Context, error) {
+	if methodAuth(fullMethod) != pb.Auth_AUTH_PRIVATE {
+		return ctx, nil
+	}
+
+	payload, err := authorize(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	return context.WithValue(ctx, payloadContextKey{}, payload), nil
+}
+
+func GRPCAuth(authorize func(ctx context.Context) (*token.Payload, error)) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		ctx, err = authenticate(ctx, info.FullMethod, authorize)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func GRPCStreamAuth(authorize func(ctx context.Context) (*token.Payload, error)) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		ctx, err := authenticate(stream.Context(), info.FullMethod, authorize)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
@@ -0,0 +1,116 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	updateUserMethod = "/pb.SimpleBank/UpdateUser"
+	loginUserMethod  = "/pb.SimpleBank/LoginUser"
+)
+
+func TestCheckMethodAuth(t *testing.T) {
+	require.NoError(t, checkMethodAuth(pb.SimpleBank_ServiceDesc))
+
+	service := pb.SimpleBank_ServiceDesc
+	service.Methods = append([]grpc.MethodDesc{{MethodName: "Undeclared"}}, service.Methods...)
+	require.Error(t, checkMethodAuth(service))
+
+	service = pb.SimpleBank_ServiceDesc
+	service.Streams = append([]grpc.StreamDesc{{StreamName: "Undeclared"}}, service.Streams...)
+	require.Error(t, checkMethodAuth(service))
+}
+
+func TestAuthenticate(t *testing.T) {
+	server := newTestServer(t)
+
+	accessToken, _, err := server.tokenMaker.CreateToken(1, "alice", time.Minute)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		method        string
+		authorization string
+		checkResult   func(t *testing.T, ctx context.Context, err error)
+	}{
+		{
+			name:   "PrivateWithoutToken",
+			method: updateUserMethod,
+			checkResult: func(t *testing.T, ctx context.Context, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:          "PrivateWithInvalidToken",
+			method:        updateUserMethod,
+			authorization: "bearer invalid",
+			checkResult: func(t *testing.T, ctx context.Context, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:          "PrivateWithToken",
+			method:        updateUserMethod,
+			authorization: fmt.Sprintf("%s %s", authorizationBearer, accessToken),
+			checkResult: func(t *testing.T, ctx context.Context, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "alice", payloadFromContext(ctx).Username)
+			},
+		},
+		{
+			name:   "PublicWithoutToken",
+			method: loginUserMethod,
+			checkResult: func(t *testing.T, ctx context.Context, err error) {
+				require.NoError(t, err)
+				require.Nil(t, payloadFromContext(ctx))
+			},
+		},
+		{
+			name:   "OtherServiceWithoutToken",
+			method: "/grpc.health.v1.Health/Check",
+			checkResult: func(t *testing.T, ctx context.Context, err error) {
+				require.NoError(t, err)
+				require.Nil(t, payloadFromContext(ctx))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(authorizationKey, tc.authorization))
+			}
+
+			ctx, err := authenticate(ctx, tc.method, server.authorizeUser)
+			tc.checkResult(t, ctx, err)
+		})
+	}
+}
+
+func TestInterceptorChainRejectsPrivateMethodsWithoutToken(t *testing.T) {
+	server := newTestServer(t)
+
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+
+		return &pb.UpdateUserResponse{}, nil
+	}
+
+	_, err := server.unaryInterceptor(context.Background(), &pb.UpdateUserRequest{Username: "alice"},
+		&grpc.UnaryServerInfo{FullMethod: updateUserMethod}, handler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.False(t, called)
+}
@@ -15,3 +15,27 @@ func convertUser(user *db.User) *pb.User {
 		CreatedAt:         timestamppb.New(user.CreatedAt),
 	}
 }
+
+func convertAccount(account *db.Account) *pb.Account {
+	return &pb.Account{
+		Id:        account.ID,
+		Number:    account.Number,
+		Owner:     account.Owner,
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+		Type:      account.Type,
+		Nickname:  account.Nickname,
+		CreatedAt: timestamppb.New(account.CreatedAt),
+	}
+}
+
+func convertEntry(entry *db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:          entry.ID,
+		AccountId:   entry.AccountID,
+		Amount:      entry.Amount,
+		Description: entry.Description,
+		Category:    entry.Category,
+		CreatedAt:   timestamppb.New(entry.CreatedAt),
+	}
+}
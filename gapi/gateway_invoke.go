@@ -0,0 +1,86 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// The in-process gateway calls the methods of the gateway server instead of the gRPC server,
+// which pass the calls through the interceptor chain like the gRPC server does.
+
+// invoke calls a unary method of the gRPC server through the interceptor chain,
+// using the generated handler of the method so that the interceptors see the same info as over gRPC.
+func invoke[T proto.Message](ctx context.Context, s *GatewayServer, method string, req proto.Message) (T, error) {
+	var rsp T
+
+	for _, desc := range pb.SimpleBank_ServiceDesc.Methods {
+		if desc.MethodName != method {
+			continue
+		}
+
+		decode := func(in interface{}) error {
+			msg, ok := in.(proto.Message)
+			if !ok {
+				return errors.Errorf("unexpected message type %T", in)
+			}
+
+			proto.Merge(msg, req)
+
+			return nil
+		}
+
+		out, err := desc.Handler(s.GRPCServer, ctx, decode, s.unaryInterceptor)
+		if err != nil {
+			return rsp, err
+		}
+
+		rsp, _ = out.(T)
+
+		return rsp, nil
+	}
+
+	return rsp, status.Errorf(codes.Unimplemented, "method %s not implemented", method)
+}
+
+// serveStream serves a streaming method of the gRPC server to a gateway stream through the interceptor chain.
+func (s *GatewayServer) serveStream(method string, stream grpc.ServerStream) error {
+	for _, desc := range pb.SimpleBank_ServiceDesc.Streams {
+		if desc.StreamName != method {
+			continue
+		}
+
+		info := &grpc.StreamServerInfo{
+			FullMethod:     "/" + pb.SimpleBank_ServiceDesc.ServiceName + "/" + method,
+			IsServerStream: desc.ServerStreams,
+			IsClientStream: desc.ClientStreams,
+		}
+
+		return s.streamInterceptor(s.GRPCServer, stream, info, desc.Handler)
+	}
+
+	return status.Errorf(codes.Unimplemented, "method %s not implemented", method)
+}
+
+func (s *GatewayServer) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	return invoke[*pb.CreateUserResponse](ctx, s, "CreateUser", req)
+}
+
+func (s *GatewayServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	return invoke[*pb.UpdateUserResponse](ctx, s, "UpdateUser", req)
+}
+
+func (s *GatewayServer) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	return invoke[*pb.LoginUserResponse](ctx, s, "LoginUser", req)
+}
+
+func (s *GatewayServer) RevokeSession(
+	ctx context.Context, req *pb.RevokeSessionRequest,
+) (*pb.RevokeSessionResponse, error) {
+	return invoke[*pb.RevokeSessionResponse](ctx, s, "RevokeSession", req)
+}
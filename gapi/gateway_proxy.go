@@ -0,0 +1,115 @@
+package gapi
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"github.com/ifantsai/simple-bank-api/certs"
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// Modes of the gateway.
+const (
+	// GatewayInProcess calls the gRPC methods in the process of the gateway, for single-binary deployments.
+	GatewayInProcess = "in-process"
+	// GatewayProxy proxies the requests to the gRPC server over the network, so that its interceptors run
+	// for HTTP requests as well and the gateway can be scaled separately.
+	GatewayProxy = "proxy"
+)
+
+// dialTarget returns the address to dial a server listening on the address,
+// which is the loopback address if it listens on all addresses.
+func dialTarget(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+
+	return net.JoinHostPort(host, port)
+}
+
+// dialOptions returns the options to dial the gRPC server, over TLS if the servers are given certificates.
+func (s *GatewayServer) dialOptions() ([]grpc.DialOption, error) {
+	if s.certs == nil {
+		return []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, nil
+	}
+
+	rootCAs, err := certs.LoadCertPool(s.config.TLSRootCAFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot load root CA")
+	}
+
+	return []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(s.certs.ClientConfig(rootCAs)))}, nil
+}
+
+// outgoingContext forwards the metadata of a gateway stream and its trace context to the gRPC server.
+func outgoingContext(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return metadata.NewOutgoingContext(ctx, metadata.Join(md, annotateTraceContext(ctx, nil)))
+}
+
+// forwardStream sends the header and messages of a proxied stream to the gateway stream until it ends.
+func forwardStream(src grpc.ClientStream, dst grpc.ServerStream, newMessage func() proto.Message) error {
+	// a stream which fails before sending anything has no header, its error is returned by RecvMsg
+	if header, err := src.Header(); err == nil {
+		_ = dst.SetHeader(header)
+	}
+
+	for {
+		msg := newMessage()
+		if err := src.RecvMsg(msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		if err := dst.SendMsg(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// watchAccountStream serves WatchAccount to a gateway stream in-process or by proxying it to the gRPC server.
+func (s *GatewayServer) watchAccountStream(req *pb.WatchAccountRequest, stream pb.SimpleBank_WatchAccountServer) error {
+	if s.client != nil {
+		events, err := s.client.WatchAccount(outgoingContext(stream.Context()), req)
+		if err != nil {
+			return err
+		}
+
+		return forwardStream(events, stream, func() proto.Message { return &pb.WatchAccountResponse{} })
+	}
+
+	return s.serveStream("WatchAccount", stream)
+}
+
+// exportStatementStream serves ExportStatement to a gateway stream in-process or by proxying it to the gRPC server.
+func (s *GatewayServer) exportStatementStream(
+	req *pb.ExportStatementRequest, stream pb.SimpleBank_ExportStatementServer,
+) error {
+	if s.client != nil {
+		chunks, err := s.client.ExportStatement(outgoingContext(stream.Context()), req)
+		if err != nil {
+			return err
+		}
+
+		return forwardStream(chunks, stream, func() proto.Message { return &httpbody.HttpBody{} })
+	}
+
+	return s.serveStream("ExportStatement", stream)
+}
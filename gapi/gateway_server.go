@@ -6,25 +6,42 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/IfanTsai/go-lib/logger"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/ifantsai/simple-bank-api/certs"
 	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/health"
 	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/ifantsai/simple-bank-api/ratelimit"
 	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/ifantsai/simple-bank-api/watch"
 	"github.com/pkg/errors"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
 // GatewayServer serves HTTP requests for our banking service.
 type GatewayServer struct {
 	*GRPCServer
+	// client calls the gRPC server in proxy mode, it is nil in in-process mode.
+	client pb.SimpleBankClient
 	server *http.Server
 }
 
 // NewGatewayServer creates a new gateway server and setup routing.
-func NewGatewayServer(config util.Config, store db.Store, address string) (*GatewayServer, error) {
-	grpcServer, err := NewGRPCServer(config, store, address)
+func NewGatewayServer(
+	config util.Config, store db.Store, hub *watch.Hub, checker *health.Checker, limiter *ratelimit.Limiter,
+	certs *certs.Reloader, address string,
+) (*GatewayServer, error) {
+	switch config.GatewayMode {
+	case "", GatewayInProcess, GatewayProxy:
+	default:
+		return nil, errors.Errorf("unknown gateway mode %q", config.GatewayMode)
+	}
+
+	grpcServer, err := NewGRPCServer(config, store, hub, checker, limiter, certs, address)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot new grpc server")
 	}
@@ -35,12 +52,9 @@ func NewGatewayServer(config util.Config, store db.Store, address string) (*Gate
 }
 
 // Start runs the gateway server on a specific address.
+// It serves HTTP/2 over TLS if certificates are given, or else cleartext HTTP/2 (h2c) besides HTTP/1.1,
+// e.g. behind a proxy which terminates TLS.
 func (s *GatewayServer) Start() error {
-	jsonLogger := logger.NewJSONLogger(
-		logger.WithFileRotationP("./logs/simple-bank.log"),
-		logger.WithEnableConsole(),
-	)
-
 	grpcMux := runtime.NewServeMux(
 		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 			MarshalOptions: protojson.MarshalOptions{
@@ -50,39 +64,105 @@ func (s *GatewayServer) Start() error {
 				DiscardUnknown: true,
 			},
 		}),
+		runtime.WithMetadata(annotateRoute),
+		runtime.WithMetadata(annotateTraceContext),
+		runtime.WithMetadata(annotateRequestID),
+		runtime.WithErrorHandler(gatewayErrorHandler),
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	if err := pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, s.GRPCServer); err != nil {
+	if s.config.GatewayMode == GatewayProxy {
+		if err := s.registerProxy(ctx, grpcMux); err != nil {
+			return err
+		}
+	} else if err := pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, s); err != nil {
 		return errors.Wrap(err, "cannot register grpc handler")
 	}
 
+	if err := grpcMux.HandlePath(http.MethodGet, statementRoute, s.exportStatement); err != nil {
+		return errors.Wrap(err, "cannot register statement handler")
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
+	mux.HandleFunc("/v1/accounts/watch", s.watchAccountEvents)
+	mux.Handle("/healthz", s.checker.LivenessHandler())
+	mux.Handle("/readyz", s.checker.ReadinessHandler())
 
 	fs := http.FileServer(http.Dir("./doc/"))
 	mux.Handle("/doc/", http.StripPrefix("/doc/", fs))
 	mux.Handle("/swagger/", httpSwagger.Handler(httpSwagger.URL("/doc/swagger/simple_bank.swagger.json")))
 
+	// recovery wraps the mux inside the other middlewares, so that they see panics as Internal errors
+	handler := HTTPRequestID(s.logger)(HTTPTracing(mux)(HTTPLogger(s.logger, s.config.LogBodySampleRatio)(
+		HTTPMetrics(mux)(HTTPRecovery(s.logger)(mux)))))
+
 	server := &http.Server{
 		Addr:              s.address,
-		Handler:           HTTPLogger(jsonLogger)(mux),
+		Handler:           h2c.NewHandler(handler, &http2.Server{}),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	if s.certs != nil {
+		server.Handler = handler
+		server.TLSConfig = s.certs.ServerConfig(false, "h2", "http/1.1")
+	}
+
 	s.server = server
 
 	log.Println("gateway server is listening on", s.address)
 
-	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	var err error
+	if s.certs != nil {
+		err = server.ListenAndServeTLS("", "")
+	} else {
+		err = server.ListenAndServe()
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
 		return errors.Wrap(err, "failed to start gateway server")
 	}
 
 	return nil
 }
 
+// registerProxy registers the handlers which proxy the requests to the gRPC server at GATEWAY_GRPC_TARGET,
+// or at GRPC_SERVER_ADDRESS of this process if it is empty. The connections are closed once the context is done.
+func (s *GatewayServer) registerProxy(ctx context.Context, grpcMux *runtime.ServeMux) error {
+	opts, err := s.dialOptions()
+	if err != nil {
+		return err
+	}
+
+	endpoint := s.config.GatewayGRPCTarget
+	if endpoint == "" {
+		endpoint = dialTarget(s.config.GRPCServerAddress)
+	}
+
+	if err := pb.RegisterSimpleBankHandlerFromEndpoint(ctx, grpcMux, endpoint, opts); err != nil {
+		return errors.Wrap(err, "cannot register grpc proxy handler")
+	}
+
+	// the streams are served by handlers of their own, which call the gRPC server with a client
+	conn, err := grpc.DialContext(ctx, endpoint, opts...)
+	if err != nil {
+		return errors.Wrap(err, "cannot dial grpc server")
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	s.client = pb.NewSimpleBankClient(conn)
+
+	log.Println("gateway server proxies to", endpoint)
+
+	return nil
+}
+
 // Stop stops the gateway server.
 func (s *GatewayServer) Stop(ctx context.Context) error {
 	return errors.Wrap(s.server.Shutdown(ctx), "failed to shutdown gateway server")
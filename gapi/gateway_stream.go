@@ -0,0 +1,111 @@
+package gapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// accessTokenParam authorizes browsers, since EventSource and downloads cannot set the authorization header.
+const accessTokenParam = "access_token"
+
+var gatewayMarshalOptions = protojson.MarshalOptions{UseProtoNames: true}
+
+// gatewayStream is the base of the server streams the gateway serves over plain HTTP,
+// since the in-process gateway doesn't support streaming calls.
+type gatewayStream struct {
+	ctx    context.Context
+	header metadata.MD
+	// req is the request the stream receives once.
+	req proto.Message
+}
+
+func newGatewayStream(r *http.Request, req proto.Message) gatewayStream {
+	return gatewayStream{
+		ctx:    metadata.NewIncomingContext(r.Context(), gatewayMetadata(r)),
+		header: metadata.MD{},
+		req:    req,
+	}
+}
+
+func (s *gatewayStream) Context() context.Context     { return s.ctx }
+func (s *gatewayStream) SendHeader(metadata.MD) error { return nil }
+func (s *gatewayStream) SetTrailer(metadata.MD)       {}
+
+func (s *gatewayStream) RecvMsg(m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if s.req == nil || !ok {
+		return io.EOF
+	}
+
+	proto.Merge(msg, s.req)
+	s.req = nil
+
+	return nil
+}
+
+func (s *gatewayStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+
+	return nil
+}
+
+// contextServerStream passes a context derived by an interceptor to the handler of a stream.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// gatewayMetadata converts the request headers to the metadata expected by the gRPC server.
+func gatewayMetadata(r *http.Request) metadata.MD {
+	// the address of the client is appended to the chain of proxies as done by the gateway for other methods
+	forwardedFor := r.RemoteAddr
+	if chain := r.Header.Get("X-Forwarded-For"); chain != "" {
+		forwardedFor = chain + ", " + r.RemoteAddr
+	}
+
+	md := metadata.Pairs(
+		grpcGatewayUserAgentKey, r.UserAgent(),
+		xForwardedForKey, forwardedFor,
+	)
+
+	if id := requestIDFromContext(r.Context()); id != "" {
+		md.Set(requestIDKey, id)
+	}
+
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		md.Set(authorizationKey, auth)
+	} else if accessToken := r.URL.Query().Get(accessTokenParam); accessToken != "" {
+		md.Set(authorizationKey, strings.Join([]string{authorizationBearer, accessToken}, " "))
+	}
+
+	return md
+}
+
+// writeStatusError writes the error of a stream which hasn't sent anything yet like the gateway does.
+func writeStatusError(w http.ResponseWriter, r *http.Request, err error) {
+	st := status.Convert(withRequestInfo(r.Context(), err))
+	setRetryAfter(w, st)
+
+	data, marshalErr := gatewayMarshalOptions.Marshal(st.Proto())
+	if marshalErr != nil {
+		data = []byte(strconv.Quote(st.Message()))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(runtime.HTTPStatusFromCode(st.Code()))
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,103 @@
+package gapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// httpBodyStream adapts an HTTP response to a server stream of HttpBody chunks.
+type httpBodyStream struct {
+	gatewayStream
+	writer  http.ResponseWriter
+	started bool
+}
+
+func (s *httpBodyStream) Send(body *httpbody.HttpBody) error {
+	return s.SendMsg(body)
+}
+
+func (s *httpBodyStream) SendMsg(m interface{}) error {
+	body, ok := m.(*httpbody.HttpBody)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+
+	if !s.started {
+		s.started = true
+
+		s.writer.Header().Set("Content-Type", body.GetContentType())
+
+		if disposition := s.header.Get(contentDisposition); len(disposition) > 0 {
+			s.writer.Header().Set("Content-Disposition", disposition[0])
+		}
+
+		s.writer.WriteHeader(http.StatusOK)
+	}
+
+	_, err := s.writer.Write(body.GetData())
+
+	return errors.Wrap(err, "failed to write body")
+}
+
+const statementRoute = "/v1/accounts/{account_id}/statement"
+
+// exportStatement serves ExportStatement as a download, e.g.
+// GET /v1/accounts/1/statement?format=csv&start_time=2022-10-01T00:00:00Z&end_time=2022-11-01T00:00:00Z
+func (s *GatewayServer) exportStatement(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+	setRoute(r.Context(), statementRoute)
+
+	accountID, err := strconv.ParseInt(pathParams["account_id"], 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid account_id: %s", pathParams["account_id"]), http.StatusBadRequest)
+
+		return
+	}
+
+	query := r.URL.Query()
+	req := &pb.ExportStatementRequest{
+		AccountId: accountID,
+		Format:    query.Get("format"),
+	}
+
+	for name, field := range map[string]**timestamppb.Timestamp{
+		"start_time": &req.StartTime,
+		"end_time":   &req.EndTime,
+	} {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid %s: %s", name, value), http.StatusBadRequest)
+
+				return
+			}
+
+			*field = timestamppb.New(t)
+		}
+	}
+
+	stream := &httpBodyStream{
+		gatewayStream: newGatewayStream(r, req),
+		writer:        w,
+	}
+
+	err = s.exportStatementStream(req, stream)
+	if err == nil {
+		return
+	}
+
+	if !stream.started {
+		writeStatusError(w, r, err)
+
+		return
+	}
+
+	// abort the response, so that the client doesn't take a truncated statement as complete
+	panic(http.ErrAbortHandler)
+}
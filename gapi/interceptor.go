@@ -0,0 +1,148 @@
+package gapi
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+)
+
+// Names of the interceptors of the chain.
+const (
+	InterceptorRecovery   = "recovery"
+	InterceptorRequestID  = "request_id"
+	InterceptorTracing    = "tracing"
+	InterceptorLogging    = "logging"
+	InterceptorMetrics    = "metrics"
+	InterceptorRateLimit  = "rate_limit"
+	InterceptorAuth       = "auth"
+	InterceptorValidation = "validation"
+)
+
+// DefaultInterceptors is the chain of interceptors if none is configured, from the outermost to the innermost.
+// Recovery comes first so that a panic of any interceptor is recovered, it logs and counts the panics itself.
+// The request ID comes next so that the others log it, and auth comes before validation
+// so that unauthenticated callers learn nothing about the requests.
+var DefaultInterceptors = []string{
+	InterceptorRecovery,
+	InterceptorRequestID,
+	InterceptorTracing,
+	InterceptorLogging,
+	InterceptorMetrics,
+	InterceptorRateLimit,
+	InterceptorAuth,
+	InterceptorValidation,
+}
+
+// interceptor is a concern which applies to unary and streaming RPCs alike.
+type interceptor struct {
+	unary  grpc.UnaryServerInterceptor
+	stream grpc.StreamServerInterceptor
+}
+
+// interceptors returns the interceptors which can be configured in the chain by name.
+func (s *GRPCServer) interceptors() map[string]interceptor {
+	return map[string]interceptor{
+		InterceptorRecovery:   {GRPCRecovery(s.logger), GRPCStreamRecovery(s.logger)},
+		InterceptorRequestID:  {GRPCRequestID(s.logger), GRPCStreamRequestID(s.logger)},
+		InterceptorTracing:    {GRPCTracing(), GRPCStreamTracing()},
+		InterceptorLogging:    {GRPCLogger(s.logger, s.config.LogBodySampleRatio), GRPCStreamLogger(s.logger)},
+		InterceptorMetrics:    {GRPCMetrics(), GRPCStreamMetrics()},
+		InterceptorRateLimit:  {GRPCRateLimit(s.limit), GRPCStreamRateLimit(s.limit)},
+		InterceptorAuth:       {GRPCAuth(s.authorizeUser), GRPCStreamAuth(s.authorizeUser)},
+		InterceptorValidation: {GRPCValidation(), GRPCStreamValidation()},
+	}
+}
+
+// parseInterceptors parses a comma separated chain of interceptors, empty means the default chain.
+func parseInterceptors(chain string) []string {
+	var names []string
+
+	for _, name := range strings.Split(chain, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return DefaultInterceptors
+	}
+
+	return names
+}
+
+// buildChain chains the named interceptors for unary and streaming RPCs.
+// The auth interceptor is required, since the handlers rely on it to authenticate the users,
+// and so is the recovery interceptor as the outermost one, so that no panic crashes the server.
+func (s *GRPCServer) buildChain(names []string) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor, error) {
+	available := s.interceptors()
+	seen := make(map[string]bool, len(names))
+
+	var (
+		unary  []grpc.UnaryServerInterceptor
+		stream []grpc.StreamServerInterceptor
+	)
+
+	for _, name := range names {
+		interceptor, ok := available[name]
+		if !ok {
+			return nil, nil, errors.Errorf("unknown interceptor %q", name)
+		}
+
+		if seen[name] {
+			return nil, nil, errors.Errorf("duplicate interceptor %q", name)
+		}
+
+		seen[name] = true
+		unary = append(unary, interceptor.unary)
+		stream = append(stream, interceptor.stream)
+	}
+
+	if len(names) == 0 || names[0] != InterceptorRecovery {
+		return nil, nil, errors.Errorf("interceptor %q is required as the outermost", InterceptorRecovery)
+	}
+
+	if !seen[InterceptorAuth] {
+		return nil, nil, errors.Errorf("interceptor %q is required", InterceptorAuth)
+	}
+
+	return chainUnary(unary), chainStream(stream), nil
+}
+
+// chainUnary chains unary interceptors, the first one is the outermost.
+// The in-process gateway calls the chain directly, so it cannot rely on grpc.ChainUnaryInterceptor.
+func chainUnary(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		next := handler
+
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// chainStream chains stream interceptors, the first one is the outermost.
+func chainStream(interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		next := handler
+
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(srv interface{}, stream grpc.ServerStream) error {
+				return interceptor(srv, stream, info, inner)
+			}
+		}
+
+		return next(srv, stream)
+	}
+}
@@ -0,0 +1,130 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestChainUnary(t *testing.T) {
+	var calls []string
+
+	record := func(name string) grpc.UnaryServerInterceptor {
+		return func(
+			ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+		) (interface{}, error) {
+			calls = append(calls, name+" before")
+			resp, err := handler(ctx, req)
+			calls = append(calls, name+" after")
+
+			return resp, err
+		}
+	}
+
+	chain := chainUnary([]grpc.UnaryServerInterceptor{record("outer"), record("middle"), record("inner")})
+
+	resp, err := chain(context.Background(), "request", &grpc.UnaryServerInfo{FullMethod: "/test/Method"},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls = append(calls, "handler")
+
+			return req, nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, "request", resp)
+	require.Equal(t, []string{
+		"outer before", "middle before", "inner before", "handler", "inner after", "middle after", "outer after",
+	}, calls)
+}
+
+func TestChainStream(t *testing.T) {
+	var calls []string
+
+	record := func(name string) grpc.StreamServerInterceptor {
+		return func(
+			srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+		) error {
+			calls = append(calls, name+" before")
+			err := handler(srv, stream)
+			calls = append(calls, name+" after")
+
+			return err
+		}
+	}
+
+	chain := chainStream([]grpc.StreamServerInterceptor{record("outer"), record("middle"), record("inner")})
+
+	err := chain(nil, nil, &grpc.StreamServerInfo{FullMethod: "/test/Stream"},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			calls = append(calls, "handler")
+
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"outer before", "middle before", "inner before", "handler", "inner after", "middle after", "outer after",
+	}, calls)
+}
+
+func TestBuildChain(t *testing.T) {
+	testCases := []struct {
+		name  string
+		chain string
+		valid bool
+	}{
+		{
+			name:  "Default",
+			chain: "",
+			valid: true,
+		},
+		{
+			name:  "Minimal",
+			chain: "recovery, auth",
+			valid: true,
+		},
+		{
+			name:  "MissingAuth",
+			chain: "recovery,request_id,logging",
+		},
+		{
+			name:  "MissingRecovery",
+			chain: "request_id,auth,validation",
+		},
+		{
+			name:  "RecoveryNotOutermost",
+			chain: "request_id,recovery,auth",
+		},
+		{
+			name:  "UnknownInterceptor",
+			chain: "recovery,auth,cache",
+		},
+		{
+			name:  "DuplicateInterceptor",
+			chain: "recovery,auth,logging,logging",
+		},
+	}
+
+	server := newTestServer(t)
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			unary, stream, err := server.buildChain(parseInterceptors(tc.chain))
+			if !tc.valid {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NotNil(t, unary)
+			require.NotNil(t, stream)
+		})
+	}
+}
+
+func TestDefaultInterceptors(t *testing.T) {
+	require.Equal(t, DefaultInterceptors, parseInterceptors(" , "))
+	require.Equal(t, InterceptorRecovery, DefaultInterceptors[0])
+}
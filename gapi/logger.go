@@ -2,34 +2,52 @@ package gapi
 
 import (
 	"context"
+	"encoding/json"
+	"math/rand"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/IfanTsai/go-lib/logger"
+	"github.com/ifantsai/simple-bank-api/redact"
+	"github.com/ifantsai/simple-bank-api/util"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
-	body       []byte
+	statusCode  int
+	body        []byte
+	wroteHeader bool
 }
 
 func (w *responseWriter) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
+	w.wroteHeader = true
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
 func (w *responseWriter) Write(b []byte) (int, error) {
 	w.body = b
+	w.wroteHeader = true
 	size, err := w.ResponseWriter.Write(b)
 
 	return size, errors.Wrap(err, "failed to write response")
 }
 
+// Flush sends buffered data to the client, e.g. for Server-Sent Events.
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
 func newResponseWriter(w http.ResponseWriter) *responseWriter {
 	return &responseWriter{
 		ResponseWriter: w,
@@ -37,10 +55,63 @@ func newResponseWriter(w http.ResponseWriter) *responseWriter {
 	}
 }
 
-func HTTPLogger(logger *zap.Logger) func(next http.Handler) http.Handler {
+// maxLoggedBodySize truncates the bodies in logs.
+const maxLoggedBodySize = 4096
+
+// newJSONLogger creates the logger of the servers at the configured level, info by default.
+func newJSONLogger(config util.Config) *zap.Logger {
+	level := logger.WithInfoLevel()
+
+	switch strings.ToLower(config.LogLevel) {
+	case "debug":
+		level = logger.WithDebugLevel()
+	case "warn":
+		level = logger.WithWarnLevel()
+	case "error":
+		level = logger.WithErrorLevel()
+	}
+
+	return logger.NewJSONLogger(
+		logger.WithFileRotationP("./logs/simple-bank.log"),
+		logger.WithEnableConsole(),
+		level,
+	)
+}
+
+// sampleBody decides whether the bodies of a request are logged.
+func sampleBody(ratio float64) bool {
+	return ratio > 0 && (ratio >= 1 || rand.Float64() < ratio) //nolint:gosec
+}
+
+func truncateBody(body []byte) []byte {
+	if len(body) > maxLoggedBodySize {
+		return body[:maxLoggedBodySize]
+	}
+
+	return body
+}
+
+// messageField logs a message with its sensitive fields masked.
+func messageField(key string, m interface{}) zap.Field {
+	message, ok := m.(proto.Message)
+	if !ok {
+		return zap.Skip()
+	}
+
+	data, err := protojson.Marshal(redact.Message(message))
+	if err != nil {
+		return zap.String(key, err.Error())
+	}
+
+	return zap.Any(key, json.RawMessage(truncateBody(data)))
+}
+
+// HTTPLogger logs every request, with the redacted body of a sample of the failed ones.
+func HTTPLogger(logger *zap.Logger, bodySampleRatio float64) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
+			requestLogger := loggerFromContext(r.Context(), logger)
 
 			writer := newResponseWriter(w)
 			next.ServeHTTP(writer, r)
@@ -55,21 +126,29 @@ func HTTPLogger(logger *zap.Logger) func(next http.Handler) http.Handler {
 				zap.String("path", r.URL.Path),
 				zap.Duration("elapsed", elapsed),
 			}
+			fields = append(fields, traceFields(r.Context())...)
 
 			if writer.statusCode != http.StatusOK {
-				logger.With(fields...).Error("HTTP error", zap.ByteString("body", writer.body))
+				if sampleBody(bodySampleRatio) {
+					fields = append(fields, zap.ByteString("body", truncateBody(redact.JSON(writer.body))))
+				}
+
+				requestLogger.With(fields...).Error("HTTP error")
 			} else {
-				logger.With(fields...).Info("HTTP success")
+				requestLogger.With(fields...).Info("HTTP success")
 			}
 		})
 	}
 }
 
-func GRPCLogger(logger *zap.Logger) grpc.UnaryServerInterceptor {
+// GRPCLogger logs every call, with the redacted messages of a sample of the failed ones.
+// The messages of successful calls are only logged at debug level.
+func GRPCLogger(logger *zap.Logger, bodySampleRatio float64) grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
 	) (resp interface{}, err error) {
 		start := time.Now()
+		requestLogger := loggerFromContext(ctx, logger)
 
 		res, err := handler(ctx, req)
 
@@ -87,13 +166,57 @@ func GRPCLogger(logger *zap.Logger) grpc.UnaryServerInterceptor {
 			zap.String("method", info.FullMethod),
 			zap.Duration("elapsed", elapsed),
 		}
+		fields = append(fields, traceFields(ctx)...)
 
 		if err != nil {
-			logger.With(fields...).Error("gRPC error", zap.Error(err))
+			if sampleBody(bodySampleRatio) {
+				fields = append(fields, messageField("request", req))
+			}
+
+			requestLogger.With(fields...).Error("gRPC error", zap.String("error", redact.Text(err.Error())))
 		} else {
-			logger.With(fields...).Info("gRPC success")
+			if requestLogger.Core().Enabled(zap.DebugLevel) && sampleBody(bodySampleRatio) {
+				fields = append(fields, messageField("request", req), messageField("response", res))
+			}
+
+			requestLogger.With(fields...).Info("gRPC success")
 		}
 
 		return res, err
 	}
 }
+
+func GRPCStreamLogger(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		start := time.Now()
+		requestLogger := loggerFromContext(stream.Context(), logger)
+
+		err := handler(srv, stream)
+
+		end := time.Now()
+		elapsed := end.Sub(start)
+
+		statusCode := codes.Unknown
+		if st, ok := status.FromError(err); ok {
+			statusCode = st.Code()
+		}
+
+		fields := []zap.Field{
+			zap.Int("status_code", int(statusCode)),
+			zap.String("status", statusCode.String()),
+			zap.String("method", info.FullMethod),
+			zap.Duration("elapsed", elapsed),
+		}
+		fields = append(fields, traceFields(stream.Context())...)
+
+		if err != nil {
+			requestLogger.With(fields...).Error("gRPC stream error", zap.String("error", redact.Text(err.Error())))
+		} else {
+			requestLogger.With(fields...).Info("gRPC stream success")
+		}
+
+		return err
+	}
+}
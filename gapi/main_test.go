@@ -0,0 +1,36 @@
+package gapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IfanTsai/go-lib/user/token"
+	"github.com/IfanTsai/go-lib/utils/randutils"
+	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestServer creates a gRPC server with the default interceptor chain, which logs nowhere.
+func newTestServer(t *testing.T) *GRPCServer {
+	t.Helper()
+
+	config := util.Config{
+		TokenSymmetricKey:   randutils.RandomString(32),
+		AccessTokenDuration: time.Minute,
+	}
+
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	server := &GRPCServer{
+		config:     config,
+		tokenMaker: tokenMaker,
+		logger:     zap.NewNop(),
+	}
+
+	server.unaryInterceptor, server.streamInterceptor, err = server.buildChain(DefaultInterceptors)
+	require.NoError(t, err)
+
+	return server
+}
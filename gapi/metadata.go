@@ -2,7 +2,10 @@ package gapi
 
 import (
 	"context"
+	"net"
+	"strings"
 
+	"github.com/pkg/errors"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 )
@@ -20,25 +23,107 @@ type Metadata struct {
 
 func (s *GRPCServer) extractMetadata(ctx context.Context) *Metadata {
 	mtdt := &Metadata{}
+
+	var chain []string
+
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
-		if ua := md.Get(grpcGatewayUserAgentKey); len(ua) > 0 {
+		if ua := md.Get(userAgentKey); len(ua) > 0 {
 			mtdt.UserAgent = ua[0]
 		}
 
-		if ua := md.Get(userAgentKey); len(ua) > 0 {
+		// the gateway forwards the user agent of the HTTP client, which is more useful than the one of the gateway
+		if ua := md.Get(grpcGatewayUserAgentKey); len(ua) > 0 {
 			mtdt.UserAgent = ua[0]
 		}
 
-		if ip := md.Get(xForwardedForKey); len(ip) > 0 {
-			mtdt.ClientIP = ip[0]
+		for _, forwardedFor := range md.Get(xForwardedForKey) {
+			for _, addr := range strings.Split(forwardedFor, ",") {
+				chain = append(chain, strings.TrimSpace(addr))
+			}
 		}
 	}
 
+	// requests of the in-process gateway have no peer, the gateway is the last proxy of their chain
 	if p, ok := peer.FromContext(ctx); ok {
-		if ip := p.Addr.String(); len(ip) > 0 {
-			mtdt.ClientIP = ip
-		}
+		chain = append(chain, p.Addr.String())
 	}
 
+	mtdt.ClientIP = s.clientIP(chain)
+
 	return mtdt
 }
+
+// clientIP returns the client of a request forwarded through the chain of addresses, which ends with the address
+// the request was received from. Every proxy appends the address it received the request from, so the chain is
+// walked backwards while the addresses are trusted proxies, since the ones before them may be forged by the client.
+func (s *GRPCServer) clientIP(chain []string) string {
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := hostIP(chain[i])
+		if i == 0 || !s.isTrustedProxy(ip) {
+			return ip
+		}
+	}
+
+	return ""
+}
+
+func (s *GRPCServer) isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range s.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hostIP returns the IP of an address with or without a port.
+func hostIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return addr
+}
+
+// parseTrustedProxies parses comma separated CIDRs or IPs of the proxies whose X-Forwarded-For is trusted,
+// e.g. "127.0.0.0/8,::1,10.0.0.0/8".
+func parseTrustedProxies(s string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+
+	for _, proxy := range strings.Split(s, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, errors.Errorf("invalid trusted proxy %q", proxy)
+			}
+
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+			}
+
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid trusted proxy %q", proxy)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return networks, nil
+}
@@ -0,0 +1,110 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestExtractMetadataClientIP(t *testing.T) {
+	trustedProxies, err := parseTrustedProxies("127.0.0.0/8, ::1, 10.0.0.0/8")
+	require.NoError(t, err)
+
+	server := &GRPCServer{trustedProxies: trustedProxies}
+
+	testCases := []struct {
+		name         string
+		peer         string
+		forwardedFor []string
+		clientIP     string
+	}{
+		{
+			name:     "DirectClient",
+			peer:     "203.0.113.10:5000",
+			clientIP: "203.0.113.10",
+		},
+		{
+			name:         "UntrustedPeer",
+			peer:         "203.0.113.10:5000",
+			forwardedFor: []string{"198.51.100.1"},
+			clientIP:     "203.0.113.10",
+		},
+		{
+			name:         "LoopbackGateway",
+			peer:         "127.0.0.1:5000",
+			forwardedFor: []string{"198.51.100.1"},
+			clientIP:     "198.51.100.1",
+		},
+		{
+			name:         "RemoteGateway",
+			peer:         "10.1.2.3:5000",
+			forwardedFor: []string{"198.51.100.1"},
+			clientIP:     "198.51.100.1",
+		},
+		{
+			name:         "ForgedChain",
+			peer:         "10.1.2.3:5000",
+			forwardedFor: []string{"192.0.2.1, 10.9.9.9, 198.51.100.1"},
+			clientIP:     "198.51.100.1",
+		},
+		{
+			name:         "TrustedLoadBalancer",
+			peer:         "[::1]:5000",
+			forwardedFor: []string{"198.51.100.1, 10.9.9.9"},
+			clientIP:     "198.51.100.1",
+		},
+		{
+			name:         "OnlyProxies",
+			peer:         "10.1.2.3:5000",
+			forwardedFor: []string{"10.9.9.9"},
+			clientIP:     "10.9.9.9",
+		},
+		{
+			name:         "InProcessGateway",
+			forwardedFor: []string{"192.0.2.1, 198.51.100.1:6000"},
+			clientIP:     "198.51.100.1",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			if tc.peer != "" {
+				addr, err := net.ResolveTCPAddr("tcp", tc.peer)
+				require.NoError(t, err)
+
+				ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+			}
+
+			if len(tc.forwardedFor) > 0 {
+				ctx = metadata.NewIncomingContext(ctx, metadata.MD{xForwardedForKey: tc.forwardedFor})
+			}
+
+			require.Equal(t, tc.clientIP, server.extractMetadata(ctx).ClientIP)
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	networks, err := parseTrustedProxies("")
+	require.NoError(t, err)
+	require.Empty(t, networks)
+
+	networks, err = parseTrustedProxies("10.0.0.0/8,192.0.2.1")
+	require.NoError(t, err)
+	require.Len(t, networks, 2)
+	require.True(t, networks[1].Contains(net.ParseIP("192.0.2.1")))
+	require.False(t, networks[1].Contains(net.ParseIP("192.0.2.2")))
+
+	_, err = parseTrustedProxies("10.0.0.0/33")
+	require.Error(t, err)
+
+	_, err = parseTrustedProxies("proxy.local")
+	require.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/ifantsai/simple-bank-api/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type routeKey struct{}
+
+// route is filled in by the handler of a request with the pattern it was routed by.
+type route struct {
+	pattern string
+}
+
+// withRoute returns the route of a request, adding one to its context if it has none yet.
+func withRoute(r *http.Request) (*http.Request, *route) {
+	if requestRoute, ok := r.Context().Value(routeKey{}).(*route); ok {
+		return r, requestRoute
+	}
+
+	requestRoute := &route{}
+
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, requestRoute)), requestRoute
+}
+
+// resolve returns the route pattern of a handled request.
+// Requests not routed by a pattern are routed by the pattern of mux they matched.
+func (rt *route) resolve(mux *http.ServeMux, r *http.Request) string {
+	if rt.pattern == "" {
+		_, rt.pattern = mux.Handler(r)
+	}
+
+	return rt.pattern
+}
+
+// setRoute records the route pattern of the request for its metrics.
+func setRoute(ctx context.Context, pattern string) {
+	if r, ok := ctx.Value(routeKey{}).(*route); ok {
+		r.pattern = pattern
+	}
+}
+
+// annotateRoute records the route pattern of requests handled by the gateway mux.
+func annotateRoute(ctx context.Context, r *http.Request) metadata.MD {
+	if pattern, ok := runtime.HTTPPathPattern(ctx); ok {
+		setRoute(r.Context(), pattern)
+	}
+
+	return nil
+}
+
+// HTTPMetrics records the count and latency of HTTP requests by route pattern.
+func HTTPMetrics(mux *http.ServeMux) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			r, requestRoute := withRoute(r)
+			writer := newResponseWriter(w)
+			next.ServeHTTP(writer, r)
+
+			metrics.ObserveHTTPRequest(r.Method, requestRoute.resolve(mux, r), writer.statusCode, time.Since(start))
+		})
+	}
+}
+
+func GRPCMetrics() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		start := time.Now()
+
+		res, err := handler(ctx, req)
+
+		metrics.ObserveGRPCRequest(info.FullMethod, status.Code(err).String(), time.Since(start))
+
+		return res, err
+	}
+}
+
+func GRPCStreamMetrics() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		start := time.Now()
+
+		err := handler(srv, stream)
+
+		metrics.ObserveGRPCRequest(info.FullMethod, status.Code(err).String(), time.Since(start))
+
+		return err
+	}
+}
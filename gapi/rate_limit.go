@@ -0,0 +1,115 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/ifantsai/simple-bank-api/metrics"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	retryAfterHeader = "Retry-After"
+	retryAfterKey    = "retry-after"
+)
+
+// rateLimitSubject returns who the requests are limited for, the user if the request is authenticated or else the client IP.
+func (s *GRPCServer) rateLimitSubject(ctx context.Context) string {
+	if payload, err := s.authorizeUser(ctx); err == nil {
+		return "user:" + payload.Username
+	}
+
+	return "ip:" + s.extractMetadata(ctx).ClientIP
+}
+
+// retryAfterSeconds rounds up the delay to whole seconds as used by the Retry-After header.
+func retryAfterSeconds(retryAfter time.Duration) int64 {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+
+	return seconds
+}
+
+func rateLimitedError(retryAfter time.Duration) error {
+	statusExhausted := status.New(codes.ResourceExhausted,
+		fmt.Sprintf("too many requests, retry after %ds", retryAfterSeconds(retryAfter)))
+
+	statusDetails, err := statusExhausted.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return statusExhausted.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+// limit rejects a call of the method beyond the rate limit with ResourceExhausted.
+// The delay until the next allowed call is sent in the retry-after header and the RetryInfo of the error.
+func (s *GRPCServer) limit(ctx context.Context, method string) error {
+	if s.limiter == nil {
+		return nil
+	}
+
+	decision, err := s.limiter.Allow(ctx, method, s.rateLimitSubject(ctx))
+	if err != nil {
+		loggerFromContext(ctx, s.logger).Warn("rate limit is not applied", zap.String("method", method), zap.Error(err))
+	}
+
+	if decision.Allowed {
+		return nil
+	}
+
+	metrics.RateLimited(method)
+
+	// the streams of the gateway have no header to set, the gateway sets Retry-After from the RetryInfo instead
+	_ = grpc.SetHeader(ctx, metadata.Pairs(retryAfterKey, strconv.FormatInt(retryAfterSeconds(decision.RetryAfter), 10)))
+
+	return rateLimitedError(decision.RetryAfter)
+}
+
+// setRetryAfter sets the Retry-After header of a response from the RetryInfo of an error.
+func setRetryAfter(w http.ResponseWriter, st *status.Status) {
+	for _, detail := range st.Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+			w.Header().Set(retryAfterHeader, strconv.FormatInt(retryAfterSeconds(retryInfo.GetRetryDelay().AsDuration()), 10))
+
+			return
+		}
+	}
+}
+
+func GRPCRateLimit(limit func(ctx context.Context, method string) error) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		if err := limit(ctx, path.Base(info.FullMethod)); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func GRPCStreamRateLimit(limit func(ctx context.Context, method string) error) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		if err := limit(stream.Context(), path.Base(info.FullMethod)); err != nil {
+			return err
+		}
+
+		return handler(srv, stream)
+	}
+}
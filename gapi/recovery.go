@@ -0,0 +1,98 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/ifantsai/simple-bank-api/metrics"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Servers of the panic metric.
+const (
+	panicServerGRPC = "grpc"
+	panicServerHTTP = "http"
+)
+
+// logPanic logs a recovered panic with the stack of the goroutine and the request ID and trace of the request.
+func logPanic(ctx context.Context, logger *zap.Logger, msg string, r interface{}, fields ...zap.Field) {
+	fields = append(fields, zap.Any("panic", r), zap.String("stack", string(debug.Stack())))
+	fields = append(fields, traceFields(ctx)...)
+
+	loggerFromContext(ctx, logger).Error(msg, fields...)
+}
+
+// recoverPanic turns a panic of a handler into an Internal error, so that it doesn't crash the server.
+func recoverPanic(ctx context.Context, logger *zap.Logger, method string, err *error) {
+	if r := recover(); r != nil {
+		metrics.Panicked(panicServerGRPC)
+		logPanic(ctx, logger, "gRPC panic", r, zap.String("method", method))
+
+		*err = withRequestInfo(ctx, status.Error(codes.Internal, "internal error"))
+	}
+}
+
+// GRPCRecovery recovers the panics of the handler and the other interceptors.
+// As it runs first, it resolves the request ID itself to log it with the panic, and the request ID interceptor reuses it.
+func GRPCRecovery(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		ctx = withRequestID(ctx, logger, incomingRequestID(ctx))
+
+		defer recoverPanic(ctx, logger, info.FullMethod, &err)
+
+		return handler(ctx, req)
+	}
+}
+
+func GRPCStreamRecovery(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) (err error) {
+		ctx := withRequestID(stream.Context(), logger, incomingRequestID(stream.Context()))
+
+		defer recoverPanic(ctx, logger, info.FullMethod, &err)
+
+		return handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// HTTPRecovery turns a panic of a gateway handler into an Internal error.
+// A response which is already started is aborted instead, so that the client doesn't take it as complete,
+// and http.ErrAbortHandler is passed on since handlers panic with it on purpose to abort their response.
+func HTTPRecovery(logger *zap.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writer := newResponseWriter(w)
+
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+
+				if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(rec)
+				}
+
+				metrics.Panicked(panicServerHTTP)
+				logPanic(r.Context(), logger, "HTTP panic", rec,
+					zap.String("method", r.Method), zap.String("path", r.URL.Path))
+
+				if writer.wroteHeader {
+					panic(http.ErrAbortHandler)
+				}
+
+				writeStatusError(writer, r, status.Error(codes.Internal, "internal error"))
+			}()
+
+			next.ServeHTTP(writer, r)
+		})
+	}
+}
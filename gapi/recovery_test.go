@@ -0,0 +1,119 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testServerStream is a server stream without messages, which is enough for the interceptors.
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context     { return s.ctx }
+func (s *testServerStream) SetHeader(metadata.MD) error  { return nil }
+func (s *testServerStream) SendHeader(metadata.MD) error { return nil }
+func (s *testServerStream) SetTrailer(metadata.MD)       {}
+
+// grpcPanics returns the value of simple_bank_panics_total{server="grpc"}.
+func grpcPanics(t *testing.T) float64 {
+	t.Helper()
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "simple_bank_panics_total" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "server" && label.GetValue() == panicServerGRPC {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+// observePanics makes the server log its errors to the returned logs.
+func observePanics(t *testing.T, server *GRPCServer) *observer.ObservedLogs {
+	t.Helper()
+
+	core, logs := observer.New(zap.ErrorLevel)
+	server.logger = zap.New(core)
+
+	var err error
+	server.unaryInterceptor, server.streamInterceptor, err = server.buildChain(DefaultInterceptors)
+	require.NoError(t, err)
+
+	return logs
+}
+
+// requirePanicRequestID checks that the panic is logged with the request ID which the error reports to the client.
+func requirePanicRequestID(t *testing.T, logs *observer.ObservedLogs, err error) string {
+	t.Helper()
+
+	var id string
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RequestInfo); ok {
+			id = info.GetRequestId()
+		}
+	}
+	require.NotEmpty(t, id)
+
+	entries := logs.FilterMessage("gRPC panic").All()
+	require.Len(t, entries, 1)
+	require.Equal(t, id, entries[0].ContextMap()["request_id"])
+
+	return id
+}
+
+func TestRecoveryUnaryPanic(t *testing.T) {
+	server := newTestServer(t)
+	logs := observePanics(t, server)
+	panics := grpcPanics(t)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDKey, "panic-request"))
+
+	resp, err := server.unaryInterceptor(ctx,
+		&pb.LoginUserRequest{Username: "alice", Password: "secret123"},
+		&grpc.UnaryServerInfo{FullMethod: loginUserMethod},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			panic("handler failed")
+		})
+	require.Nil(t, resp)
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.Equal(t, panics+1, grpcPanics(t))
+	require.Equal(t, "panic-request", requirePanicRequestID(t, logs, err))
+}
+
+func TestRecoveryStreamPanic(t *testing.T) {
+	server := newTestServer(t)
+	logs := observePanics(t, server)
+	panics := grpcPanics(t)
+
+	err := server.streamInterceptor(nil, &testServerStream{ctx: context.Background()},
+		&grpc.StreamServerInfo{FullMethod: "/grpc.health.v1.Health/Watch", IsServerStream: true},
+		func(srv interface{}, stream grpc.ServerStream) error {
+			panic("handler failed")
+		})
+	require.Equal(t, codes.Internal, status.Code(err))
+	require.Equal(t, panics+1, grpcPanics(t))
+	requirePanicRequestID(t, logs, err)
+}
@@ -0,0 +1,164 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	requestIDKey    = "x-request-id"
+)
+
+// validRequestID guards the logs against IDs which aren't identifiers, they are replaced by a generated one.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type (
+	requestIDContextKey struct{}
+	loggerContextKey    struct{}
+)
+
+// requestID returns the ID of a request accepted from the client, or a new one.
+func requestID(accepted string) string {
+	if validRequestID.MatchString(accepted) {
+		return accepted
+	}
+
+	return uuid.NewString()
+}
+
+// withRequestID adds the request ID and a logger of the request to the context.
+func withRequestID(ctx context.Context, logger *zap.Logger, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDContextKey{}, id)
+
+	return context.WithValue(ctx, loggerContextKey{}, logger.With(zap.String("request_id", id)))
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+
+	return id
+}
+
+// loggerFromContext returns the logger of the request, which logs its request ID, or the given logger.
+func loggerFromContext(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if requestLogger, ok := ctx.Value(loggerContextKey{}).(*zap.Logger); ok {
+		return requestLogger
+	}
+
+	return logger
+}
+
+// withRequestInfo adds the request ID to the details of an error status, so that clients can report it.
+// Errors proxied from the gRPC server already have it.
+func withRequestInfo(ctx context.Context, err error) error {
+	id := requestIDFromContext(ctx)
+	if err == nil || id == "" {
+		return err
+	}
+
+	st := status.Convert(err)
+	for _, detail := range st.Details() {
+		if _, ok := detail.(*errdetails.RequestInfo); ok {
+			return err
+		}
+	}
+
+	st, detailsErr := st.WithDetails(&errdetails.RequestInfo{RequestId: id})
+	if detailsErr != nil {
+		return err
+	}
+
+	return st.Err()
+}
+
+// annotateRequestID forwards the request ID of gateway requests as gRPC metadata.
+func annotateRequestID(ctx context.Context, r *http.Request) metadata.MD {
+	if id := requestIDFromContext(r.Context()); id != "" {
+		return metadata.Pairs(requestIDKey, id)
+	}
+
+	return nil
+}
+
+// gatewayErrorHandler writes errors like the default handler, with the request ID in the details
+// and the Retry-After header of rate limited requests.
+func gatewayErrorHandler(
+	ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error,
+) {
+	setRetryAfter(w, status.Convert(err))
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, withRequestInfo(r.Context(), err))
+}
+
+// HTTPRequestID accepts the X-Request-ID of a request or generates one, and echoes it in the response headers.
+// It must run before the other middlewares so that they log the request ID.
+func HTTPRequestID(logger *zap.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := requestID(r.Header.Get(requestIDHeader))
+			w.Header().Set(requestIDHeader, id)
+
+			next.ServeHTTP(w, r.WithContext(withRequestID(r.Context(), logger, id)))
+		})
+	}
+}
+
+// incomingRequestID returns the request ID already resolved by the recovery interceptor,
+// else the one of the incoming metadata, or a new one.
+func incomingRequestID(ctx context.Context) string {
+	if id := requestIDFromContext(ctx); id != "" {
+		return id
+	}
+
+	var accepted string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDKey); len(ids) > 0 {
+			accepted = ids[0]
+		}
+	}
+
+	return requestID(accepted)
+}
+
+func GRPCRequestID(logger *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		id := incomingRequestID(ctx)
+		ctx = withRequestID(ctx, logger, id)
+
+		if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id)); err != nil {
+			loggerFromContext(ctx, logger).Warn("cannot send request ID", zap.Error(err))
+		}
+
+		res, err := handler(ctx, req)
+
+		return res, withRequestInfo(ctx, err)
+	}
+}
+
+func GRPCStreamRequestID(logger *zap.Logger) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		id := incomingRequestID(stream.Context())
+		ctx := withRequestID(stream.Context(), logger, id)
+
+		if err := stream.SetHeader(metadata.Pairs(requestIDKey, id)); err != nil {
+			loggerFromContext(ctx, logger).Warn("cannot send request ID", zap.Error(err))
+		}
+
+		err := handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+
+		return withRequestInfo(ctx, err)
+	}
+}
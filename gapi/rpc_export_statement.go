@@ -0,0 +1,115 @@
+package gapi
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/ifantsai/simple-bank-api/statement"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// statementChunkSize is the size of the chunks a statement is streamed in.
+	statementChunkSize = 32 * 1024
+	contentDisposition = "content-disposition"
+)
+
+// ExportStatement streams the statement of an account in the requested format.
+// The file name is sent in the content-disposition header.
+func (s *GRPCServer) ExportStatement(req *pb.ExportStatementRequest, stream pb.SimpleBank_ExportStatementServer) error {
+	ctx := stream.Context()
+
+	payload := payloadFromContext(ctx)
+
+	account, err := s.store.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		errorCode := codes.Internal
+		if errors.Is(errors.Cause(err), sql.ErrNoRows) {
+			errorCode = codes.NotFound
+		}
+
+		return status.Errorf(errorCode, "failed to get account, %s", err)
+	}
+
+	if account.Owner != payload.Username {
+		return status.Errorf(codes.PermissionDenied, "cannot export statement of account %d", req.GetAccountId())
+	}
+
+	export := statement.Statement{
+		Account:     account,
+		StartTime:   req.GetStartTime().AsTime(),
+		EndTime:     req.GetEndTime().AsTime(),
+		GeneratedAt: time.Now(),
+	}
+
+	err = stream.SetHeader(metadata.Pairs(contentDisposition,
+		fmt.Sprintf("attachment; filename=%q", export.FileName(req.GetFormat()))))
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriterSize(&httpBodyWriter{
+		stream:      stream,
+		contentType: statement.ContentType(req.GetFormat()),
+	}, statementChunkSize)
+
+	if err := statement.Export(ctx, s.store, export, req.GetFormat(), writer); err != nil {
+		return status.Errorf(codes.Internal, "failed to export statement: %s", err)
+	}
+
+	if err := writer.Flush(); err != nil {
+		return status.Errorf(codes.Internal, "failed to send statement: %s", err)
+	}
+
+	return nil
+}
+
+// httpBodyWriter sends every write as a chunk of the body.
+type httpBodyWriter struct {
+	stream      pb.SimpleBank_ExportStatementServer
+	contentType string
+}
+
+func (w *httpBodyWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	if err := w.stream.Send(&httpbody.HttpBody{ContentType: w.contentType, Data: data}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func validateExportStatementRequest(req *pb.ExportStatementRequest) []*BadRequestFieldViolation {
+	var violations []*BadRequestFieldViolation
+
+	if req.GetAccountId() < 1 {
+		violations = append(violations, fieldViolation("account_id", errors.New("must be positive")))
+	}
+
+	if !statement.IsSupportedFormat(req.GetFormat()) {
+		violations = append(violations, fieldViolation("format", errors.New("must be csv, ofx, qfx or pdf")))
+	}
+
+	if req.StartTime == nil {
+		violations = append(violations, fieldViolation("start_time", errors.New("is required")))
+	}
+
+	if req.EndTime == nil {
+		violations = append(violations, fieldViolation("end_time", errors.New("is required")))
+	}
+
+	if req.StartTime != nil && req.EndTime != nil && !req.GetEndTime().AsTime().After(req.GetStartTime().AsTime()) {
+		violations = append(violations, fieldViolation("end_time", errors.New("must be after start_time")))
+	}
+
+	return violations
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/metrics"
 	"github.com/ifantsai/simple-bank-api/pb"
 	"github.com/ifantsai/simple-bank-api/util"
 	"github.com/ifantsai/simple-bank-api/validator"
@@ -15,22 +16,20 @@ import (
 )
 
 func (s *GRPCServer) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
-	violations := validateLoginUserRequest(req)
-	if len(violations) != 0 {
-		return nil, invalidParameters(violations)
-	}
-
 	user, err := s.store.GetUser(ctx, req.GetUsername())
 	if err != nil {
 		errorCode := codes.Internal
 		if errors.Is(errors.Cause(err), sql.ErrNoRows) {
 			errorCode = codes.NotFound
+			metrics.LoginFailed(metrics.LoginFailureNotFound)
 		}
 
 		return nil, status.Errorf(errorCode, "failed to get user, %s", err)
 	}
 
 	if err = util.CheckPassword(req.GetPassword(), user.HashedPassword); err != nil {
+		metrics.LoginFailed(metrics.LoginFailureWrongPassword)
+
 		return nil, status.Errorf(codes.Unauthenticated, "failed to check password, %s", err)
 	}
 
@@ -0,0 +1,65 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/ifantsai/simple-bank-api/validator"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *GRPCServer) RevokeSession(
+	ctx context.Context, req *pb.RevokeSessionRequest,
+) (*pb.RevokeSessionResponse, error) {
+	payload := payloadFromContext(ctx)
+
+	sessionID := uuid.MustParse(req.GetSessionId())
+
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		errorCode := codes.Internal
+		if errors.Is(errors.Cause(err), sql.ErrNoRows) {
+			errorCode = codes.NotFound
+		}
+
+		return nil, status.Errorf(errorCode, "failed to get session, %s", err)
+	}
+
+	if session.Username != payload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "cannot revoke session %s", req.GetSessionId())
+	}
+
+	metadata := s.extractMetadata(ctx)
+
+	session, err = s.store.RevokeSessionTx(ctx, db.RevokeSessionTxParams{
+		ID: sessionID,
+		Audit: db.AuditMeta{
+			Actor:    payload.Username,
+			ClientIP: metadata.ClientIP,
+		},
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke session, %s", err)
+	}
+
+	return &pb.RevokeSessionResponse{
+		SessionId: session.ID.String(),
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+	}, nil
+}
+
+func validateRevokeSessionRequest(req *pb.RevokeSessionRequest) []*BadRequestFieldViolation {
+	var violations []*BadRequestFieldViolation
+
+	if err := validator.ValidateUUID(req.GetSessionId()); err != nil {
+		violations = append(violations, fieldViolation("session_id", err))
+	}
+
+	return violations
+}
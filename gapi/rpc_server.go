@@ -5,13 +5,19 @@ import (
 	"log"
 	"net"
 
-	"github.com/IfanTsai/go-lib/logger"
 	"github.com/IfanTsai/go-lib/user/token"
+	"github.com/ifantsai/simple-bank-api/certs"
 	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/health"
 	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/ifantsai/simple-bank-api/ratelimit"
 	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/ifantsai/simple-bank-api/watch"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -21,37 +27,76 @@ type GRPCServer struct {
 	config     util.Config
 	store      db.Store
 	tokenMaker token.Maker
-	server     *grpc.Server
-	address    string
+	hub        *watch.Hub
+	checker    *health.Checker
+	limiter    *ratelimit.Limiter
+	certs      *certs.Reloader
+	logger     *zap.Logger
+	// trustedProxies are the networks of the proxies whose X-Forwarded-For is trusted to find the client IP.
+	trustedProxies []*net.IPNet
+	// unaryInterceptor and streamInterceptor are the configured interceptor chains,
+	// which the in-process gateway applies as well.
+	unaryInterceptor  grpc.UnaryServerInterceptor
+	streamInterceptor grpc.StreamServerInterceptor
+	server            *grpc.Server
+	address           string
 }
 
 // NewGRPCServer creates a new gRPC server and setup routing.
-func NewGRPCServer(config util.Config, store db.Store, address string) (*GRPCServer, error) {
+func NewGRPCServer(
+	config util.Config, store db.Store, hub *watch.Hub, checker *health.Checker, limiter *ratelimit.Limiter,
+	certs *certs.Reloader, address string,
+) (*GRPCServer, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, errors.Wrap(err, "cannot create token")
 	}
+
+	if err := checkMethodAuth(pb.SimpleBank_ServiceDesc); err != nil {
+		return nil, err
+	}
+
+	trustedProxies, err := parseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	server := &GRPCServer{
-		config:     config,
-		store:      store,
-		address:    address,
-		tokenMaker: tokenMaker,
+		config:         config,
+		store:          store,
+		address:        address,
+		tokenMaker:     tokenMaker,
+		hub:            hub,
+		checker:        checker,
+		limiter:        limiter,
+		certs:          certs,
+		logger:         newJSONLogger(config),
+		trustedProxies: trustedProxies,
+	}
+
+	server.unaryInterceptor, server.streamInterceptor, err = server.buildChain(parseInterceptors(config.GRPCInterceptors))
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot build interceptor chain")
 	}
 
 	return server, nil
 }
 
 // Start runs the gRPC server on a specific address.
+// It serves TLS if certificates are given, and requires client certificates if a client CA is configured.
 func (s *GRPCServer) Start() error {
-	jsonLogger := logger.NewJSONLogger(
-		logger.WithFileRotationP("./logs/simple-bank.log"),
-		logger.WithEnableConsole(),
-	)
-
-	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(GRPCLogger(jsonLogger)),
-	)
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(s.unaryInterceptor),
+		grpc.StreamInterceptor(s.streamInterceptor),
+	}
+
+	if s.certs != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.certs.ServerConfig(s.config.TLSClientCAFile != "", "h2"))))
+	}
+
+	grpcServer := grpc.NewServer(opts...)
 	pb.RegisterSimpleBankServer(grpcServer, s)
+	grpc_health_v1.RegisterHealthServer(grpcServer, s.checker.GRPCHealthServer())
 	reflection.Register(grpcServer)
 
 	listener, err := net.Listen("tcp", s.address)
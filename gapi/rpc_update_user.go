@@ -15,15 +15,7 @@ import (
 )
 
 func (s *GRPCServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
-	payload, err := s.authorizeUser(ctx)
-	if err != nil {
-		return nil, unauthenticatedError(err)
-	}
-
-	violations := validateUpdateUserRequest(req)
-	if len(violations) != 0 {
-		return nil, invalidParameters(violations)
-	}
+	payload := payloadFromContext(ctx)
 
 	if payload.Username != req.Username {
 		return nil, status.Errorf(codes.PermissionDenied, "cannot update user %s", req.Username)
@@ -58,7 +50,15 @@ func (s *GRPCServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		}
 	}
 
-	user, err := s.store.UpdateUser(ctx, arg)
+	metadata := s.extractMetadata(ctx)
+
+	user, err := s.store.UpdateUserTx(ctx, db.UpdateUserTxParams{
+		UpdateUserParams: arg,
+		Audit: db.AuditMeta{
+			Actor:    payload.Username,
+			ClientIP: metadata.ClientIP,
+		},
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, status.Errorf(codes.NotFound, "user %s not found", req.GetUsername())
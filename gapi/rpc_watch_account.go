@@ -0,0 +1,126 @@
+package gapi
+
+import (
+	"database/sql"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxWatchedAccounts is the maximum number of accounts watched by a stream.
+const maxWatchedAccounts = 100
+
+// WatchAccount sends the current state of the accounts and then every entry posted to them.
+// The stream ends with Unavailable when changes may have been missed, and the client should watch again.
+func (s *GRPCServer) WatchAccount(req *pb.WatchAccountRequest, stream pb.SimpleBank_WatchAccountServer) error {
+	ctx := stream.Context()
+
+	payload := payloadFromContext(ctx)
+
+	accounts, err := s.watchedAccounts(stream, payload.Username, req.GetAccountIds())
+	if err != nil {
+		return err
+	}
+
+	accountIDs := make([]int64, 0, len(accounts))
+	for _, account := range accounts {
+		accountIDs = append(accountIDs, account.ID)
+	}
+
+	// watch before reading the current state, so that no change is missed in between
+	watcher := s.hub.Watch(accountIDs...)
+	defer watcher.Close()
+
+	for i := range accounts {
+		account, err := s.store.GetAccount(ctx, accounts[i].ID)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to get account: %s", err)
+		}
+
+		if err := stream.Send(&pb.WatchAccountResponse{Account: convertAccount(&account)}); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case change, ok := <-watcher.C:
+			if !ok {
+				return status.Error(codes.Unavailable, "account changes may have been missed, watch again")
+			}
+
+			err := stream.Send(&pb.WatchAccountResponse{
+				Account: convertAccount(&change.Account),
+				Entry:   convertEntry(&change.Entry),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchedAccounts returns the requested accounts, or all accounts of the user if none is requested.
+func (s *GRPCServer) watchedAccounts(
+	stream pb.SimpleBank_WatchAccountServer, username string, accountIDs []int64,
+) ([]db.Account, error) {
+	ctx := stream.Context()
+
+	if len(accountIDs) == 0 {
+		accounts, err := s.store.ListAccounts(ctx, db.ListAccountsParams{
+			Owner: username,
+			Limit: maxWatchedAccounts,
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
+		}
+
+		return accounts, nil
+	}
+
+	accounts := make([]db.Account, 0, len(accountIDs))
+
+	for _, accountID := range accountIDs {
+		account, err := s.store.GetAccount(ctx, accountID)
+		if err != nil {
+			errorCode := codes.Internal
+			if errors.Is(errors.Cause(err), sql.ErrNoRows) {
+				errorCode = codes.NotFound
+			}
+
+			return nil, status.Errorf(errorCode, "failed to get account %d, %s", accountID, err)
+		}
+
+		if account.Owner != username {
+			return nil, status.Errorf(codes.PermissionDenied, "cannot watch account %d", accountID)
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+func validateWatchAccountRequest(req *pb.WatchAccountRequest) []*BadRequestFieldViolation {
+	var violations []*BadRequestFieldViolation
+
+	if len(req.GetAccountIds()) > maxWatchedAccounts {
+		violations = append(violations, fieldViolation("account_ids",
+			errors.Errorf("must contain at most %d accounts", maxWatchedAccounts)))
+	}
+
+	for _, accountID := range req.GetAccountIds() {
+		if accountID < 1 {
+			violations = append(violations, fieldViolation("account_ids", errors.New("must be positive")))
+
+			break
+		}
+	}
+
+	return violations
+}
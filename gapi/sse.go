@@ -0,0 +1,118 @@
+package gapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// sseStream adapts a Server-Sent Events response to the server stream of WatchAccount.
+type sseStream struct {
+	gatewayStream
+	writer  http.ResponseWriter
+	flusher http.Flusher
+	started bool
+}
+
+func (s *sseStream) Send(rsp *pb.WatchAccountResponse) error {
+	return s.SendMsg(rsp)
+}
+
+func (s *sseStream) SendMsg(m interface{}) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return errors.Errorf("unexpected message type %T", m)
+	}
+
+	data, err := gatewayMarshalOptions.Marshal(msg)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event")
+	}
+
+	s.start()
+
+	if _, err := fmt.Fprintf(s.writer, "data: %s\n\n", data); err != nil {
+		return errors.Wrap(err, "failed to write event")
+	}
+
+	s.flusher.Flush()
+
+	return nil
+}
+
+// start writes the headers of the event stream before the first event.
+func (s *sseStream) start() {
+	if s.started {
+		return
+	}
+
+	s.started = true
+
+	header := s.writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	s.writer.WriteHeader(http.StatusOK)
+}
+
+// watchAccountEvents serves WatchAccount as Server-Sent Events, e.g.
+// GET /v1/accounts/watch?account_ids=1&account_ids=2&access_token=...
+// An error ends the stream with an "error" event once events were sent.
+func (s *GatewayServer) watchAccountEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+
+		return
+	}
+
+	req := &pb.WatchAccountRequest{}
+
+	for _, value := range r.URL.Query()["account_ids"] {
+		accountID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid account_ids: %s", value), http.StatusBadRequest)
+
+			return
+		}
+
+		req.AccountIds = append(req.AccountIds, accountID)
+	}
+
+	stream := &sseStream{
+		gatewayStream: newGatewayStream(r, req),
+		writer:        w,
+		flusher:       flusher,
+	}
+
+	err := s.watchAccountStream(req, stream)
+	if err == nil {
+		return
+	}
+
+	if !stream.started {
+		writeStatusError(w, r, err)
+
+		return
+	}
+
+	data, err := gatewayMarshalOptions.Marshal(status.Convert(withRequestInfo(r.Context(), err)).Proto())
+	if err != nil {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+	flusher.Flush()
+}
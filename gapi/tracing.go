@@ -0,0 +1,151 @@
+package gapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var tracer = otel.Tracer("github.com/ifantsai/simple-bank-api/gapi")
+
+// metadataCarrier carries the trace context in gRPC metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// annotateTraceContext propagates the trace context of gateway requests into the gRPC metadata.
+func annotateTraceContext(ctx context.Context, r *http.Request) metadata.MD {
+	md := metadata.MD{}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+
+	return md
+}
+
+// traceFields returns the log fields of the trace of a request, if it is traced.
+func traceFields(ctx context.Context) []zap.Field {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return nil
+	}
+
+	return []zap.Field{
+		zap.String("trace_id", spanContext.TraceID().String()),
+		zap.String("span_id", spanContext.SpanID().String()),
+	}
+}
+
+// HTTPTracing creates a server span for every HTTP request, continuing the trace of the W3C trace context headers.
+// The span is named by the route pattern of the request.
+func HTTPTracing(mux *http.ServeMux) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, requestRoute := withRoute(r)
+
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, "HTTP "+r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPMethodKey.String(r.Method),
+					semconv.HTTPTargetKey.String(r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			writer := newResponseWriter(w)
+			next.ServeHTTP(writer, r.WithContext(ctx))
+
+			pattern := requestRoute.resolve(mux, r)
+			span.SetName(r.Method + " " + pattern)
+			span.SetAttributes(semconv.HTTPRouteKey.String(pattern))
+			span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(writer.statusCode)...)
+			span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(writer.statusCode, trace.SpanKindServer))
+		})
+	}
+}
+
+// startRPCSpan creates a server span for a gRPC call, continuing the trace of the incoming metadata.
+func startRPCSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	name := strings.TrimPrefix(fullMethod, "/")
+	service, method, _ := strings.Cut(name, "/")
+
+	return tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			semconv.RPCServiceKey.String(service),
+			semconv.RPCMethodKey.String(method),
+		),
+	)
+}
+
+func endRPCSpan(span trace.Span, err error) {
+	st := status.Convert(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(st.Code())))
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, st.Message())
+	}
+
+	span.End()
+}
+
+func GRPCTracing() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		ctx, span := startRPCSpan(ctx, info.FullMethod)
+
+		res, err := handler(ctx, req)
+
+		endRPCSpan(span, err)
+
+		return res, err
+	}
+}
+
+func GRPCStreamTracing() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		ctx, span := startRPCSpan(stream.Context(), info.FullMethod)
+
+		err := handler(srv, &contextServerStream{ServerStream: stream, ctx: ctx})
+
+		endRPCSpan(span, err)
+
+		return err
+	}
+}
@@ -0,0 +1,67 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/ifantsai/simple-bank-api/pb"
+	"google.golang.org/grpc"
+)
+
+// validateRequest validates the fields of a request, the requests of other services aren't validated.
+func validateRequest(req interface{}) error {
+	var violations []*BadRequestFieldViolation
+
+	switch req := req.(type) {
+	case *pb.CreateUserRequest:
+		violations = validateCreateUserRequest(req)
+	case *pb.UpdateUserRequest:
+		violations = validateUpdateUserRequest(req)
+	case *pb.LoginUserRequest:
+		violations = validateLoginUserRequest(req)
+	case *pb.RevokeSessionRequest:
+		violations = validateRevokeSessionRequest(req)
+	case *pb.WatchAccountRequest:
+		violations = validateWatchAccountRequest(req)
+	case *pb.ExportStatementRequest:
+		violations = validateExportStatementRequest(req)
+	}
+
+	if len(violations) != 0 {
+		return invalidParameters(violations)
+	}
+
+	return nil
+}
+
+// validatingServerStream validates the requests a handler receives from a stream.
+type validatingServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return validateRequest(m)
+}
+
+func GRPCValidation() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		if err := validateRequest(req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func GRPCStreamValidation() grpc.StreamServerInterceptor {
+	return func(
+		srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+	) error {
+		return handler(srv, &validatingServerStream{ServerStream: stream})
+	}
+}
@@ -0,0 +1,248 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// checkInterval is how often the serving status of the gRPC health service is updated.
+	checkInterval = 5 * time.Second
+	// checkTimeout bounds the checks of a readiness probe.
+	checkTimeout = 2 * time.Second
+)
+
+// ErrDraining fails the readiness while the server shuts down.
+var ErrDraining = errors.New("server is shutting down")
+
+// Check is a dependency which must be healthy for the server to be ready.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// DatabaseCheck checks that the database is reachable.
+func DatabaseCheck(conn *sql.DB) Check {
+	return Check{
+		Name: "database",
+		Check: func(ctx context.Context) error {
+			return errors.Wrap(conn.PingContext(ctx), "cannot ping database")
+		},
+	}
+}
+
+// MigrationCheck checks that the database schema is clean and at least at the version the server was migrated to.
+// A newer version is fine, since it is applied by a newer release during a rolling update.
+func MigrationCheck(conn *sql.DB, version uint) Check {
+	return Check{
+		Name: "migration",
+		Check: func(ctx context.Context) error {
+			var (
+				current uint
+				dirty   bool
+			)
+
+			err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&current, &dirty)
+			if err != nil {
+				return errors.Wrap(err, "cannot get migration version")
+			}
+
+			if dirty {
+				return errors.Errorf("migration %d is dirty", current)
+			}
+
+			if current < version {
+				return errors.Errorf("database is at migration %d, expected %d", current, version)
+			}
+
+			return nil
+		},
+	}
+}
+
+// Checker reports the liveness and readiness of the server over HTTP and the gRPC health protocol.
+// It becomes not ready once it drains, so that load balancers stop sending requests before the servers stop.
+type Checker struct {
+	checks     []Check
+	drainDelay time.Duration
+	draining   atomic.Bool
+	grpcHealth *grpchealth.Server
+	services   []string
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// NewChecker creates a new checker of the given dependencies.
+// The gRPC health service reports the overall status and the status of every service.
+func NewChecker(drainDelay time.Duration, services []string, checks ...Check) *Checker {
+	return &Checker{
+		checks:     checks,
+		drainDelay: drainDelay,
+		grpcHealth: grpchealth.NewServer(),
+		services:   services,
+		done:       make(chan struct{}),
+	}
+}
+
+// GRPCHealthServer returns the grpc.health.v1 service to register on gRPC servers.
+func (c *Checker) GRPCHealthServer() grpc_health_v1.HealthServer {
+	return c.grpcHealth
+}
+
+// Ready runs the checks and returns the errors by check name, a nil error means the check passed.
+func (c *Checker) Ready(ctx context.Context) (bool, map[string]error) {
+	results := make(map[string]error, len(c.checks)+1)
+	ready := true
+
+	if c.draining.Load() {
+		results["shutdown"] = ErrDraining
+		ready = false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	for _, check := range c.checks {
+		err := check.Check(ctx)
+		if err != nil {
+			ready = false
+		}
+
+		results[check.Name] = err
+	}
+
+	return ready, results
+}
+
+// Start updates the serving status of the gRPC health service until it is stopped.
+func (c *Checker) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	defer close(c.done)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		c.updateServingStatus(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Checker) updateServingStatus(ctx context.Context) {
+	ready, results := c.Ready(ctx)
+
+	status := grpc_health_v1.HealthCheckResponse_SERVING
+	if !ready {
+		status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+
+		for name, err := range results {
+			if err != nil && !errors.Is(err, ErrDraining) {
+				log.Printf("health check %s failed: %s", name, err)
+			}
+		}
+	}
+
+	// the gRPC health server keeps reporting not serving once it was shut down
+	c.grpcHealth.SetServingStatus("", status)
+
+	for _, service := range c.services {
+		c.grpcHealth.SetServingStatus(service, status)
+	}
+}
+
+// Drain fails the readiness and waits for the drain delay, so that load balancers notice before the servers stop.
+func (c *Checker) Drain(ctx context.Context) error {
+	c.draining.Store(true)
+	c.grpcHealth.Shutdown()
+
+	log.Println("draining for", c.drainDelay)
+
+	timer := time.NewTimer(c.drainDelay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop stops updating the serving status.
+func (c *Checker) Stop(ctx context.Context) error {
+	if c.cancel == nil {
+		return nil
+	}
+
+	c.cancel()
+
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func writeResponse(w http.ResponseWriter, statusCode int, rsp response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(rsp)
+}
+
+// LivenessHandler serves /healthz, it only reports that the process serves HTTP requests,
+// so that an unavailable database doesn't get the server restarted.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, http.StatusOK, response{Status: "ok"})
+	})
+}
+
+// ReadinessHandler serves /readyz with the result of every check.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ready, results := c.Ready(r.Context())
+
+		rsp := response{
+			Status: "ok",
+			Checks: make(map[string]string, len(results)),
+		}
+
+		for name, err := range results {
+			rsp.Checks[name] = "ok"
+			if err != nil {
+				rsp.Checks[name] = err.Error()
+			}
+		}
+
+		statusCode := http.StatusOK
+		if !ready {
+			rsp.Status = "not ready"
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		writeResponse(w, statusCode, rsp)
+	})
+}
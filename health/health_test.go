@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func passingCheck(name string) Check {
+	return Check{Name: name, Check: func(ctx context.Context) error { return nil }}
+}
+
+func failingCheck(name string) Check {
+	return Check{Name: name, Check: func(ctx context.Context) error { return errors.New("connection refused") }}
+}
+
+func readyz(t *testing.T, checker *Checker) (int, response) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	checker.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var rsp response
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&rsp))
+
+	return recorder.Code, rsp
+}
+
+func servingStatus(t *testing.T, checker *Checker, service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	rsp, err := checker.GRPCHealthServer().Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+
+	return rsp.GetStatus()
+}
+
+func TestReadiness(t *testing.T) {
+	checker := NewChecker(0, nil, passingCheck("database"), passingCheck("migration"))
+
+	code, rsp := readyz(t, checker)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, response{Status: "ok", Checks: map[string]string{"database": "ok", "migration": "ok"}}, rsp)
+
+	checker = NewChecker(0, nil, failingCheck("database"), passingCheck("migration"))
+
+	code, rsp = readyz(t, checker)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "not ready", rsp.Status)
+	require.Equal(t, "connection refused", rsp.Checks["database"])
+	require.Equal(t, "ok", rsp.Checks["migration"])
+}
+
+func TestLiveness(t *testing.T) {
+	checker := NewChecker(0, nil, failingCheck("database"))
+
+	recorder := httptest.NewRecorder()
+	checker.LivenessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestGRPCServingStatus(t *testing.T) {
+	checker := NewChecker(0, []string{"pb.SimpleBank"}, passingCheck("database"))
+	checker.updateServingStatus(context.Background())
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus(t, checker, ""))
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, servingStatus(t, checker, "pb.SimpleBank"))
+
+	checker = NewChecker(0, []string{"pb.SimpleBank"}, failingCheck("database"))
+	checker.updateServingStatus(context.Background())
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, "pb.SimpleBank"))
+}
+
+func TestDrain(t *testing.T) {
+	checker := NewChecker(50*time.Millisecond, []string{"pb.SimpleBank"}, passingCheck("database"))
+
+	go func() { _ = checker.Start() }()
+
+	require.Eventually(t, func() bool {
+		rsp, err := checker.GRPCHealthServer().Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+
+		return err == nil && rsp.GetStatus() == grpc_health_v1.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, checker.Drain(context.Background()))
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	code, rsp := readyz(t, checker)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, ErrDraining.Error(), rsp.Checks["shutdown"])
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, ""))
+
+	// a check after the drain doesn't report serving again
+	checker.updateServingStatus(context.Background())
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, "pb.SimpleBank"))
+
+	require.NoError(t, checker.Stop(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, NewChecker(time.Hour, nil).Drain(ctx), context.Canceled)
+}
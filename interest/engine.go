@@ -0,0 +1,188 @@
+package interest
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/pkg/errors"
+)
+
+const pageSize = 100
+
+// Engine accrues and posts interest of savings accounts.
+type Engine struct {
+	store          db.Store
+	defaultRateBps int32
+}
+
+// NewEngine creates a new interest engine.
+// Savings accounts without their own rate earn the default rate.
+func NewEngine(store db.Store, defaultRateBps int32) *Engine {
+	return &Engine{
+		store:          store,
+		defaultRateBps: defaultRateBps,
+	}
+}
+
+// RunDay accrues interest for the given day and posts it if the day ends a period.
+// It is idempotent, so running a day more than once has no further effect.
+func (e *Engine) RunDay(ctx context.Context, date time.Time) error {
+	date = truncateDay(date)
+
+	accrued, err := e.Accrue(ctx, date)
+	if err != nil {
+		return errors.Wrapf(err, "failed to accrue interest for %s", date.Format("2006-01-02"))
+	}
+
+	log.Printf("accrued interest of %d accounts for %s", accrued, date.Format("2006-01-02"))
+
+	if !IsPostingDay(date) {
+		return nil
+	}
+
+	posted, err := e.Post(ctx, date)
+	if err != nil {
+		return errors.Wrapf(err, "failed to post interest for %s", date.Format("2006-01-02"))
+	}
+
+	log.Printf("posted interest of %d accounts for %s", posted, date.Format("2006-01-02"))
+
+	return nil
+}
+
+// LastAccruedDay returns the last day interest was accrued for, zero if it never was.
+func (e *Engine) LastAccruedDay(ctx context.Context) (time.Time, error) {
+	date, err := e.store.GetLastAccrualDate(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+
+		return time.Time{}, errors.Wrap(err, "failed to get last accrual date")
+	}
+
+	return truncateDay(date), nil
+}
+
+// Accrue records the daily interest of every savings account for the given day,
+// on the balance at the end of that day so that a missed day can be accrued later.
+// Accounts that already accrued for the day are skipped.
+func (e *Engine) Accrue(ctx context.Context, date time.Time) (int, error) {
+	var (
+		afterID int64
+		accrued int
+	)
+
+	for {
+		accounts, err := e.store.ListInterestBearingAccounts(ctx, db.ListInterestBearingAccountsParams{
+			DayEnd:         date.AddDate(0, 0, 1),
+			DefaultRateBps: e.defaultRateBps,
+			AfterID:        afterID,
+			LimitCount:     pageSize,
+		})
+		if err != nil {
+			return accrued, errors.Wrap(err, "failed to list savings accounts")
+		}
+
+		for _, account := range accounts {
+			afterID = account.ID
+
+			_, err := e.store.CreateInterestAccrual(ctx, db.CreateInterestAccrualParams{
+				AccountID:    account.ID,
+				AccrualDate:  date,
+				Balance:      account.Balance,
+				RateBps:      account.RateBps,
+				AmountMicros: DailyAccrual(account.Balance, account.RateBps),
+			})
+			if err != nil {
+				// nothing returned means the day is already accrued
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+
+				return accrued, errors.Wrapf(err, "failed to accrue interest of account %d", account.ID)
+			}
+
+			accrued++
+		}
+
+		if len(accounts) < pageSize {
+			return accrued, nil
+		}
+	}
+}
+
+// Post pays out the interest accrued until the period end for every account.
+// Accounts that are already posted for the period are skipped.
+func (e *Engine) Post(ctx context.Context, periodEnd time.Time) (int, error) {
+	var (
+		afterID int64
+		posted  int
+	)
+
+	expenseAccounts := make(map[string]int64)
+
+	for {
+		accountIDs, err := e.store.ListAccountsWithAccruals(ctx, db.ListAccountsWithAccrualsParams{
+			PeriodEnd:  periodEnd,
+			AfterID:    afterID,
+			LimitCount: pageSize,
+		})
+		if err != nil {
+			return posted, errors.Wrap(err, "failed to list accounts with accrued interest")
+		}
+
+		for _, accountID := range accountIDs {
+			afterID = accountID
+
+			account, err := e.store.GetAccount(ctx, accountID)
+			if err != nil {
+				return posted, errors.Wrapf(err, "failed to get account %d", accountID)
+			}
+
+			expenseAccountID, ok := expenseAccounts[account.Currency]
+			if !ok {
+				expenseAccount, err := e.store.GetSystemAccount(ctx, db.GetSystemAccountParams{
+					Owner:    util.BankUsername,
+					Currency: account.Currency,
+					Type:     util.InterestExpense,
+				})
+				if err != nil {
+					return posted, errors.Wrapf(err, "failed to get interest expense account for %s", account.Currency)
+				}
+
+				expenseAccountID = expenseAccount.ID
+				expenseAccounts[account.Currency] = expenseAccountID
+			}
+
+			_, err = e.store.PostInterestTx(ctx, db.PostInterestTxParams{
+				AccountID:        accountID,
+				ExpenseAccountID: expenseAccountID,
+				PeriodEnd:        periodEnd,
+			})
+			if err != nil {
+				if errors.Is(err, db.ErrInterestAlreadyPosted) {
+					continue
+				}
+
+				return posted, errors.Wrapf(err, "failed to post interest of account %d", accountID)
+			}
+
+			posted++
+		}
+
+		if len(accountIDs) < pageSize {
+			return posted, nil
+		}
+	}
+}
+
+func truncateDay(date time.Time) time.Time {
+	year, month, day := date.Date()
+
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
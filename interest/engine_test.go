@@ -0,0 +1,155 @@
+package interest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/ifantsai/simple-bank-api/db/mock"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDay(t *testing.T) {
+	account := db.ListInterestBearingAccountsRow{
+		ID:       util.RandomMoney(),
+		Balance:  util.RandomMoney(),
+		Currency: util.USD,
+		RateBps:  150,
+	}
+
+	expenseAccount := db.Account{
+		ID:       account.ID + 1,
+		Owner:    util.BankUsername,
+		Currency: util.USD,
+		Type:     util.InterestExpense,
+	}
+
+	testCases := []struct {
+		name       string
+		date       time.Time
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name: "AccrueOnly",
+			date: time.Date(2022, time.March, 15, 13, 0, 0, 0, time.UTC),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListInterestBearingAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListInterestBearingAccountsRow{account}, nil)
+
+				store.EXPECT().
+					CreateInterestAccrual(gomock.Any(), gomock.Eq(db.CreateInterestAccrualParams{
+						AccountID:    account.ID,
+						AccrualDate:  time.Date(2022, time.March, 15, 0, 0, 0, 0, time.UTC),
+						Balance:      account.Balance,
+						RateBps:      account.RateBps,
+						AmountMicros: DailyAccrual(account.Balance, account.RateBps),
+					})).
+					Times(1).
+					Return(db.InterestAccrual{}, nil)
+
+				store.EXPECT().
+					ListAccountsWithAccruals(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+		},
+		{
+			name: "AccrueAndPost",
+			date: time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListInterestBearingAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListInterestBearingAccountsRow{account}, nil)
+
+				store.EXPECT().
+					CreateInterestAccrual(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.InterestAccrual{}, nil)
+
+				store.EXPECT().
+					ListAccountsWithAccruals(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]int64{account.ID}, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{ID: account.ID, Currency: account.Currency}, nil)
+
+				store.EXPECT().
+					GetSystemAccount(gomock.Any(), gomock.Eq(db.GetSystemAccountParams{
+						Owner:    util.BankUsername,
+						Currency: util.USD,
+						Type:     util.InterestExpense,
+					})).
+					Times(1).
+					Return(expenseAccount, nil)
+
+				store.EXPECT().
+					PostInterestTx(gomock.Any(), gomock.Eq(db.PostInterestTxParams{
+						AccountID:        account.ID,
+						ExpenseAccountID: expenseAccount.ID,
+						PeriodEnd:        time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC),
+					})).
+					Times(1).
+					Return(db.PostInterestTxResult{}, nil)
+			},
+		},
+		{
+			name: "AlreadyDone",
+			date: time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListInterestBearingAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListInterestBearingAccountsRow{account}, nil)
+
+				store.EXPECT().
+					CreateInterestAccrual(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.InterestAccrual{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					ListAccountsWithAccruals(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]int64{account.ID}, nil)
+
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{ID: account.ID, Currency: account.Currency}, nil)
+
+				store.EXPECT().
+					GetSystemAccount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(expenseAccount, nil)
+
+				store.EXPECT().
+					PostInterestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PostInterestTxResult{}, db.ErrInterestAlreadyPosted)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			engine := NewEngine(store, 150)
+			require.NoError(t, engine.RunDay(context.Background(), tc.date))
+		})
+	}
+}
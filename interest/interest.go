@@ -0,0 +1,54 @@
+package interest
+
+import (
+	"math/big"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+)
+
+const (
+	// DaysPerYear is the day count basis of the accrual (actual/365 fixed).
+	DaysPerYear = 365
+	// basisPointsPerUnit converts a rate in basis points to a fraction.
+	basisPointsPerUnit = 10_000
+)
+
+// DailyAccrual returns the interest earned by a balance in one day, in millionths of the minor unit.
+//
+// The rounding policy is:
+//   - interest accrues daily on the actual/365 fixed basis,
+//   - the daily amount is rounded half to even at the millionth of the minor unit,
+//   - accounts with a non-positive balance or rate accrue nothing,
+//   - only whole minor units are posted, the remaining fraction is carried to the next period.
+func DailyAccrual(balance int64, rateBps int32) int64 {
+	if balance <= 0 || rateBps <= 0 {
+		return 0
+	}
+
+	numerator := new(big.Int).SetInt64(balance)
+	numerator.Mul(numerator, big.NewInt(int64(rateBps)))
+	numerator.Mul(numerator, big.NewInt(db.MicrosPerUnit))
+
+	denominator := big.NewInt(basisPointsPerUnit * DaysPerYear)
+
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+
+	// round half to even
+	switch remainder.Mul(remainder, big.NewInt(2)).Cmp(denominator) {
+	case 1:
+		quotient.Add(quotient, big.NewInt(1))
+	case 0:
+		if quotient.Bit(0) == 1 {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+
+	return quotient.Int64()
+}
+
+// IsPostingDay returns true if interest is posted at the end of the given day,
+// which is the last day of every month.
+func IsPostingDay(date time.Time) bool {
+	return date.AddDate(0, 0, 1).Day() == 1
+}
@@ -0,0 +1,88 @@
+package interest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDailyAccrual(t *testing.T) {
+	testCases := []struct {
+		name    string
+		balance int64
+		rateBps int32
+		micros  int64
+	}{
+		{
+			name:    "Exact",
+			balance: 1000,
+			rateBps: 365,
+			micros:  100_000,
+		},
+		{
+			name:    "RoundDown",
+			balance: 100,
+			rateBps: 149,
+			micros:  4082,
+		},
+		{
+			name:    "RoundUp",
+			balance: 100,
+			rateBps: 150,
+			micros:  4110,
+		},
+		{
+			name:    "BelowHalfMicro",
+			balance: 1,
+			rateBps: 1,
+			micros:  0,
+		},
+		{
+			name:    "AboveHalfMicro",
+			balance: 1,
+			rateBps: 2,
+			micros:  1,
+		},
+		{
+			name:    "LargeBalance",
+			balance: 9_000_000_000_000,
+			rateBps: 10_000,
+			micros:  24_657_534_246_575_342,
+		},
+		{
+			name:    "ZeroBalance",
+			balance: 0,
+			rateBps: 150,
+			micros:  0,
+		},
+		{
+			name:    "NegativeBalance",
+			balance: -100,
+			rateBps: 150,
+			micros:  0,
+		},
+		{
+			name:    "ZeroRate",
+			balance: 100,
+			rateBps: 0,
+			micros:  0,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.micros, DailyAccrual(tc.balance, tc.rateBps))
+		})
+	}
+}
+
+func TestIsPostingDay(t *testing.T) {
+	require.True(t, IsPostingDay(time.Date(2022, time.January, 31, 0, 0, 0, 0, time.UTC)))
+	require.True(t, IsPostingDay(time.Date(2022, time.February, 28, 0, 0, 0, 0, time.UTC)))
+	require.False(t, IsPostingDay(time.Date(2024, time.February, 28, 0, 0, 0, 0, time.UTC)))
+	require.True(t, IsPostingDay(time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)))
+	require.False(t, IsPostingDay(time.Date(2022, time.December, 1, 0, 0, 0, 0, time.UTC)))
+}
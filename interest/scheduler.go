@@ -0,0 +1,108 @@
+package interest
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Scheduler runs the interest engine for every completed day.
+type Scheduler struct {
+	engine   *Engine
+	interval time.Duration
+	// lastDay is the last day the engine ran for, zero until it is read from the accruals.
+	lastDay time.Time
+	// ctx is created with the scheduler so that Stop can cancel it while Start is starting.
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewScheduler creates a new scheduler which checks for completed days at every interval.
+func NewScheduler(engine *Engine, interval time.Duration) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Scheduler{
+		engine:   engine,
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the scheduler until it is stopped.
+func (s *Scheduler) Start() error {
+	ctx := s.ctx
+
+	defer close(s.done)
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	log.Println("interest scheduler is running every", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.runDueDays(ctx, time.Now()); err != nil {
+			log.Println("failed to run interest engine:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDueDays runs the engine for every completed day since the last one it ran for,
+// so that the days the process was down are accrued as well.
+// The last accrued day is run again in case it was interrupted, which is a no-op if it completed.
+func (s *Scheduler) runDueDays(ctx context.Context, now time.Time) error {
+	yesterday := truncateDay(now.UTC().AddDate(0, 0, -1))
+
+	if s.lastDay.IsZero() {
+		lastDay, err := s.engine.LastAccruedDay(ctx)
+		if err != nil {
+			return err
+		}
+
+		// nothing was ever accrued, so there is nothing to catch up
+		if lastDay.IsZero() || lastDay.After(yesterday) {
+			lastDay = yesterday
+		}
+
+		s.lastDay = lastDay.AddDate(0, 0, -1)
+	}
+
+	for day := s.lastDay.AddDate(0, 0, 1); !day.After(yesterday); day = day.AddDate(0, 0, 1) {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err := s.engine.RunDay(ctx, day); err != nil {
+			return err
+		}
+
+		s.lastDay = day
+	}
+
+	return nil
+}
+
+// Stop stops the scheduler and waits for the running day to finish.
+// It can be called before Start runs, which then returns at once.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,164 @@
+package interest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/ifantsai/simple-bank-api/db/mock"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerRunDueDays(t *testing.T) {
+	now := time.Date(2022, time.March, 15, 13, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		days       []time.Time
+	}{
+		{
+			name: "CatchUp",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLastAccrualDate(gomock.Any()).
+					Times(1).
+					Return(time.Date(2022, time.March, 11, 0, 0, 0, 0, time.UTC), nil)
+			},
+			// the last accrued day is run again in case it was interrupted
+			days: []time.Time{
+				time.Date(2022, time.March, 11, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, time.March, 12, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, time.March, 13, 0, 0, 0, 0, time.UTC),
+				time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "NeverAccrued",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetLastAccrualDate(gomock.Any()).
+					Times(1).
+					Return(time.Time{}, sql.ErrNoRows)
+			},
+			days: []time.Time{
+				time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			var days []time.Time
+
+			store.EXPECT().
+				ListInterestBearingAccounts(gomock.Any(), gomock.Any()).
+				Times(len(tc.days)).
+				Return([]db.ListInterestBearingAccountsRow{{ID: 1, Balance: 100, RateBps: 150}}, nil)
+
+			store.EXPECT().
+				CreateInterestAccrual(gomock.Any(), gomock.Any()).
+				Times(len(tc.days)).
+				DoAndReturn(func(_ interface{}, arg db.CreateInterestAccrualParams) (db.InterestAccrual, error) {
+					days = append(days, arg.AccrualDate)
+
+					return db.InterestAccrual{}, nil
+				})
+
+			scheduler := NewScheduler(NewEngine(store, 150), time.Hour)
+
+			require.NoError(t, scheduler.runDueDays(context.Background(), now))
+			require.Equal(t, tc.days, days)
+
+			// the days already run are not run again
+			require.NoError(t, scheduler.runDueDays(context.Background(), now))
+			require.Equal(t, tc.days, days)
+		})
+	}
+}
+
+func TestSchedulerBackfillBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	now := time.Date(2022, time.March, 15, 13, 0, 0, 0, time.UTC)
+
+	// the balance was 100 until a deposit of 50 on March 14 and is 150 now
+	balances := map[time.Time]int64{
+		time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC): 100,
+		time.Date(2022, time.March, 15, 0, 0, 0, 0, time.UTC): 150,
+	}
+
+	store.EXPECT().
+		GetLastAccrualDate(gomock.Any()).
+		Times(1).
+		Return(time.Date(2022, time.March, 13, 0, 0, 0, 0, time.UTC), nil)
+
+	store.EXPECT().
+		ListInterestBearingAccounts(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ interface{}, arg db.ListInterestBearingAccountsParams) ([]db.ListInterestBearingAccountsRow, error) {
+			balance, ok := balances[arg.DayEnd]
+			require.True(t, ok)
+
+			return []db.ListInterestBearingAccountsRow{{ID: 1, Balance: balance, RateBps: 150}}, nil
+		})
+
+	accrued := make(map[time.Time]int64)
+
+	store.EXPECT().
+		CreateInterestAccrual(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ interface{}, arg db.CreateInterestAccrualParams) (db.InterestAccrual, error) {
+			require.Equal(t, DailyAccrual(arg.Balance, arg.RateBps), arg.AmountMicros)
+			accrued[arg.AccrualDate] = arg.Balance
+
+			return db.InterestAccrual{}, nil
+		})
+
+	scheduler := NewScheduler(NewEngine(store, 150), time.Hour)
+
+	require.NoError(t, scheduler.runDueDays(context.Background(), now))
+	require.Equal(t, map[time.Time]int64{
+		time.Date(2022, time.March, 13, 0, 0, 0, 0, time.UTC): 100,
+		time.Date(2022, time.March, 14, 0, 0, 0, 0, time.UTC): 150,
+	}, accrued)
+}
+
+func TestSchedulerStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetLastAccrualDate(gomock.Any()).
+		AnyTimes().
+		Return(time.Time{}, sql.ErrNoRows)
+	store.EXPECT().
+		ListInterestBearingAccounts(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return([]db.ListInterestBearingAccountsRow{}, nil)
+
+	scheduler := NewScheduler(NewEngine(store, 150), time.Hour)
+
+	// Stop may run before the goroutine of Start did
+	go func() {
+		require.NoError(t, scheduler.Start())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, scheduler.Stop(ctx))
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "simple_bank"
+
+// Reasons of login failures.
+const (
+	LoginFailureNotFound      = "not_found"
+	LoginFailureWrongPassword = "wrong_password"
+)
+
+var (
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "Number of handled gRPC requests by method and status code.",
+	}, []string{"method", "code"})
+
+	grpcRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Latency of handled gRPC requests by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of handled HTTP requests by method, route and status code.",
+	}, []string{"method", "path", "code"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of handled HTTP requests by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "path", "code"})
+
+	transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Number of completed transfers by currency.",
+	}, []string{"currency"})
+
+	transferVolume = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfer_volume_total",
+		Help:      "Amount of completed transfers in minor units by currency.",
+	}, []string{"currency"})
+
+	loginFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Number of failed logins by reason.",
+	}, []string{"reason"})
+
+	rateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_requests_total",
+		Help:      "Number of requests rejected by the rate limits by method.",
+	}, []string{"method"})
+
+	panics = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "panics_total",
+		Help:      "Number of panics recovered from handlers by server, grpc or http.",
+	}, []string{"server"})
+)
+
+// ObserveGRPCRequest records a handled gRPC request.
+func ObserveGRPCRequest(method string, code string, elapsed time.Duration) {
+	grpcRequests.WithLabelValues(method, code).Inc()
+	grpcRequestDuration.WithLabelValues(method, code).Observe(elapsed.Seconds())
+}
+
+// ObserveHTTPRequest records a handled HTTP request.
+// The path must be the route pattern rather than the request path to keep the number of series bounded.
+func ObserveHTTPRequest(method string, path string, statusCode int, elapsed time.Duration) {
+	code := strconv.Itoa(statusCode)
+
+	httpRequests.WithLabelValues(method, path, code).Inc()
+	httpRequestDuration.WithLabelValues(method, path, code).Observe(elapsed.Seconds())
+}
+
+// ObserveTransfer records the volume of a transfer, which is only counted once its money was moved.
+func ObserveTransfer(result db.TransferTxResult) {
+	if result.Transfer.Status != db.TransferStatusCompleted {
+		return
+	}
+
+	currency := result.FromAccount.Currency
+
+	transfers.WithLabelValues(currency).Inc()
+	transferVolume.WithLabelValues(currency).Add(float64(result.Transfer.Amount))
+}
+
+// LoginFailed records a failed login.
+func LoginFailed(reason string) {
+	loginFailures.WithLabelValues(reason).Inc()
+}
+
+// RateLimited records a request rejected by the rate limits.
+func RateLimited(method string) {
+	rateLimited.WithLabelValues(method).Inc()
+}
+
+// Panicked records a panic recovered from a handler of the grpc or http server.
+func Panicked(server string) {
+	panics.WithLabelValues(server).Inc()
+}
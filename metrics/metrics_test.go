@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/ifantsai/simple-bank-api/db/mock"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// The counters are global, so the tests check how much they grew to be repeatable.
+
+func TestObserveRequests(t *testing.T) {
+	grpcCounter := grpcRequests.WithLabelValues("/pb.SimpleBank/LoginUser", "OK")
+	grpcBefore := testutil.ToFloat64(grpcCounter)
+
+	ObserveGRPCRequest("/pb.SimpleBank/LoginUser", "OK", 10*time.Millisecond)
+	ObserveGRPCRequest("/pb.SimpleBank/LoginUser", "OK", 20*time.Millisecond)
+	require.Equal(t, grpcBefore+2, testutil.ToFloat64(grpcCounter))
+
+	httpCounter := httpRequests.WithLabelValues(http.MethodGet, "/v1/accounts/{account_id}/statement", "200")
+	httpBefore := testutil.ToFloat64(httpCounter)
+
+	ObserveHTTPRequest(http.MethodGet, "/v1/accounts/{account_id}/statement", http.StatusOK, time.Second)
+	require.Equal(t, httpBefore+1, testutil.ToFloat64(httpCounter))
+	require.Equal(t, 1, testutil.CollectAndCount(httpRequestDuration))
+}
+
+func TestStoreTransfers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	completed := db.TransferTxResult{
+		Transfer:    db.Transfer{Amount: 250, Status: db.TransferStatusCompleted},
+		FromAccount: db.Account{Currency: util.EUR},
+	}
+	held := db.TransferTxResult{
+		Transfer:    db.Transfer{Amount: 100000, Status: db.TransferStatusPendingReview},
+		FromAccount: db.Account{Currency: util.EUR},
+	}
+
+	mockStore := mockdb.NewMockStore(ctrl)
+	mockStore.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(completed, nil)
+	mockStore.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(held, nil)
+	mockStore.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, errors.New("failed"))
+	mockStore.EXPECT().ReviewTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(completed, nil)
+
+	store := NewStore(mockStore)
+	ctx := context.Background()
+
+	transfersBefore := testutil.ToFloat64(transfers.WithLabelValues(util.EUR))
+	volumeBefore := testutil.ToFloat64(transferVolume.WithLabelValues(util.EUR))
+
+	for i := 0; i < 3; i++ {
+		_, _ = store.TransferTx(ctx, db.TransferTxParams{})
+	}
+
+	_, err := store.ReviewTransferTx(ctx, db.ReviewTransferTxParams{Approve: true})
+	require.NoError(t, err)
+
+	require.Equal(t, transfersBefore+2, testutil.ToFloat64(transfers.WithLabelValues(util.EUR)))
+	require.Equal(t, volumeBefore+500, testutil.ToFloat64(transferVolume.WithLabelValues(util.EUR)))
+}
+
+func TestLoginFailed(t *testing.T) {
+	before := testutil.ToFloat64(loginFailures.WithLabelValues(LoginFailureWrongPassword))
+
+	LoginFailed(LoginFailureWrongPassword)
+	require.Equal(t, before+1, testutil.ToFloat64(loginFailures.WithLabelValues(LoginFailureWrongPassword)))
+}
+
+func TestPanicked(t *testing.T) {
+	before := testutil.ToFloat64(panics.WithLabelValues("grpc"))
+
+	Panicked("grpc")
+	Panicked("grpc")
+	require.Equal(t, before+2, testutil.ToFloat64(panics.WithLabelValues("grpc")))
+}
+
+func TestSessionCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CountActiveSessions(gomock.Any()).Times(1).Return(int64(3), nil)
+
+	expected := `
+# HELP simple_bank_active_sessions Number of sessions which are neither blocked nor expired.
+# TYPE simple_bank_active_sessions gauge
+simple_bank_active_sessions 3
+`
+	collector := NewSessionCollector(store)
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	store.EXPECT().CountActiveSessions(gomock.Any()).Times(1).Return(int64(0), errors.New("connection refused"))
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	_, err := registry.Gather()
+	require.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// sessionCountTimeout bounds the query of a scrape, so that a slow database doesn't block the scraper.
+const sessionCountTimeout = 3 * time.Second
+
+var activeSessionsDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "active_sessions"),
+	"Number of sessions which are neither blocked nor expired.",
+	nil, nil,
+)
+
+// SessionCollector counts the active sessions in the database at every scrape.
+type SessionCollector struct {
+	store db.Querier
+}
+
+// NewSessionCollector creates a new collector of active sessions.
+func NewSessionCollector(store db.Querier) *SessionCollector {
+	return &SessionCollector{store: store}
+}
+
+// Describe implements prometheus.Collector.
+func (c *SessionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeSessionsDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *SessionCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionCountTimeout)
+	defer cancel()
+
+	count, err := c.store.CountActiveSessions(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(activeSessionsDesc, err)
+
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(activeSessionsDesc, prometheus.GaugeValue, float64(count))
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"context"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+)
+
+// Store records the business metrics of the transactions it executes.
+type Store struct {
+	db.Store
+}
+
+// NewStore wraps a store to record transfer metrics.
+func NewStore(store db.Store) db.Store {
+	return &Store{Store: store}
+}
+
+// TransferTx records the transfer once its money was moved, a transfer held for review is recorded when approved.
+func (s *Store) TransferTx(ctx context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+	result, err := s.Store.TransferTx(ctx, arg)
+	if err == nil {
+		ObserveTransfer(result)
+	}
+
+	return result, err
+}
+
+// ReviewTransferTx records the transfer if it was approved.
+func (s *Store) ReviewTransferTx(ctx context.Context, arg db.ReviewTransferTxParams) (db.TransferTxResult, error) {
+	result, err := s.Store.ReviewTransferTx(ctx, arg)
+	if err == nil {
+		ObserveTransfer(result)
+	}
+
+	return result, err
+}
@@ -0,0 +1,49 @@
+// Package outbox publishes the domain events recorded in the outbox table to downstream sinks.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+)
+
+// subjectPrefix is the first token of the NATS-style subjects of events.
+const subjectPrefix = "simplebank"
+
+// Event is a domain event as delivered to sinks.
+type Event struct {
+	ID            int64           `json:"id"`
+	Subject       string          `json:"subject"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// NewEvent converts a recorded outbox event to the event delivered to sinks.
+func NewEvent(event db.OutboxEvent) Event {
+	return Event{
+		ID:            event.ID,
+		Subject:       Subject(event.EventType),
+		Type:          event.EventType,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       event.Payload,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+// Subject returns the NATS-style subject of an event type, e.g. simplebank.transfer.completed.
+func Subject(eventType string) string {
+	return subjectPrefix + "." + strings.ToLower(eventType)
+}
+
+// Sink delivers events to a downstream system.
+// Delivery is at-least-once, so sinks may receive the same event again and should deduplicate by its id.
+type Sink interface {
+	Publish(ctx context.Context, event Event) error
+}
@@ -0,0 +1,168 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/ifantsai/simple-bank-api/db/mock"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	events []Event
+	err    error
+}
+
+func (s *recordingSink) Publish(_ context.Context, event Event) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.events = append(s.events, event)
+
+	return nil
+}
+
+func TestRelayPublishPending(t *testing.T) {
+	recorded := db.OutboxEvent{
+		ID:            1,
+		AggregateType: db.OutboxAggregateTransfer,
+		AggregateID:   "7",
+		EventType:     db.EventTransferCompleted,
+		Payload:       json.RawMessage(`{"id":7}`),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		PublishOutbox(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.PublishOutboxParams) (db.PublishOutboxResult, error) {
+			require.Equal(t, int32(batchSize), arg.Limit)
+			require.Equal(t, int32(10), arg.MaxAttempts)
+			require.NoError(t, arg.Publish(ctx, recorded))
+
+			return db.PublishOutboxResult{Published: 1, Locked: true}, nil
+		})
+
+	sink1, sink2 := &recordingSink{}, &recordingSink{}
+	relay := NewRelay(store, 0, 10, sink1, sink2)
+	require.NoError(t, relay.PublishPending(context.Background()))
+
+	for _, sink := range []*recordingSink{sink1, sink2} {
+		require.Len(t, sink.events, 1)
+		require.Equal(t, recorded.ID, sink.events[0].ID)
+		require.Equal(t, "simplebank.transfer.completed", sink.events[0].Subject)
+		require.JSONEq(t, `{"id":7}`, string(sink.events[0].Payload))
+	}
+}
+
+func TestRelayFailingSink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		PublishOutbox(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.PublishOutboxParams) (db.PublishOutboxResult, error) {
+			require.Error(t, arg.Publish(ctx, db.OutboxEvent{ID: 1}))
+
+			return db.PublishOutboxResult{Failed: 1, Locked: true}, nil
+		})
+
+	relay := NewRelay(store, 0, 10, &recordingSink{err: errors.New("unavailable")})
+	require.NoError(t, relay.PublishPending(context.Background()))
+}
+
+func TestRelayStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		PublishOutbox(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(db.PublishOutboxResult{Locked: true}, nil)
+
+	relay := NewRelay(store, time.Hour, 10)
+
+	// Stop may run before the goroutine of Start did
+	go func() {
+		require.NoError(t, relay.Start())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, relay.Stop(ctx))
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := NewFileSink(path)
+
+	for id := int64(1); id <= 2; id++ {
+		err := sink.Publish(context.Background(), NewEvent(db.OutboxEvent{
+			ID:            id,
+			AggregateType: db.OutboxAggregateUser,
+			AggregateID:   "alice",
+			EventType:     db.EventUserCreated,
+			Payload:       json.RawMessage(`{}`),
+		}))
+		require.NoError(t, err)
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var ids []int64
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		require.Equal(t, "simplebank.user.created", event.Subject)
+
+		ids = append(ids, event.ID)
+	}
+
+	require.Equal(t, []int64{1, 2}, ids)
+}
+
+func TestWebhookSink(t *testing.T) {
+	status := http.StatusNoContent
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "3", r.Header.Get("X-Event-Id"))
+		require.Equal(t, db.EventAccountCreated, r.Header.Get("X-Event-Type"))
+
+		var event Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		require.Equal(t, int64(3), event.ID)
+
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, DefaultWebhookTimeout)
+	event := NewEvent(db.OutboxEvent{ID: 3, EventType: db.EventAccountCreated, Payload: json.RawMessage(`{}`)})
+
+	require.NoError(t, sink.Publish(context.Background(), event))
+
+	status = http.StatusInternalServerError
+	require.Error(t, sink.Publish(context.Background(), event))
+}
@@ -0,0 +1,121 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+)
+
+// batchSize is the number of events read and published at once.
+const batchSize = 100
+
+// Relay publishes the events of the outbox table to every sink.
+// An event is only marked as published once all sinks accepted it, otherwise it is retried at the next interval
+// until it failed the maximum attempts.
+type Relay struct {
+	store       db.Store
+	sinks       []Sink
+	interval    time.Duration
+	maxAttempts int32
+	// ctx is created with the relay so that Stop can cancel it while Start is starting.
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRelay creates a new relay which checks for unpublished events at every interval.
+// Events which failed maxAttempts times are set aside, zero retries them forever.
+func NewRelay(store db.Store, interval time.Duration, maxAttempts int32, sinks ...Sink) *Relay {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Relay{
+		store:       store,
+		sinks:       sinks,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		ctx:         ctx,
+		cancel:      cancel,
+		done:        make(chan struct{}),
+	}
+}
+
+// Start runs the relay until it is stopped.
+func (r *Relay) Start() error {
+	ctx := r.ctx
+
+	defer close(r.done)
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	log.Println("outbox relay is running every", r.interval)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.PublishPending(ctx); err != nil {
+			log.Println("failed to publish outbox events:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops the relay and waits for the running batch to finish.
+// It can be called before Start runs, which then returns at once.
+func (r *Relay) Stop(ctx context.Context) error {
+	r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// PublishPending publishes batches of events until no full batch is left.
+func (r *Relay) PublishPending(ctx context.Context) error {
+	for {
+		result, err := r.store.PublishOutbox(ctx, db.PublishOutboxParams{
+			Limit:       batchSize,
+			Publish:     r.publish,
+			MaxAttempts: r.maxAttempts,
+		})
+		if err != nil {
+			return err
+		}
+
+		if result.Failed > result.SetAside {
+			log.Printf("failed to publish %d outbox events, they will be retried", result.Failed-result.SetAside)
+		}
+
+		if result.SetAside > 0 {
+			log.Printf("set aside %d outbox events which failed %d times", result.SetAside, r.maxAttempts)
+		}
+
+		if !result.Locked || result.Published+result.Failed < batchSize || result.Failed > 0 || ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, recorded db.OutboxEvent) error {
+	event := NewEvent(recorded)
+
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
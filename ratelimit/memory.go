@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryStore keeps the token buckets in the process.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// NewMemoryStore creates a new in-memory bucket store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take refills the bucket of the key and takes a token if one is left.
+func (s *MemoryStore) Take(_ context.Context, key string, policy Policy) (bool, float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(policy.Burst), updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	tokens := math.Min(float64(policy.Burst), b.tokens+now.Sub(b.updatedAt).Seconds()*policy.Rate)
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	b.tokens = tokens
+	b.updatedAt = now
+
+	return allowed, tokens, nil
+}
+
+// Cleanup removes the buckets which were not taken from since before.
+func (s *MemoryStore) Cleanup(_ context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, b := range s.buckets {
+		if b.updatedAt.Before(before) {
+			delete(s.buckets, key)
+		}
+	}
+
+	return nil
+}
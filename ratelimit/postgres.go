@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/pkg/errors"
+)
+
+// PostgresStore keeps the token buckets in the database.
+// A bucket is refilled and taken from in a single statement, so that concurrent requests of the replicas do not race.
+type PostgresStore struct {
+	querier db.Querier
+}
+
+// NewPostgresStore creates a new bucket store which keeps the buckets with the querier.
+func NewPostgresStore(querier db.Querier) *PostgresStore {
+	return &PostgresStore{querier: querier}
+}
+
+// Take refills the bucket of the key and takes a token if one is left.
+func (s *PostgresStore) Take(ctx context.Context, key string, policy Policy) (bool, float64, error) {
+	bucket, err := s.querier.TakeRateLimitToken(ctx, db.TakeRateLimitTokenParams{
+		Key:   key,
+		Burst: float64(policy.Burst),
+		Rate:  policy.Rate,
+	})
+	if err != nil {
+		return false, 0, errors.Wrap(err, "cannot take rate limit token")
+	}
+
+	return bucket.Allowed, bucket.Tokens, nil
+}
+
+// Cleanup removes the buckets which were not taken from since before.
+func (s *PostgresStore) Cleanup(ctx context.Context, before time.Time) error {
+	_, err := s.querier.DeleteRateLimitBuckets(ctx, before)
+
+	return errors.Wrap(err, "cannot delete rate limit buckets")
+}
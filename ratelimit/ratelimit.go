@@ -0,0 +1,247 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/pkg/errors"
+)
+
+// Kinds of bucket stores.
+const (
+	// Memory keeps the buckets in the process, every replica limits on its own.
+	Memory = "memory"
+	// Postgres keeps the buckets in the database, so that the replicas share them.
+	Postgres = "postgres"
+)
+
+// cleanupInterval is how often the buckets which are full again are removed.
+const cleanupInterval = time.Minute
+
+// Policy is a token bucket which is refilled with Rate tokens per second up to Burst tokens.
+// Every request takes a token, the zero policy does not limit.
+type Policy struct {
+	Rate  float64
+	Burst int
+}
+
+// ParsePolicy parses a policy of the form "count/period[:burst]", e.g. "5/m" or "100/10s:20".
+// The period is a duration whose leading 1 may be omitted, the burst defaults to the count.
+// An empty string is the zero policy.
+func ParsePolicy(s string) (Policy, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Policy{}, nil
+	}
+
+	limit, burst, hasBurst := strings.Cut(s, ":")
+
+	count, period, ok := strings.Cut(limit, "/")
+	if !ok {
+		return Policy{}, errors.Errorf("invalid rate limit policy %q", s)
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n <= 0 {
+		return Policy{}, errors.Errorf("invalid count of rate limit policy %q", s)
+	}
+
+	if period != "" && (period[0] < '0' || period[0] > '9') {
+		period = "1" + period
+	}
+
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration <= 0 {
+		return Policy{}, errors.Errorf("invalid period of rate limit policy %q", s)
+	}
+
+	policy := Policy{
+		Rate:  float64(n) / duration.Seconds(),
+		Burst: n,
+	}
+
+	if hasBurst {
+		if policy.Burst, err = strconv.Atoi(burst); err != nil || policy.Burst <= 0 {
+			return Policy{}, errors.Errorf("invalid burst of rate limit policy %q", s)
+		}
+	}
+
+	return policy, nil
+}
+
+// ParsePolicies parses comma separated policies of methods, e.g. "LoginUser=5/m,CreateUser=3/m:1".
+func ParsePolicies(s string) (map[string]Policy, error) {
+	policies := make(map[string]Policy)
+
+	for _, entry := range strings.Split(s, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		method, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid rate limit policy %q, expected method=policy", entry)
+		}
+
+		policy, err := ParsePolicy(value)
+		if err != nil {
+			return nil, err
+		}
+
+		policies[strings.TrimSpace(method)] = policy
+	}
+
+	return policies, nil
+}
+
+// Limited reports whether the policy limits requests.
+func (p Policy) Limited() bool {
+	return p.Rate > 0 && p.Burst > 0
+}
+
+// refillTime is how long an empty bucket takes to be full again.
+func (p Policy) refillTime() time.Duration {
+	return time.Duration(float64(p.Burst) / p.Rate * float64(time.Second))
+}
+
+// Store keeps the token buckets.
+type Store interface {
+	// Take refills the bucket of the key for the time since it was last taken from and takes a token if one is left.
+	// A missing bucket is full. It returns whether a token was taken and how many tokens are left.
+	Take(ctx context.Context, key string, policy Policy) (allowed bool, tokens float64, err error)
+	// Cleanup removes the buckets which were not taken from since before.
+	Cleanup(ctx context.Context, before time.Time) error
+}
+
+// NewStore creates a bucket store of the given kind, the Postgres store keeps the buckets with the querier.
+func NewStore(kind string, querier db.Querier) (Store, error) {
+	switch kind {
+	case "", Memory:
+		return NewMemoryStore(), nil
+	case Postgres:
+		return NewPostgresStore(querier), nil
+	default:
+		return nil, errors.Errorf("unknown rate limit store %q", kind)
+	}
+}
+
+// Decision is the outcome of a rate limited request.
+type Decision struct {
+	Allowed bool
+	// RetryAfter is how long until the next token, only set if the request is not allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter limits requests per method and subject, e.g. a client IP or a username.
+// It removes the buckets which are full again until it is stopped.
+type Limiter struct {
+	store         Store
+	defaultPolicy Policy
+	policies      map[string]Policy
+	// maxRefillTime is the longest refill time of the policies, buckets untouched for longer are full.
+	maxRefillTime time.Duration
+	cancel        context.CancelFunc
+	done          chan struct{}
+}
+
+// NewLimiter creates a new limiter which applies the policy of a method or else the default policy.
+func NewLimiter(store Store, defaultPolicy Policy, policies map[string]Policy) *Limiter {
+	limiter := &Limiter{
+		store:         store,
+		defaultPolicy: defaultPolicy,
+		policies:      policies,
+		done:          make(chan struct{}),
+	}
+
+	for _, policy := range append([]Policy{defaultPolicy}, values(policies)...) {
+		if policy.Limited() && policy.refillTime() > limiter.maxRefillTime {
+			limiter.maxRefillTime = policy.refillTime()
+		}
+	}
+
+	return limiter
+}
+
+// Policy returns the policy of a method.
+func (l *Limiter) Policy(method string) Policy {
+	if policy, ok := l.policies[method]; ok {
+		return policy
+	}
+
+	return l.defaultPolicy
+}
+
+// Allow takes a token from the bucket of the method and subject.
+// The request is allowed if the store fails, so that an unavailable store does not reject every request.
+func (l *Limiter) Allow(ctx context.Context, method string, subject string) (Decision, error) {
+	policy := l.Policy(method)
+	if !policy.Limited() {
+		return Decision{Allowed: true}, nil
+	}
+
+	allowed, tokens, err := l.store.Take(ctx, method+":"+subject, policy)
+	if err != nil {
+		return Decision{Allowed: true}, errors.Wrap(err, "cannot take rate limit token")
+	}
+
+	if allowed {
+		return Decision{Allowed: true}, nil
+	}
+
+	return Decision{
+		RetryAfter: time.Duration(math.Ceil((1 - tokens) / policy.Rate * float64(time.Second))),
+	}, nil
+}
+
+// Start removes the buckets which are full again until the limiter is stopped.
+func (l *Limiter) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+
+	defer close(l.done)
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if err := l.store.Cleanup(ctx, time.Now().Add(-l.maxRefillTime)); err != nil {
+			log.Println("failed to clean up rate limit buckets:", err)
+		}
+	}
+}
+
+// Stop stops the limiter.
+func (l *Limiter) Stop(ctx context.Context) error {
+	if l.cancel == nil {
+		return nil
+	}
+
+	l.cancel()
+
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func values(policies map[string]Policy) []Policy {
+	result := make([]Policy, 0, len(policies))
+	for _, policy := range policies {
+		result = append(result, policy)
+	}
+
+	return result
+}
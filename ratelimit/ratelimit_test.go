@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/ifantsai/simple-bank-api/db/mock"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	testCases := []struct {
+		policy string
+		want   Policy
+		valid  bool
+	}{
+		{policy: "", want: Policy{}, valid: true},
+		{policy: "5/m", want: Policy{Rate: 5.0 / 60, Burst: 5}, valid: true},
+		{policy: "100/10s:20", want: Policy{Rate: 10, Burst: 20}, valid: true},
+		{policy: "3/h:1", want: Policy{Rate: 3.0 / 3600, Burst: 1}, valid: true},
+		{policy: "5", valid: false},
+		{policy: "0/m", valid: false},
+		{policy: "5/fortnight", valid: false},
+		{policy: "5/m:0", valid: false},
+	}
+
+	for _, tc := range testCases {
+		policy, err := ParsePolicy(tc.policy)
+		if !tc.valid {
+			require.Error(t, err, tc.policy)
+
+			continue
+		}
+
+		require.NoError(t, err, tc.policy)
+		require.Equal(t, tc.want.Burst, policy.Burst, tc.policy)
+		require.InDelta(t, tc.want.Rate, policy.Rate, 1e-9, tc.policy)
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies("LoginUser=5/m, CreateUser=3/m:1")
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	require.Equal(t, 5, policies["LoginUser"].Burst)
+	require.Equal(t, 1, policies["CreateUser"].Burst)
+
+	policies, err = ParsePolicies("")
+	require.NoError(t, err)
+	require.Empty(t, policies)
+
+	_, err = ParsePolicies("LoginUser")
+	require.Error(t, err)
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	policy := Policy{Rate: 1, Burst: 2}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := store.Take(ctx, "key", policy)
+		require.NoError(t, err)
+		require.True(t, allowed)
+	}
+
+	allowed, tokens, err := store.Take(ctx, "key", policy)
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Zero(t, tokens)
+
+	// other keys have their own buckets
+	allowed, _, err = store.Take(ctx, "other", policy)
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	now = now.Add(1500 * time.Millisecond)
+
+	allowed, tokens, err = store.Take(ctx, "key", policy)
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.InDelta(t, 0.5, tokens, 1e-9)
+
+	require.NoError(t, store.Cleanup(ctx, now))
+	require.Len(t, store.buckets, 1)
+	require.Contains(t, store.buckets, "key")
+}
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	limiter := NewLimiter(store, Policy{}, map[string]Policy{"LoginUser": {Rate: 0.5, Burst: 1}})
+	require.Equal(t, 2*time.Second, limiter.maxRefillTime)
+
+	ctx := context.Background()
+
+	decision, err := limiter.Allow(ctx, "LoginUser", "ip:10.0.0.1")
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+
+	decision, err = limiter.Allow(ctx, "LoginUser", "ip:10.0.0.1")
+	require.NoError(t, err)
+	require.False(t, decision.Allowed)
+	require.Equal(t, 2*time.Second, decision.RetryAfter)
+
+	// the bucket is kept per subject
+	decision, err = limiter.Allow(ctx, "LoginUser", "ip:10.0.0.2")
+	require.NoError(t, err)
+	require.True(t, decision.Allowed)
+
+	// methods without a policy fall back to the default policy, which does not limit
+	for i := 0; i < 10; i++ {
+		decision, err = limiter.Allow(ctx, "GetAccount", "user:alice")
+		require.NoError(t, err)
+		require.True(t, decision.Allowed)
+	}
+}
+
+func TestLimiterFailsOpen(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	querier := mockdb.NewMockStore(ctrl)
+	querier.EXPECT().
+		TakeRateLimitToken(gomock.Any(), gomock.Eq(db.TakeRateLimitTokenParams{
+			Key:   "CreateUser:ip:10.0.0.1",
+			Burst: 3,
+			Rate:  1,
+		})).
+		Times(1).
+		Return(db.TakeRateLimitTokenRow{}, sql.ErrConnDone)
+
+	limiter := NewLimiter(NewPostgresStore(querier), Policy{Rate: 1, Burst: 3}, nil)
+
+	decision, err := limiter.Allow(context.Background(), "CreateUser", "ip:10.0.0.1")
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	require.True(t, decision.Allowed)
+}
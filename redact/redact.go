@@ -0,0 +1,175 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/ifantsai/simple-bank-api/pb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Mask replaces sensitive values.
+const Mask = "[REDACTED]"
+
+var (
+	// sensitivePatterns match sensitive values in free text such as error messages.
+	sensitivePatterns = []*regexp.Regexp{
+		// email addresses
+		regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		// paseto tokens
+		regexp.MustCompile(`v[1-4]\.(local|public)\.[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)?`),
+		// credentials of authorization headers
+		regexp.MustCompile(`(?i)\b(bearer|basic)\s+[^\s"']+`),
+	}
+
+	// sensitiveKeys are the JSON keys of sensitive values: the fields marked (sensitive) and common credentials.
+	sensitiveKeys = sensitiveFieldNames(map[string]bool{
+		"authorization": true,
+		"secret":        true,
+		"token":         true,
+	})
+)
+
+// IsSensitive reports whether a field is marked with the (sensitive) option.
+func IsSensitive(field protoreflect.FieldDescriptor) bool {
+	options, ok := field.Options().(*descriptorpb.FieldOptions)
+	if !ok || options == nil {
+		return false
+	}
+
+	sensitive, _ := proto.GetExtension(options, pb.E_Sensitive).(bool)
+
+	return sensitive
+}
+
+// sensitiveFieldNames adds the proto and JSON names of the sensitive fields of our messages to keys.
+func sensitiveFieldNames(keys map[string]bool) map[string]bool {
+	protoregistry.GlobalFiles.RangeFilesByPackage("pb", func(file protoreflect.FileDescriptor) bool {
+		addSensitiveFieldNames(keys, file.Messages())
+
+		return true
+	})
+
+	return keys
+}
+
+func addSensitiveFieldNames(keys map[string]bool, messages protoreflect.MessageDescriptors) {
+	for i := 0; i < messages.Len(); i++ {
+		message := messages.Get(i)
+		fields := message.Fields()
+
+		for j := 0; j < fields.Len(); j++ {
+			if field := fields.Get(j); IsSensitive(field) {
+				keys[strings.ToLower(string(field.Name()))] = true
+				keys[strings.ToLower(field.JSONName())] = true
+			}
+		}
+
+		addSensitiveFieldNames(keys, message.Messages())
+	}
+}
+
+// Message returns a copy of a message with its sensitive fields masked, including those of nested messages.
+func Message(m proto.Message) proto.Message {
+	if m == nil {
+		return nil
+	}
+
+	clone := proto.Clone(m)
+	redactMessage(clone.ProtoReflect())
+
+	return clone
+}
+
+func redactMessage(message protoreflect.Message) {
+	var sensitive []protoreflect.FieldDescriptor
+
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case IsSensitive(field):
+			sensitive = append(sensitive, field)
+		case field.IsList() && field.Kind() == protoreflect.MessageKind:
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				redactMessage(list.Get(i).Message())
+			}
+		case field.IsMap() && field.MapValue().Kind() == protoreflect.MessageKind:
+			value.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+				redactMessage(value.Message())
+
+				return true
+			})
+		case !field.IsList() && !field.IsMap() && field.Kind() == protoreflect.MessageKind:
+			redactMessage(value.Message())
+		}
+
+		return true
+	})
+
+	// a set field is masked, other kinds of values can't hold the mask and are cleared
+	for _, field := range sensitive {
+		if field.Kind() == protoreflect.StringKind && !field.IsList() && !field.IsMap() {
+			message.Set(field, protoreflect.ValueOfString(Mask))
+		} else {
+			message.Clear(field)
+		}
+	}
+}
+
+// Text masks the sensitive values found in free text, such as email addresses and tokens.
+func Text(text string) string {
+	for _, pattern := range sensitivePatterns {
+		text = pattern.ReplaceAllString(text, Mask)
+	}
+
+	return text
+}
+
+// JSON masks the values of sensitive keys in a JSON body and the sensitive values in its strings.
+// A body which isn't JSON is redacted as text.
+func JSON(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return []byte(Text(string(body)))
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return []byte(Mask)
+	}
+
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if sensitiveKeys[strings.ToLower(key)] {
+				value[key] = Mask
+			} else {
+				value[key] = redactValue(field)
+			}
+		}
+
+		return value
+	case []interface{}:
+		for i, element := range value {
+			value[i] = redactValue(element)
+		}
+
+		return value
+	case string:
+		return Text(value)
+	default:
+		return value
+	}
+}
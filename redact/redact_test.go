@@ -0,0 +1,77 @@
+package redact
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ifantsai/simple-bank-api/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMessage(t *testing.T) {
+	rsp := &pb.LoginUserResponse{
+		User: &pb.User{
+			Username: "alice",
+			FullName: "Alice",
+			Email:    "alice@example.com",
+		},
+		SessionId:    "6a3c4f0e-6f4c-4c57-9b5e-1e7f4d0c9a11",
+		AccessToken:  "v2.local.abc",
+		RefreshToken: "v2.local.def",
+	}
+
+	redacted, ok := Message(rsp).(*pb.LoginUserResponse)
+	require.True(t, ok)
+	require.Equal(t, "alice", redacted.GetUser().GetUsername())
+	require.Equal(t, Mask, redacted.GetUser().GetEmail())
+	require.Equal(t, rsp.GetSessionId(), redacted.GetSessionId())
+	require.Equal(t, Mask, redacted.GetAccessToken())
+	require.Equal(t, Mask, redacted.GetRefreshToken())
+
+	// the original message is left intact
+	require.Equal(t, "alice@example.com", rsp.GetUser().GetEmail())
+	require.Equal(t, "v2.local.abc", rsp.GetAccessToken())
+
+	// unset optional fields stay unset
+	update, ok := Message(&pb.UpdateUserRequest{Username: "alice", Password: proto.String("secret")}).(*pb.UpdateUserRequest)
+	require.True(t, ok)
+	require.Equal(t, Mask, update.GetPassword())
+	require.Nil(t, update.Email)
+
+	require.Nil(t, Message(nil))
+}
+
+func TestText(t *testing.T) {
+	require.Equal(t,
+		`duplicate key value violates unique constraint, Key (email)=([REDACTED]) already exists`,
+		Text(`duplicate key value violates unique constraint, Key (email)=(alice@example.com) already exists`))
+	require.Equal(t, "invalid token [REDACTED]", Text("invalid token v2.local.Zm9vYmFy.YmF6"))
+	require.Equal(t, "authorization: [REDACTED]", Text("authorization: Bearer abc.def"))
+	require.Equal(t, "account 42 not found", Text("account 42 not found"))
+}
+
+func TestJSON(t *testing.T) {
+	body := []byte(`{"user":{"username":"alice","email":"alice@example.com"},"accessToken":"v2.local.abc",` +
+		`"refresh_token":"v2.local.def","details":[{"message":"cannot send to bob@example.com"}],"amount":10.50}`)
+
+	var redacted map[string]interface{}
+	require.NoError(t, json.Unmarshal(JSON(body), &redacted))
+	require.Equal(t, map[string]interface{}{
+		"user":          map[string]interface{}{"username": "alice", "email": Mask},
+		"accessToken":   Mask,
+		"refresh_token": Mask,
+		"details":       []interface{}{map[string]interface{}{"message": "cannot send to [REDACTED]"}},
+		"amount":        10.50,
+	}, redacted)
+
+	require.Equal(t, "not json: [REDACTED]", string(JSON([]byte("not json: alice@example.com"))))
+}
+
+func TestSensitiveKeys(t *testing.T) {
+	for _, key := range []string{"password", "email", "access_token", "accesstoken", "refresh_token", "authorization"} {
+		require.True(t, sensitiveKeys[key], key)
+	}
+
+	require.False(t, sensitiveKeys["username"])
+}
@@ -0,0 +1,201 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/pkg/errors"
+)
+
+// Transfer describes a transfer to be evaluated and the client requesting it.
+type Transfer struct {
+	Username      string
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	ClientIP      string
+	UserAgent     string
+}
+
+// Decision is the outcome of a risk evaluation.
+type Decision struct {
+	// Outcome is one of db.RiskOutcomeAllow, db.RiskOutcomeReview or db.RiskOutcomeDeny.
+	Outcome string `json:"outcome"`
+	Reason  string `json:"reason"`
+}
+
+// Evaluator decides whether a transfer is executed, held for review or denied.
+type Evaluator interface {
+	Evaluate(ctx context.Context, transfer Transfer) (Decision, error)
+}
+
+// Config configures the built-in rules. A zero value disables a rule.
+type Config struct {
+	// VelocityWindow and VelocityMaxTransfers deny more than the maximum number
+	// of transfers from an account within the window.
+	VelocityWindow       time.Duration
+	VelocityMaxTransfers int64
+	// UnusualAmountFactor reviews transfers larger than this multiple of the account's average transfer.
+	UnusualAmountFactor int64
+	// UnusualAmountMinHistory is the number of transfers needed to know the average of an account.
+	UnusualAmountMinHistory int64
+	// NewPayeeReviewAmount reviews transfers of at least this amount to an account never paid before.
+	NewPayeeReviewAmount int64
+	// NewClientReviewAmount reviews transfers of at least this amount from an IP address
+	// and user agent the user never transferred from before.
+	NewClientReviewAmount int64
+}
+
+// RulesEvaluator is the built-in rules evaluator.
+type RulesEvaluator struct {
+	store  db.Querier
+	config Config
+}
+
+// NewRulesEvaluator creates a new rules evaluator.
+func NewRulesEvaluator(store db.Querier, config Config) *RulesEvaluator {
+	return &RulesEvaluator{
+		store:  store,
+		config: config,
+	}
+}
+
+// rule leads to its outcome when the check returns a reason.
+type rule struct {
+	outcome string
+	check   func(ctx context.Context, transfer Transfer) (string, error)
+}
+
+// Evaluate runs all rules. The most severe outcome wins and the reasons of all matched rules are kept.
+func (e *RulesEvaluator) Evaluate(ctx context.Context, transfer Transfer) (Decision, error) {
+	rules := []rule{
+		{outcome: db.RiskOutcomeDeny, check: e.checkVelocity},
+		{outcome: db.RiskOutcomeReview, check: e.checkUnusualAmount},
+		{outcome: db.RiskOutcomeReview, check: e.checkNewPayee},
+		{outcome: db.RiskOutcomeReview, check: e.checkNewClient},
+	}
+
+	decision := Decision{Outcome: db.RiskOutcomeAllow}
+
+	var reasons []string
+
+	for _, rule := range rules {
+		reason, err := rule.check(ctx, transfer)
+		if err != nil {
+			return decision, err
+		}
+
+		if reason == "" {
+			continue
+		}
+
+		reasons = append(reasons, reason)
+
+		if severity(rule.outcome) > severity(decision.Outcome) {
+			decision.Outcome = rule.outcome
+		}
+	}
+
+	decision.Reason = strings.Join(reasons, "; ")
+
+	return decision, nil
+}
+
+func (e *RulesEvaluator) checkVelocity(ctx context.Context, transfer Transfer) (string, error) {
+	if e.config.VelocityWindow <= 0 || e.config.VelocityMaxTransfers <= 0 {
+		return "", nil
+	}
+
+	count, err := e.store.CountTransfersSince(ctx, db.CountTransfersSinceParams{
+		FromAccountID: transfer.FromAccountID,
+		CreatedAt:     time.Now().Add(-e.config.VelocityWindow),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to count recent transfers")
+	}
+
+	if count < e.config.VelocityMaxTransfers {
+		return "", nil
+	}
+
+	return fmt.Sprintf("velocity: %d transfers within %s", count, e.config.VelocityWindow), nil
+}
+
+func (e *RulesEvaluator) checkUnusualAmount(ctx context.Context, transfer Transfer) (string, error) {
+	if e.config.UnusualAmountFactor <= 0 {
+		return "", nil
+	}
+
+	stats, err := e.store.GetTransferAmountStats(ctx, transfer.FromAccountID)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get transfer amount statistics")
+	}
+
+	if stats.Transfers < e.config.UnusualAmountMinHistory || stats.AverageAmount <= 0 {
+		return "", nil
+	}
+
+	if transfer.Amount <= stats.AverageAmount*e.config.UnusualAmountFactor {
+		return "", nil
+	}
+
+	return fmt.Sprintf("unusual amount: %d is more than %d times the average of %d",
+		transfer.Amount, e.config.UnusualAmountFactor, stats.AverageAmount), nil
+}
+
+func (e *RulesEvaluator) checkNewPayee(ctx context.Context, transfer Transfer) (string, error) {
+	if e.config.NewPayeeReviewAmount <= 0 || transfer.Amount < e.config.NewPayeeReviewAmount {
+		return "", nil
+	}
+
+	count, err := e.store.CountTransfersBetween(ctx, db.CountTransfersBetweenParams{
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to count transfers to payee")
+	}
+
+	if count > 0 {
+		return "", nil
+	}
+
+	// the reason is shown to the sender, who must not learn the internal id of the recipient's account
+	return "new payee: first transfer to the recipient account", nil
+}
+
+func (e *RulesEvaluator) checkNewClient(ctx context.Context, transfer Transfer) (string, error) {
+	if e.config.NewClientReviewAmount <= 0 || transfer.Amount < e.config.NewClientReviewAmount {
+		return "", nil
+	}
+
+	known, err := e.store.CountKnownClientTransfers(ctx, db.CountKnownClientTransfersParams{
+		ClientIp:  transfer.ClientIP,
+		UserAgent: transfer.UserAgent,
+		Username:  transfer.Username,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to count transfers from client")
+	}
+
+	// the very first transfer of a user has no known client to compare with
+	if known.Transfers == 0 || known.ClientTransfers > 0 {
+		return "", nil
+	}
+
+	return fmt.Sprintf("new client: first transfer from %s", transfer.ClientIP), nil
+}
+
+func severity(outcome string) int {
+	switch outcome {
+	case db.RiskOutcomeDeny:
+		return 2
+	case db.RiskOutcomeReview:
+		return 1
+	default:
+		return 0
+	}
+}
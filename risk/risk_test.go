@@ -0,0 +1,170 @@
+package risk
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/ifantsai/simple-bank-api/db/mock"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesEvaluator(t *testing.T) {
+	config := Config{
+		VelocityWindow:          10 * time.Minute,
+		VelocityMaxTransfers:    5,
+		UnusualAmountFactor:     10,
+		UnusualAmountMinHistory: 3,
+		NewPayeeReviewAmount:    1000,
+		NewClientReviewAmount:   500,
+	}
+
+	transfer := Transfer{
+		Username:      util.RandomOwner(),
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        100,
+		ClientIP:      "10.0.0.1",
+		UserAgent:     "test",
+	}
+
+	testCases := []struct {
+		name       string
+		amount     int64
+		buildStubs func(store *mockdb.MockStore)
+		outcome    string
+		reasons    int
+	}{
+		{
+			name:   "Allow",
+			amount: 100,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().
+					GetTransferAmountStats(gomock.Any(), gomock.Eq(transfer.FromAccountID)).
+					Times(1).
+					Return(db.GetTransferAmountStatsRow{Transfers: 10, AverageAmount: 100}, nil)
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CountKnownClientTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			outcome: db.RiskOutcomeAllow,
+		},
+		{
+			name:   "VelocityDenied",
+			amount: 100,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(5), nil)
+				store.EXPECT().
+					GetTransferAmountStats(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetTransferAmountStatsRow{}, nil)
+			},
+			outcome: db.RiskOutcomeDeny,
+			reasons: 1,
+		},
+		{
+			name:   "UnusualAmount",
+			amount: 600,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().
+					GetTransferAmountStats(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetTransferAmountStatsRow{Transfers: 3, AverageAmount: 50}, nil)
+				store.EXPECT().
+					CountKnownClientTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CountKnownClientTransfersRow{Transfers: 3, ClientTransfers: 3}, nil)
+			},
+			outcome: db.RiskOutcomeReview,
+			reasons: 1,
+		},
+		{
+			name:   "NotEnoughHistory",
+			amount: 600,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().
+					GetTransferAmountStats(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetTransferAmountStatsRow{Transfers: 2, AverageAmount: 50}, nil)
+				store.EXPECT().
+					CountKnownClientTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CountKnownClientTransfersRow{}, nil)
+			},
+			outcome: db.RiskOutcomeAllow,
+		},
+		{
+			name:   "NewPayeeAndNewClient",
+			amount: 1000,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().
+					GetTransferAmountStats(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetTransferAmountStatsRow{Transfers: 1, AverageAmount: 900}, nil)
+				store.EXPECT().
+					CountTransfersBetween(gomock.Any(), gomock.Eq(db.CountTransfersBetweenParams{
+						FromAccountID: transfer.FromAccountID,
+						ToAccountID:   transfer.ToAccountID,
+					})).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					CountKnownClientTransfers(gomock.Any(), gomock.Eq(db.CountKnownClientTransfersParams{
+						ClientIp:  transfer.ClientIP,
+						UserAgent: transfer.UserAgent,
+						Username:  transfer.Username,
+					})).
+					Times(1).
+					Return(db.CountKnownClientTransfersRow{Transfers: 4}, nil)
+			},
+			outcome: db.RiskOutcomeReview,
+			reasons: 2,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			evaluator := NewRulesEvaluator(store, config)
+
+			transfer := transfer
+			transfer.Amount = tc.amount
+
+			decision, err := evaluator.Evaluate(context.Background(), transfer)
+			require.NoError(t, err)
+			require.Equal(t, tc.outcome, decision.Outcome)
+
+			if tc.reasons == 0 {
+				require.Empty(t, decision.Reason)
+			} else {
+				require.Len(t, strings.Split(decision.Reason, "; "), tc.reasons)
+			}
+		})
+	}
+}
+
+func TestRulesEvaluatorDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// no rule queries the store when all rules are disabled
+	store := mockdb.NewMockStore(ctrl)
+
+	decision, err := NewRulesEvaluator(store, Config{}).Evaluate(context.Background(), Transfer{Amount: 1_000_000})
+	require.NoError(t, err)
+	require.Equal(t, Decision{Outcome: db.RiskOutcomeAllow}, decision)
+}
@@ -14,6 +14,12 @@ type Server interface {
 	Stop(ctx context.Context) error
 }
 
+// Drainer is a server which prepares for the shutdown before any server is stopped,
+// e.g. failing the readiness so that load balancers stop sending requests first.
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
 func Run(servers ...Server) {
 	for _, server := range servers {
 		go func(server Server) {
@@ -25,6 +31,14 @@ func Run(servers ...Server) {
 
 	if err := process.GracefulShutdown(
 		func(ctx context.Context) error {
+			for _, server := range servers {
+				if drainer, ok := server.(Drainer); ok {
+					if err := drainer.Drain(ctx); err != nil {
+						log.Println("cannot drain server:", err)
+					}
+				}
+			}
+
 			var errRet error
 			for _, server := range servers {
 				if err := server.Stop(ctx); err != nil {
@@ -0,0 +1,62 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var csvHeader = []string{
+	"entry_id", "date", "description", "category", "amount", "currency", "transfer_id", "reference", "counterparty",
+}
+
+type csvEncoder struct {
+	writer   *csv.Writer
+	currency string
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{writer: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Begin(statement Statement) error {
+	e.currency = statement.Account.Currency
+
+	return e.writer.Write(csvHeader)
+}
+
+func (e *csvEncoder) WriteEntry(entry Entry) error {
+	transferID := ""
+	if entry.TransferID.Valid {
+		transferID = strconv.FormatInt(entry.TransferID.Int64, 10)
+	}
+
+	return e.writer.Write([]string{
+		strconv.FormatInt(entry.ID, 10),
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		csvText(entry.Description),
+		csvText(entry.Category),
+		formatAmount(entry.Amount),
+		e.currency,
+		transferID,
+		csvText(entry.TransferReference),
+		entry.CounterpartyAccountNumber,
+	})
+}
+
+func (e *csvEncoder) End() error {
+	e.writer.Flush()
+
+	return e.writer.Error()
+}
+
+// csvText keeps spreadsheets from evaluating text as a formula.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
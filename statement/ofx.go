@@ -0,0 +1,154 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ifantsai/simple-bank-api/util"
+)
+
+const (
+	ofxTimeLayout = "20060102150405"
+	// ofxNameLength is the maximum length of the payee name of a transaction.
+	ofxNameLength = 32
+	// ofxRefNumLength is the maximum length of the reference number of a transaction.
+	ofxRefNumLength = 32
+	ofxMemoLength   = 255
+	ofxOrg          = "Simple Bank"
+	ofxBankID       = "SIMPLEBANK"
+	// intuitBankID identifies the bank to Quicken in QFX files.
+	intuitBankID = "00000"
+)
+
+// ofxHeader is the header of OFX 1.0.2, which is the version supported by most personal-finance software.
+const ofxHeader = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:USASCII
+CHARSET:1252
+COMPRESSION:NONE
+OLDFILEUID:NONE
+NEWFILEUID:NONE
+
+`
+
+var ofxEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+type ofxEncoder struct {
+	writer    *bufio.Writer
+	quicken   bool
+	statement Statement
+}
+
+func newOFXEncoder(w io.Writer, quicken bool) *ofxEncoder {
+	return &ofxEncoder{writer: bufio.NewWriter(w), quicken: quicken}
+}
+
+func (e *ofxEncoder) Begin(statement Statement) error {
+	e.statement = statement
+
+	accountType := "CHECKING"
+	if statement.Account.Type == util.Savings {
+		accountType = "SAVINGS"
+	}
+
+	e.printf(ofxHeader)
+	e.printf("<OFX>\n<SIGNONMSGSRSV1>\n<SONRS>\n")
+	e.printf("<STATUS>\n<CODE>0\n<SEVERITY>INFO\n</STATUS>\n")
+	e.printf("<DTSERVER>%s\n<LANGUAGE>ENG\n", ofxTime(statement.GeneratedAt))
+	e.printf("<FI>\n<ORG>%s\n<FID>%s\n</FI>\n", ofxOrg, ofxBankID)
+
+	if e.quicken {
+		e.printf("<INTU.BID>%s\n", intuitBankID)
+	}
+
+	e.printf("</SONRS>\n</SIGNONMSGSRSV1>\n")
+	e.printf("<BANKMSGSRSV1>\n<STMTTRNRS>\n<TRNUID>0\n")
+	e.printf("<STATUS>\n<CODE>0\n<SEVERITY>INFO\n</STATUS>\n")
+	e.printf("<STMTRS>\n<CURDEF>%s\n", statement.Account.Currency)
+	e.printf("<BANKACCTFROM>\n<BANKID>%s\n<ACCTID>%s\n<ACCTTYPE>%s\n</BANKACCTFROM>\n",
+		ofxBankID, statement.Account.Number, accountType)
+	e.printf("<BANKTRANLIST>\n<DTSTART>%s\n<DTEND>%s\n",
+		ofxTime(statement.StartTime), ofxTime(statement.EndTime))
+
+	return nil
+}
+
+func (e *ofxEncoder) WriteEntry(entry Entry) error {
+	transactionType := "CREDIT"
+	if entry.Amount < 0 {
+		transactionType = "DEBIT"
+	}
+
+	if entry.Category == feeCategory {
+		transactionType = "FEE"
+	}
+
+	name := entry.Description
+	if name == "" {
+		name = entry.Category
+	}
+
+	e.printf("<STMTTRN>\n")
+	e.printf("<TRNTYPE>%s\n<DTPOSTED>%s\n<TRNAMT>%s\n<FITID>%d\n",
+		transactionType, ofxTime(entry.CreatedAt), formatAmount(entry.Amount), entry.ID)
+
+	if entry.TransferReference != "" {
+		e.printf("<REFNUM>%s\n", ofxText(entry.TransferReference, ofxRefNumLength))
+	}
+
+	if name != "" {
+		e.printf("<NAME>%s\n", ofxText(name, ofxNameLength))
+	}
+
+	// the counterparty goes into the memo, since BANKACCTTO needs the type of its account
+	memo := entry.Category
+	if entry.CounterpartyAccountNumber != "" {
+		if memo != "" {
+			memo += "; "
+		}
+
+		memo += "counterparty " + entry.CounterpartyAccountNumber
+	}
+
+	if memo != "" {
+		e.printf("<MEMO>%s\n", ofxText(memo, ofxMemoLength))
+	}
+
+	e.printf("</STMTTRN>\n")
+
+	return nil
+}
+
+func (e *ofxEncoder) End() error {
+	e.printf("</BANKTRANLIST>\n")
+	// the ledger balance is the current balance of the account
+	e.printf("<LEDGERBAL>\n<BALAMT>%s\n<DTASOF>%s\n</LEDGERBAL>\n",
+		formatAmount(e.statement.Account.Balance), ofxTime(e.statement.GeneratedAt))
+	e.printf("</STMTRS>\n</STMTTRNRS>\n</BANKMSGSRSV1>\n</OFX>\n")
+
+	return e.writer.Flush()
+}
+
+// printf writes to the buffer, whose first error is returned by Flush.
+func (e *ofxEncoder) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(e.writer, format, args...)
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format(ofxTimeLayout) + "[0:GMT]"
+}
+
+// ofxText escapes text and truncates it to n characters, since OFX 1.0.2 is ASCII only.
+func ofxText(s string, n int) string {
+	ascii := []rune(toASCII(s))
+	if len(ascii) > n {
+		ascii = ascii[:n]
+	}
+
+	return ofxEscaper.Replace(string(ascii))
+}
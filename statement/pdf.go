@@ -0,0 +1,238 @@
+package statement
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Layout of the A4 pages in points.
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 9
+	pdfLineHeight   = 14
+	pdfTitleSize    = 14
+	pdfDescription  = 22
+	pdfReference    = 14
+	pdfCategoryText = 12
+)
+
+// Columns of the entries table.
+const (
+	pdfDateX         = pdfMargin
+	pdfDescriptionX  = 130
+	pdfReferenceX    = 245
+	pdfCounterpartyX = 320
+	pdfCategoryX     = 425
+	pdfAmountRight   = pdfPageWidth - pdfMargin
+)
+
+// Object numbers written before the pages, the page tree is written last since it lists all pages.
+const (
+	pdfCatalogObject  = 1
+	pdfPagesObject    = 2
+	pdfFontObject     = 3
+	pdfBoldFontObject = 4
+	pdfFirstPage      = 5
+)
+
+var pdfEscaper = strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+
+// pdfEncoder writes a PDF with the standard Helvetica font, so that no font needs to be embedded.
+// Every page is written as soon as it is full.
+type pdfEncoder struct {
+	writer    *countingWriter
+	offsets   map[int]int64
+	pages     []int
+	content   bytes.Buffer
+	y         int
+	statement Statement
+}
+
+func newPDFEncoder(w io.Writer) *pdfEncoder {
+	return &pdfEncoder{
+		writer:  &countingWriter{writer: bufio.NewWriter(w)},
+		offsets: make(map[int]int64),
+	}
+}
+
+func (e *pdfEncoder) Begin(statement Statement) error {
+	e.statement = statement
+
+	e.printf("%%PDF-1.4\n")
+	e.object(pdfCatalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pdfPagesObject))
+	e.object(pdfFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	e.object(pdfBoldFontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	e.newPage()
+
+	account := statement.Account
+	e.text("F2", pdfTitleSize, pdfMargin, e.y, "Account Statement")
+	e.y -= 2 * pdfLineHeight
+	e.text("F1", pdfFontSize, pdfMargin, e.y, fmt.Sprintf("Account: %s (%s)", account.Number, account.Currency))
+	e.y -= pdfLineHeight
+	e.text("F1", pdfFontSize, pdfMargin, e.y, "Owner: "+account.Owner)
+	e.y -= pdfLineHeight
+	e.text("F1", pdfFontSize, pdfMargin, e.y, fmt.Sprintf("Period: %s - %s",
+		statement.StartTime.UTC().Format(time.RFC3339), statement.EndTime.UTC().Format(time.RFC3339)))
+	e.y -= pdfLineHeight
+	e.text("F1", pdfFontSize, pdfMargin, e.y, fmt.Sprintf("Balance: %s as of %s",
+		formatAmount(account.Balance), statement.GeneratedAt.UTC().Format(time.RFC3339)))
+	e.y -= 2 * pdfLineHeight
+
+	e.tableHeader()
+
+	return e.writer.err
+}
+
+func (e *pdfEncoder) WriteEntry(entry Entry) error {
+	if e.y < pdfMargin+pdfLineHeight {
+		e.endPage()
+		e.newPage()
+		e.tableHeader()
+	}
+
+	amount := formatAmount(entry.Amount)
+
+	e.text("F1", pdfFontSize, pdfDateX, e.y, entry.CreatedAt.UTC().Format("2006-01-02 15:04"))
+	e.text("F1", pdfFontSize, pdfDescriptionX, e.y, truncate(entry.Description, pdfDescription))
+	e.text("F1", pdfFontSize, pdfReferenceX, e.y, truncate(entry.TransferReference, pdfReference))
+	e.text("F1", pdfFontSize, pdfCounterpartyX, e.y, entry.CounterpartyAccountNumber)
+	e.text("F1", pdfFontSize, pdfCategoryX, e.y, truncate(entry.Category, pdfCategoryText))
+	e.text("F1", pdfFontSize, pdfAmountRight-amountWidth(amount, pdfFontSize), e.y, amount)
+	e.y -= pdfLineHeight
+
+	return e.writer.err
+}
+
+func (e *pdfEncoder) End() error {
+	e.endPage()
+
+	kids := make([]string, 0, len(e.pages))
+	for _, page := range e.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+
+	e.object(pdfPagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>",
+		strings.Join(kids, " "), len(e.pages)))
+
+	size := pdfFirstPage + 2*len(e.pages)
+	xref := e.writer.count
+
+	e.printf("xref\n0 %d\n0000000000 65535 f \n", size)
+
+	for object := 1; object < size; object++ {
+		e.printf("%010d 00000 n \n", e.offsets[object])
+	}
+
+	e.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", size, pdfCatalogObject, xref)
+
+	if e.writer.err != nil {
+		return e.writer.err
+	}
+
+	return e.writer.writer.Flush()
+}
+
+func (e *pdfEncoder) newPage() {
+	e.content.Reset()
+	e.y = pdfPageHeight - pdfMargin
+}
+
+func (e *pdfEncoder) tableHeader() {
+	e.text("F2", pdfFontSize, pdfDateX, e.y, "Date (UTC)")
+	e.text("F2", pdfFontSize, pdfDescriptionX, e.y, "Description")
+	e.text("F2", pdfFontSize, pdfReferenceX, e.y, "Reference")
+	e.text("F2", pdfFontSize, pdfCounterpartyX, e.y, "Counterparty")
+	e.text("F2", pdfFontSize, pdfCategoryX, e.y, "Category")
+	e.text("F2", pdfFontSize, pdfAmountRight-amountWidth("Amount", pdfFontSize), e.y, "Amount")
+	fmt.Fprintf(&e.content, "%d %d m %d %d l S\n", pdfMargin, e.y-4, pdfAmountRight, e.y-4)
+	e.y -= pdfLineHeight + 4
+}
+
+// endPage writes the content and the page object of the current page.
+func (e *pdfEncoder) endPage() {
+	pageNumber := len(e.pages) + 1
+	footer := fmt.Sprintf("Page %d", pageNumber)
+	e.text("F1", pdfFontSize, pdfAmountRight-amountWidth(footer, pdfFontSize), pdfMargin/2, footer)
+
+	contentObject := pdfFirstPage + 2*len(e.pages)
+	pageObject := contentObject + 1
+
+	e.object(contentObject, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", e.content.Len(), e.content.String()))
+	e.object(pageObject, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Contents %d 0 R "+
+			"/Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> >>",
+		pdfPagesObject, pdfPageWidth, pdfPageHeight, contentObject, pdfFontObject, pdfBoldFontObject,
+	))
+
+	e.pages = append(e.pages, pageObject)
+}
+
+// text adds a line of text at the position to the current page.
+func (e *pdfEncoder) text(font string, size, x, y int, s string) {
+	fmt.Fprintf(&e.content, "BT /%s %d Tf %d %d Td (%s) Tj ET\n", font, size, x, y, pdfEscaper.Replace(toASCII(s)))
+}
+
+func (e *pdfEncoder) object(number int, body string) {
+	e.offsets[number] = e.writer.count
+	e.printf("%d 0 obj\n%s\nendobj\n", number, body)
+}
+
+// printf writes to the output, whose first error is kept by the counting writer.
+func (e *pdfEncoder) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(e.writer, format, args...)
+}
+
+// helveticaWidths are the widths of the characters of amounts in thousandths of the font size.
+var helveticaWidths = map[rune]int{'-': 333, '.': 278, 'A': 667, 'm': 833, 'o': 556, 'u': 556, 'n': 556, 't': 278}
+
+// amountWidth returns the width of an amount written in Helvetica, used to align amounts to the right.
+func amountWidth(s string, size int) int {
+	width := 0
+
+	for _, r := range s {
+		w, ok := helveticaWidths[r]
+		if !ok {
+			// digits and most lowercase letters
+			w = 556
+		}
+
+		width += w
+	}
+
+	return width * size / 1000
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n-3]) + "..."
+}
+
+// countingWriter counts the written bytes for the cross-reference table and keeps the first error.
+type countingWriter struct {
+	writer *bufio.Writer
+	count  int64
+	err    error
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+
+	n, err := w.writer.Write(p)
+	w.count += int64(n)
+	w.err = err
+
+	return n, err
+}
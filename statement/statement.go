@@ -0,0 +1,168 @@
+// Package statement renders the account statements downloaded by account holders.
+package statement
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+)
+
+// Formats of statements.
+const (
+	FormatCSV = "csv"
+	FormatOFX = "ofx"
+	// FormatQFX is OFX with the Intuit extensions expected by Quicken.
+	FormatQFX = "qfx"
+	FormatPDF = "pdf"
+)
+
+// feeCategory is the category of the entries of transfer fees.
+const feeCategory = "fee"
+
+// pageSize is the number of entries read from the database at once while exporting.
+const pageSize = 500
+
+// IsSupportedFormat returns true if statements can be rendered in the format.
+func IsSupportedFormat(format string) bool {
+	switch format {
+	case FormatCSV, FormatOFX, FormatQFX, FormatPDF:
+		return true
+	}
+
+	return false
+}
+
+// ContentType returns the MIME type of a statement format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatQFX:
+		return "application/vnd.intu.qfx"
+	case FormatPDF:
+		return "application/pdf"
+	}
+
+	return "application/octet-stream"
+}
+
+// Statement describes the entries of an account within a period.
+type Statement struct {
+	Account db.Account
+	// StartTime is inclusive and EndTime is exclusive.
+	StartTime   time.Time
+	EndTime     time.Time
+	GeneratedAt time.Time
+}
+
+// FileName returns the name of the downloaded statement file.
+func (s Statement) FileName(format string) string {
+	return fmt.Sprintf("statement-%s-%s-%s.%s",
+		s.Account.Number,
+		s.StartTime.Format("20060102"),
+		s.EndTime.Format("20060102"),
+		format,
+	)
+}
+
+// Entry is an entry of a statement with the reference and the counterparty of the transfer it was posted for.
+// Both are empty for entries which don't belong to a transfer.
+type Entry = db.ListEntriesRow
+
+// Encoder renders a statement entry by entry, so that large statements are streamed.
+type Encoder interface {
+	Begin(statement Statement) error
+	WriteEntry(entry Entry) error
+	// End writes the rest of the statement and flushes it.
+	End() error
+}
+
+// NewEncoder creates an encoder of the format which writes to w.
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVEncoder(w), nil
+	case FormatOFX:
+		return newOFXEncoder(w, false), nil
+	case FormatQFX:
+		return newOFXEncoder(w, true), nil
+	case FormatPDF:
+		return newPDFEncoder(w), nil
+	}
+
+	return nil, fmt.Errorf("unsupported statement format %q", format)
+}
+
+// Export renders the statement in the format, reading its entries page by page in the order of their ids.
+func Export(ctx context.Context, store db.Store, statement Statement, format string, w io.Writer) error {
+	encoder, err := NewEncoder(format, w)
+	if err != nil {
+		return err
+	}
+
+	if err := encoder.Begin(statement); err != nil {
+		return err
+	}
+
+	arg := db.ListEntriesParams{
+		AccountID: statement.Account.ID,
+		StartTime: sql.NullTime{Time: statement.StartTime, Valid: true},
+		EndTime:   sql.NullTime{Time: statement.EndTime, Valid: true},
+		Limit:     pageSize,
+	}
+
+	for {
+		entries, err := store.ListEntries(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			if err := encoder.WriteEntry(entry); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < pageSize {
+			break
+		}
+
+		arg.AfterID = entries[len(entries)-1].ID
+	}
+
+	return encoder.End()
+}
+
+// formatAmount formats an amount in minor units with two decimals, e.g. -1234 as -12.34.
+func formatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// toASCII replaces the characters OFX and the standard PDF fonts cannot represent.
+func toASCII(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+
+		if r < ' ' || r > '~' {
+			return '?'
+		}
+
+		return r
+	}, s)
+}
@@ -0,0 +1,226 @@
+package statement
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/ifantsai/simple-bank-api/db/mock"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/util"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement() Statement {
+	return Statement{
+		Account: db.Account{
+			ID:       7,
+			Number:   "SB0512345678901234",
+			Owner:    "alice",
+			Balance:  123456,
+			Currency: util.USD,
+			Type:     util.Savings,
+		},
+		StartTime:   time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC),
+		GeneratedAt: time.Date(2022, 11, 2, 8, 30, 0, 0, time.UTC),
+	}
+}
+
+func testEntries(n int) []Entry {
+	entries := make([]Entry, 0, n)
+
+	for i := 1; i <= n; i++ {
+		amount := int64(i * 100)
+		if i%2 == 0 {
+			amount = -amount
+		}
+
+		entries = append(entries, Entry{
+			ID:          int64(i),
+			AccountID:   7,
+			Amount:      amount,
+			Description: fmt.Sprintf("Rent & <utilities> #%d", i),
+			Category:    "housing",
+			CreatedAt:   time.Date(2022, 10, 2, 12, 0, i, 0, time.UTC),
+		})
+	}
+
+	return entries
+}
+
+func encode(t *testing.T, format string, entries []Entry) string {
+	var buf bytes.Buffer
+
+	encoder, err := NewEncoder(format, &buf)
+	require.NoError(t, err)
+	require.NoError(t, encoder.Begin(testStatement()))
+
+	for _, entry := range entries {
+		require.NoError(t, encoder.WriteEntry(entry))
+	}
+
+	require.NoError(t, encoder.End())
+
+	return buf.String()
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "0.00", formatAmount(0))
+	require.Equal(t, "0.05", formatAmount(5))
+	require.Equal(t, "12.34", formatAmount(1234))
+	require.Equal(t, "-12.34", formatAmount(-1234))
+	require.Equal(t, "-0.99", formatAmount(-99))
+}
+
+// withTransfer links the entry to a transfer with the reference and the counterparty.
+func withTransfer(entry Entry, transferID int64, reference, counterparty string) Entry {
+	entry.TransferID = sql.NullInt64{Int64: transferID, Valid: true}
+	entry.TransferReference = reference
+	entry.CounterpartyAccountNumber = counterparty
+
+	return entry
+}
+
+func TestCSV(t *testing.T) {
+	entries := testEntries(2)
+	entries[0] = withTransfer(entries[0], 42, "=invoice 7", "SB0598765432109876")
+	entries[1].Description = "=HYPERLINK(\"http://example.com\")"
+
+	records, err := csv.NewReader(strings.NewReader(encode(t, FormatCSV, entries))).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		csvHeader,
+		{"1", "2022-10-02T12:00:01Z", "Rent & <utilities> #1", "housing", "1.00", "USD",
+			"42", "'=invoice 7", "SB0598765432109876"},
+		{"2", "2022-10-02T12:00:02Z", "'=HYPERLINK(\"http://example.com\")", "housing", "-2.00", "USD", "", "", ""},
+	}, records)
+}
+
+func TestOFX(t *testing.T) {
+	entries := testEntries(2)
+	entries[0] = withTransfer(entries[0], 42, "invoice <7>", "SB0598765432109876")
+	entries[1].Category = feeCategory
+
+	ofx := encode(t, FormatOFX, entries)
+	require.True(t, strings.HasPrefix(ofx, "OFXHEADER:100\n"))
+	require.Contains(t, ofx, "<ACCTID>SB0512345678901234\n<ACCTTYPE>SAVINGS\n")
+	require.Contains(t, ofx, "<DTSTART>20221001000000[0:GMT]\n<DTEND>20221101000000[0:GMT]\n")
+	require.Contains(t, ofx, "<TRNTYPE>CREDIT\n<DTPOSTED>20221002120001[0:GMT]\n<TRNAMT>1.00\n<FITID>1\n")
+	require.Contains(t, ofx, "<TRNTYPE>FEE\n<DTPOSTED>20221002120002[0:GMT]\n<TRNAMT>-2.00\n<FITID>2\n")
+	require.Contains(t, ofx, "<FITID>1\n<REFNUM>invoice &lt;7&gt;\n<NAME>Rent &amp; &lt;utilities&gt; #1\n")
+	require.Contains(t, ofx, "<MEMO>housing; counterparty SB0598765432109876\n")
+	require.Contains(t, ofx, "<FITID>2\n<NAME>Rent &amp; &lt;utilities&gt; #2\n<MEMO>fee\n")
+	require.Contains(t, ofx, "<LEDGERBAL>\n<BALAMT>1234.56\n<DTASOF>20221102083000[0:GMT]\n</LEDGERBAL>\n")
+	require.NotContains(t, ofx, "INTU.BID")
+	require.True(t, strings.HasSuffix(ofx, "</OFX>\n"))
+
+	qfx := encode(t, FormatQFX, entries)
+	require.Contains(t, qfx, "<INTU.BID>"+intuitBankID+"\n")
+}
+
+func TestOFXText(t *testing.T) {
+	require.Equal(t, "Caf? &amp; Co", ofxText("Café & Co", ofxNameLength))
+	require.Equal(t, "abc", ofxText("abcdef", 3))
+	require.Equal(t, "a b", ofxText("a\nb", 3))
+}
+
+// requireValidPDF checks that the cross-reference table points to the objects.
+func requireValidPDF(t *testing.T, pdf string) {
+	t.Helper()
+
+	require.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	require.Len(t, startxref, 2)
+
+	xref, err := strconv.Atoi(startxref[1])
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(pdf[xref:], "xref\n"))
+
+	lines := strings.Split(pdf[xref:], "\n")
+	size, err := strconv.Atoi(strings.Fields(lines[1])[1])
+	require.NoError(t, err)
+
+	for object := 1; object < size; object++ {
+		offset, err := strconv.Atoi(lines[2+object][:10])
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj\n", object)), "object %d", object)
+	}
+}
+
+func TestPDF(t *testing.T) {
+	entries := testEntries(3)
+	entries[0] = withTransfer(entries[0], 42, "invoice (7)", "SB0598765432109876")
+
+	pdf := encode(t, FormatPDF, entries)
+	requireValidPDF(t, pdf)
+	require.Contains(t, pdf, "/Count 1")
+	require.Contains(t, pdf, "(Account: SB0512345678901234 \\(USD\\)) Tj")
+	require.Contains(t, pdf, "(Rent & <utilities> #3) Tj")
+	require.Contains(t, pdf, "(-2.00) Tj")
+	require.Contains(t, pdf, "(invoice \\(7\\)) Tj")
+	require.Contains(t, pdf, "(SB0598765432109876) Tj")
+
+	pdf = encode(t, FormatPDF, testEntries(200))
+	requireValidPDF(t, pdf)
+	require.Contains(t, pdf, "/Count 5")
+	require.Contains(t, pdf, "(Page 5) Tj")
+
+	requireValidPDF(t, encode(t, FormatPDF, nil))
+}
+
+func TestExport(t *testing.T) {
+	statement := testStatement()
+	entries := testEntries(pageSize + 1)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().
+			ListEntries(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.ListEntriesParams) ([]Entry, error) {
+				require.Equal(t, statement.Account.ID, arg.AccountID)
+				require.Equal(t, sql.NullTime{Time: statement.StartTime, Valid: true}, arg.StartTime)
+				require.Equal(t, sql.NullTime{Time: statement.EndTime, Valid: true}, arg.EndTime)
+				require.Equal(t, int32(pageSize), arg.Limit)
+				require.Zero(t, arg.AfterID)
+
+				return entries[:pageSize], nil
+			}),
+		store.EXPECT().
+			ListEntries(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.ListEntriesParams) ([]Entry, error) {
+				require.Equal(t, entries[pageSize-1].ID, arg.AfterID)
+
+				return entries[pageSize:], nil
+			}),
+	)
+
+	var buf bytes.Buffer
+	require.NoError(t, Export(context.Background(), store, statement, FormatCSV, &buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, pageSize+2)
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	require.False(t, IsSupportedFormat("xlsx"))
+
+	_, err := NewEncoder("xlsx", &bytes.Buffer{})
+	require.Error(t, err)
+}
@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// stdoutSpan is a span written as a line of JSON.
+type stdoutSpan struct {
+	Name         string                 `json:"name"`
+	Kind         string                 `json:"kind"`
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	StartTime    time.Time              `json:"start_time"`
+	Duration     time.Duration          `json:"duration"`
+	Status       string                 `json:"status"`
+	Description  string                 `json:"description,omitempty"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// StdoutExporter writes every span as a line of JSON, e.g. for local development.
+type StdoutExporter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewStdoutExporter creates a new exporter writing to w.
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{encoder: json.NewEncoder(w)}
+}
+
+// ExportSpans implements sdktrace.SpanExporter.
+func (e *StdoutExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, span := range spans {
+		out := stdoutSpan{
+			Name:        span.Name(),
+			Kind:        span.SpanKind().String(),
+			TraceID:     span.SpanContext().TraceID().String(),
+			SpanID:      span.SpanContext().SpanID().String(),
+			StartTime:   span.StartTime(),
+			Duration:    span.EndTime().Sub(span.StartTime()),
+			Status:      span.Status().Code.String(),
+			Description: span.Status().Description,
+		}
+
+		if span.Parent().IsValid() {
+			out.ParentSpanID = span.Parent().SpanID().String()
+		}
+
+		if attributes := span.Attributes(); len(attributes) > 0 {
+			out.Attributes = make(map[string]interface{}, len(attributes))
+			for _, attribute := range attributes {
+				out.Attributes[string(attribute.Key)] = attribute.Value.AsInterface()
+			}
+		}
+
+		if err := e.encoder.Encode(out); err != nil {
+			return errors.Wrap(err, "failed to write span")
+		}
+	}
+
+	return nil
+}
+
+// Shutdown implements sdktrace.SpanExporter.
+func (e *StdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+// Exporters of spans.
+const (
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const serviceName = "simple-bank-api"
+
+// Provider records the spans of the application and exports them in batches.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+	exporter string
+}
+
+// NewProvider sets up the W3C trace context propagation and the global tracer provider.
+// An empty exporter only propagates the trace context of requests without recording spans.
+// The OTLP exporter sends spans to a collector at the endpoint over gRPC.
+func NewProvider(exporter string, endpoint string, sampleRatio float64) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if exporter == "" {
+		return &Provider{}, nil
+	}
+
+	spanExporter, err := newExporter(exporter, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return &Provider{
+		provider: provider,
+		exporter: exporter,
+	}, nil
+}
+
+func newExporter(exporter string, endpoint string) (sdktrace.SpanExporter, error) {
+	switch exporter {
+	case ExporterStdout:
+		return NewStdoutExporter(os.Stdout), nil
+	case ExporterOTLP:
+		spanExporter, err := otlptracegrpc.New(context.Background(),
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+
+		return spanExporter, errors.Wrap(err, "cannot create OTLP exporter")
+	default:
+		return nil, errors.Errorf("unsupported tracing exporter %q", exporter)
+	}
+}
+
+// Start does nothing, spans are exported in the background once recorded.
+func (p *Provider) Start() error {
+	if p.provider != nil {
+		log.Println("tracing exports spans to", p.exporter)
+	}
+
+	return nil
+}
+
+// Stop exports the remaining spans.
+func (p *Provider) Stop(ctx context.Context) error {
+	if p.provider == nil {
+		return nil
+	}
+
+	return errors.Wrap(p.provider.Shutdown(ctx), "failed to shutdown tracer provider")
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestStdoutExporter(t *testing.T) {
+	var buf bytes.Buffer
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(NewStdoutExporter(&buf)))
+	tracer := provider.Tracer("test")
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	_, child := tracer.Start(ctx, "child")
+	child.SetAttributes(attribute.String("db.operation", "GetAccount"))
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+	parent.End()
+
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var childSpan, parentSpan stdoutSpan
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &childSpan))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &parentSpan))
+
+	require.Equal(t, "child", childSpan.Name)
+	require.Equal(t, parentSpan.TraceID, childSpan.TraceID)
+	require.Equal(t, parentSpan.SpanID, childSpan.ParentSpanID)
+	require.Equal(t, "Error", childSpan.Status)
+	require.Equal(t, "failed", childSpan.Description)
+	require.Equal(t, "GetAccount", childSpan.Attributes["db.operation"])
+	require.Empty(t, parentSpan.ParentSpanID)
+}
+
+func TestNewProvider(t *testing.T) {
+	provider, err := NewProvider("", "", 1)
+	require.NoError(t, err)
+	require.NoError(t, provider.Start())
+	require.NoError(t, provider.Stop(context.Background()))
+
+	_, err = NewProvider("jaeger", "", 1)
+	require.Error(t, err)
+}
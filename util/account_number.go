@@ -0,0 +1,53 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/IfanTsai/go-lib/utils/randutils"
+)
+
+// Account numbers are IBAN-like: a country code, two check digits and a basic account number of digits.
+const (
+	AccountNumberCountryCode = "SB"
+	AccountNumberLength      = 16
+)
+
+// AccountNumberChecksum computes the ISO 7064 mod-97 checksum of an account number.
+// The checksum of a valid account number is 1.
+func AccountNumberChecksum(number string) int {
+	// the country code and check digits are moved to the end, letters count as 10 to 35
+	rearranged := number[4:] + number[:4]
+
+	checksum := 0
+
+	for _, r := range rearranged {
+		switch {
+		case r >= '0' && r <= '9':
+			checksum = (checksum*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			checksum = (checksum*100 + int(r-'A') + 10) % 97
+		default:
+			return -1
+		}
+	}
+
+	return checksum
+}
+
+// NewAccountNumber returns the account number with the check digits of the basic account number.
+func NewAccountNumber(bban string) string {
+	checkDigits := 98 - AccountNumberChecksum(AccountNumberCountryCode+"00"+bban)
+
+	return fmt.Sprintf("%s%02d%s", AccountNumberCountryCode, checkDigits, bban)
+}
+
+// RandomAccountNumber generates a random valid account number.
+func RandomAccountNumber() string {
+	bban := ""
+	for len(bban) < AccountNumberLength-4 {
+		bban += strconv.FormatInt(randutils.RandomInt(0, 9), 10)
+	}
+
+	return NewAccountNumber(bban)
+}
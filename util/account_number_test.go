@@ -0,0 +1,27 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountNumberChecksum(t *testing.T) {
+	// a published IBAN example, the checksum does not depend on the country code
+	require.Equal(t, 1, AccountNumberChecksum("GB82WEST12345698765432"))
+	require.NotEqual(t, 1, AccountNumberChecksum("GB82WEST12345698765423"))
+	require.Equal(t, -1, AccountNumberChecksum("SB12345678901234-"))
+}
+
+func TestNewAccountNumber(t *testing.T) {
+	number := NewAccountNumber("000000000001")
+	require.Len(t, number, AccountNumberLength)
+	require.Equal(t, AccountNumberCountryCode, number[:2])
+	require.Equal(t, 1, AccountNumberChecksum(number))
+
+	for i := 0; i < 100; i++ {
+		number := RandomAccountNumber()
+		require.Len(t, number, AccountNumberLength)
+		require.Equal(t, 1, AccountNumberChecksum(number))
+	}
+}
@@ -0,0 +1,26 @@
+package util
+
+// Constants for all supported account types.
+const (
+	Checking = "checking"
+	Savings  = "savings"
+)
+
+// IsSupportedAccountType returns true if the account type can be opened by users.
+func IsSupportedAccountType(accountType string) bool {
+	switch accountType {
+	case Checking, Savings:
+		return true
+	}
+
+	return false
+}
+
+// Account types owned by the bank, which cannot be opened by users.
+const (
+	InterestExpense = "interest_expense"
+	FeeRevenue      = "fee_revenue"
+)
+
+// BankUsername is the owner of all bank-owned accounts.
+const BankUsername = "bank"
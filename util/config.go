@@ -14,9 +14,72 @@ type Config struct {
 	DBMigrationURL       string        `mapstructure:"DB_MIGRATION_URL"`
 	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
 	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	AdminServerAddress   string        `mapstructure:"ADMIN_SERVER_ADDRESS"`
 	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	// MaxAccountsPerCurrency limits how many accounts a user can open in one currency.
+	// It defaults to one account per currency, more must be allowed explicitly.
+	MaxAccountsPerCurrency int64 `mapstructure:"MAX_ACCOUNTS_PER_CURRENCY"`
+	// SavingsInterestRateBps is the annual interest rate in basis points of savings accounts without their own rate.
+	SavingsInterestRateBps int32 `mapstructure:"SAVINGS_INTEREST_RATE_BPS"`
+	// InterestCheckInterval is how often the interest scheduler checks for completed days, 0 disables it.
+	InterestCheckInterval time.Duration `mapstructure:"INTEREST_CHECK_INTERVAL"`
+	// FeeRulesPath is the JSON file of transfer fee rules, empty means no fees are charged.
+	FeeRulesPath string `mapstructure:"FEE_RULES_PATH"`
+	// Risk rules of transfers, 0 disables a rule.
+	RiskVelocityWindow          time.Duration `mapstructure:"RISK_VELOCITY_WINDOW"`
+	RiskVelocityMaxTransfers    int64         `mapstructure:"RISK_VELOCITY_MAX_TRANSFERS"`
+	RiskUnusualAmountFactor     int64         `mapstructure:"RISK_UNUSUAL_AMOUNT_FACTOR"`
+	RiskUnusualAmountMinHistory int64         `mapstructure:"RISK_UNUSUAL_AMOUNT_MIN_HISTORY"`
+	RiskNewPayeeReviewAmount    int64         `mapstructure:"RISK_NEW_PAYEE_REVIEW_AMOUNT"`
+	RiskNewClientReviewAmount   int64         `mapstructure:"RISK_NEW_CLIENT_REVIEW_AMOUNT"`
+	// OutboxRelayInterval is how often the outbox relay publishes domain events, 0 disables it.
+	OutboxRelayInterval time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
+	// OutboxMaxAttempts is how often an event is published before it is set aside, 0 retries it forever.
+	OutboxMaxAttempts int32 `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	// OutboxWebhookURL and OutboxFilePath configure the sinks of domain events, empty disables a sink.
+	OutboxWebhookURL string `mapstructure:"OUTBOX_WEBHOOK_URL"`
+	OutboxFilePath   string `mapstructure:"OUTBOX_FILE_PATH"`
+	// WebhookDeliveryInterval is how often due webhook deliveries are attempted, 0 disables delivery.
+	WebhookDeliveryInterval time.Duration `mapstructure:"WEBHOOK_DELIVERY_INTERVAL"`
+	// TracingExporter exports spans to stdout or an OTLP collector at TracingOTLPEndpoint, empty disables it.
+	TracingExporter     string  `mapstructure:"TRACING_EXPORTER"`
+	TracingOTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	TracingSampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	// LogLevel is debug, info, warn or error. LogBodySampleRatio is the ratio of failed requests logged with their bodies.
+	LogLevel           string  `mapstructure:"LOG_LEVEL"`
+	LogBodySampleRatio float64 `mapstructure:"LOG_BODY_SAMPLE_RATIO"`
+	// ShutdownDrainDelay is how long the server reports not ready before it stops, so that load balancers drain it.
+	ShutdownDrainDelay time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
+	// RateLimitDefault is the policy of the methods without one in RateLimitPolicies, e.g. "100/m:20", empty means unlimited.
+	// RateLimitPolicies are comma separated policies of methods, e.g. "LoginUser=5/m,CreateUser=3/m".
+	// RateLimitStore keeps the buckets in memory or in postgres, which is shared by the replicas.
+	RateLimitDefault  string `mapstructure:"RATE_LIMIT_DEFAULT"`
+	RateLimitPolicies string `mapstructure:"RATE_LIMIT_POLICIES"`
+	RateLimitStore    string `mapstructure:"RATE_LIMIT_STORE"`
+	// TrustedProxies are comma separated CIDRs or IPs of the proxies, e.g. the gateway and load balancers,
+	// whose X-Forwarded-For is trusted to find the client IP of a request for rate limiting and sessions.
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+	// TLSCertFile and TLSKeyFile are the certificate of the gRPC and gateway servers, empty serves plaintext.
+	// TLSClientCAFile verifies the certificates the gRPC clients must present, empty doesn't require them.
+	// The files are reloaded when they change.
+	TLSCertFile     string `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile      string `mapstructure:"TLS_KEY_FILE"`
+	TLSClientCAFile string `mapstructure:"TLS_CLIENT_CA_FILE"`
+	// ServerRole is all, grpc, which runs the gRPC server and the workers without the gateway,
+	// or gateway, which runs only the gateway in proxy mode without a database. Empty means all.
+	ServerRole string `mapstructure:"SERVER_ROLE"`
+	// GatewayMode is in-process or proxy, which proxies to the gRPC server at GatewayGRPCTarget over the network.
+	// GatewayGRPCTarget is the dial target of the gRPC server, empty dials GRPCServerAddress of this process.
+	// TLSRootCAFile verifies the certificate of the gRPC server in proxy mode, empty uses the system roots.
+	GatewayMode       string `mapstructure:"GATEWAY_MODE"`
+	GatewayGRPCTarget string `mapstructure:"GATEWAY_GRPC_TARGET"`
+	TLSRootCAFile     string `mapstructure:"TLS_ROOT_CA_FILE"`
+	// GRPCInterceptors is the comma separated chain of gRPC interceptors from the outermost, empty means the default chain:
+	// recovery, request_id, tracing, logging, metrics, rate_limit, auth and validation.
+	// recovery is required as the outermost interceptor and auth is required.
+	GRPCInterceptors string `mapstructure:"GRPC_INTERCEPTORS"`
 }
 
 // LoadConfig reads configuration from file or environment variables.
@@ -25,6 +88,9 @@ func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigName("app")
 	viper.SetConfigType("env")
 
+	viper.SetDefault("MAX_ACCOUNTS_PER_CURRENCY", 1)
+	viper.SetDefault("TRUSTED_PROXIES", "127.0.0.0/8,::1")
+
 	// read environment variables and automatically override values that it has read from configure file
 	viper.AutomaticEnv()
 
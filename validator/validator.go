@@ -3,13 +3,21 @@ package validator
 import (
 	"net/mail"
 	"regexp"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
+	"github.com/ifantsai/simple-bank-api/util"
 	"github.com/pkg/errors"
 )
 
 var (
-	isValidUsername = regexp.MustCompile(`^\w+$`).MatchString
-	isValidFullName = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidUsername         = regexp.MustCompile(`^\w+$`).MatchString
+	isValidFullName         = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidTransferCategory = regexp.MustCompile(`^[a-z][a-z0-9_]*$`).MatchString
+	isValidClientReference  = regexp.MustCompile(`^[\w\-.:/]+$`).MatchString
+	isValidAccountNickname  = regexp.MustCompile(`^[\pL\pN][\pL\pN\s\-_'.]*$`).MatchString
+	isValidAccountNumber    = regexp.MustCompile(`^` + util.AccountNumberCountryCode + `[0-9]{14}$`).MatchString
 )
 
 func ValidateString(value string, minLen, maxLen int) error {
@@ -30,6 +38,11 @@ func ValidateUsername(value string) error {
 		return errors.Errorf("username must be alphanumeric")
 	}
 
+	// the bank owns the system accounts, so nobody may register or log in as it
+	if value == util.BankUsername {
+		return errors.Errorf("username %s is reserved", value)
+	}
+
 	return nil
 }
 
@@ -58,3 +71,79 @@ func ValidateFullName(value string) error {
 
 	return nil
 }
+
+func ValidateUUID(value string) error {
+	_, err := uuid.Parse(value)
+
+	return errors.Wrap(err, "invalid uuid")
+}
+
+func ValidateTransferDescription(value string) error {
+	if err := ValidateString(value, 0, 200); err != nil {
+		return err
+	}
+
+	if !utf8.ValidString(value) {
+		return errors.Errorf("description must be valid UTF-8")
+	}
+
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return errors.Errorf("description must not contain control characters")
+		}
+	}
+
+	return nil
+}
+
+func ValidateTransferCategory(value string) error {
+	if err := ValidateString(value, 1, 50); err != nil {
+		return err
+	}
+
+	if !isValidTransferCategory(value) {
+		return errors.Errorf("category must contain only lowercase letters, digits and underscores")
+	}
+
+	return nil
+}
+
+func ValidateClientReference(value string) error {
+	if err := ValidateString(value, 1, 64); err != nil {
+		return err
+	}
+
+	if !isValidClientReference(value) {
+		return errors.Errorf("client reference must contain only letters, digits and -_.:/")
+	}
+
+	return nil
+}
+
+func ValidateSearchQuery(value string) error {
+	return ValidateString(value, 0, 100)
+}
+
+func ValidateAccountNickname(value string) error {
+	if err := ValidateString(value, 1, 50); err != nil {
+		return err
+	}
+
+	if !isValidAccountNickname(value) {
+		return errors.Errorf("nickname must start with a letter or digit and contain only letters, digits, spaces and -_'.")
+	}
+
+	return nil
+}
+
+func ValidateAccountNumber(value string) error {
+	if !isValidAccountNumber(value) {
+		return errors.Errorf("account number must be %s followed by 14 digits", util.AccountNumberCountryCode)
+	}
+
+	if util.AccountNumberChecksum(value) != 1 {
+		return errors.Errorf("account number has invalid check digits")
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+// Package watch fans out the account changes notified by Postgres to the watchers of the accounts.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/lib/pq"
+)
+
+const (
+	minReconnectInterval = 10 * time.Second
+	maxReconnectInterval = time.Minute
+	// pingInterval checks the connection of the listener when no notification arrives.
+	pingInterval = 90 * time.Second
+	// watcherBufferSize is the number of changes a watcher may lag behind before it is closed.
+	watcherBufferSize = 64
+)
+
+// Watcher receives the changes of the watched accounts.
+type Watcher struct {
+	// C is closed when the watcher is closed or may have missed changes, e.g. when it fell behind
+	// or the hub lost its connection to the database. The watcher should watch again and reload the accounts.
+	C <-chan db.AccountChange
+
+	c          chan db.AccountChange
+	accountIDs []int64
+	hub        *Hub
+	closed     bool
+}
+
+// Close stops watching the accounts.
+func (w *Watcher) Close() {
+	w.hub.mu.Lock()
+	defer w.hub.mu.Unlock()
+
+	w.hub.remove(w)
+}
+
+// Hub listens for account changes and dispatches them to the watchers of the accounts.
+type Hub struct {
+	dataSource string
+	mu         sync.Mutex
+	watchers   map[int64]map[*Watcher]struct{}
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// NewHub creates a new hub which listens on the database of the data source.
+func NewHub(dataSource string) *Hub {
+	return &Hub{
+		dataSource: dataSource,
+		watchers:   make(map[int64]map[*Watcher]struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// Watch starts watching the changes of the accounts.
+func (h *Hub) Watch(accountIDs ...int64) *Watcher {
+	c := make(chan db.AccountChange, watcherBufferSize)
+	w := &Watcher{C: c, c: c, accountIDs: accountIDs, hub: h}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, accountID := range accountIDs {
+		if h.watchers[accountID] == nil {
+			h.watchers[accountID] = make(map[*Watcher]struct{})
+		}
+
+		h.watchers[accountID][w] = struct{}{}
+	}
+
+	return w
+}
+
+// Start listens for account changes until the hub is stopped.
+func (h *Hub) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	defer close(h.done)
+
+	listener := pq.NewListener(h.dataSource, minReconnectInterval, maxReconnectInterval,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				log.Println("account changes listener:", err)
+			}
+		})
+	defer listener.Close()
+
+	if err := listener.Listen(db.AccountChangesChannel); err != nil {
+		return err
+	}
+
+	log.Println("watch hub is listening on", db.AccountChangesChannel)
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			h.closeAll()
+
+			return nil
+		case notification := <-listener.Notify:
+			// a nil notification means that the connection was re-established and changes may be lost
+			if notification == nil {
+				h.closeAll()
+
+				continue
+			}
+
+			h.notify(notification.Extra)
+		case <-ticker.C:
+			go func() {
+				if err := listener.Ping(); err != nil {
+					log.Println("account changes listener:", err)
+				}
+			}()
+		}
+	}
+}
+
+// Stop stops the hub and closes all watchers.
+func (h *Hub) Stop(ctx context.Context) error {
+	if h.cancel == nil {
+		return nil
+	}
+
+	h.cancel()
+
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Hub) notify(payload string) {
+	var change db.AccountChange
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		log.Println("invalid account change:", err)
+
+		return
+	}
+
+	h.dispatch(change)
+}
+
+// dispatch sends a change to the watchers of its account. Watchers which fell behind are closed.
+func (h *Hub) dispatch(change db.AccountChange) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for w := range h.watchers[change.Entry.AccountID] {
+		select {
+		case w.c <- change:
+		default:
+			h.remove(w)
+		}
+	}
+}
+
+func (h *Hub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, watchers := range h.watchers {
+		for w := range watchers {
+			h.remove(w)
+		}
+	}
+}
+
+// remove closes a watcher. The caller must hold the lock.
+func (h *Hub) remove(w *Watcher) {
+	if w.closed {
+		return
+	}
+
+	w.closed = true
+	close(w.c)
+
+	for _, accountID := range w.accountIDs {
+		delete(h.watchers[accountID], w)
+
+		if len(h.watchers[accountID]) == 0 {
+			delete(h.watchers, accountID)
+		}
+	}
+}
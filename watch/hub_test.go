@@ -0,0 +1,100 @@
+package watch
+
+import (
+	"testing"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func accountChange(accountID, balance int64) db.AccountChange {
+	return db.AccountChange{
+		Account: db.Account{ID: accountID, Balance: balance},
+		Entry:   db.Entry{AccountID: accountID, Amount: 10},
+	}
+}
+
+func TestHubDispatch(t *testing.T) {
+	hub := NewHub("")
+
+	watcher1 := hub.Watch(1, 2)
+	defer watcher1.Close()
+
+	watcher2 := hub.Watch(2)
+	defer watcher2.Close()
+
+	hub.notify(`{"account":{"id":1,"balance":110},"entry":{"account_id":1,"amount":10}}`)
+	hub.dispatch(accountChange(2, 20))
+	hub.dispatch(accountChange(3, 30))
+
+	require.Equal(t, accountChange(1, 110), <-watcher1.C)
+	require.Equal(t, accountChange(2, 20), <-watcher1.C)
+	require.Equal(t, accountChange(2, 20), <-watcher2.C)
+	require.Empty(t, watcher1.C)
+	require.Empty(t, watcher2.C)
+}
+
+func TestHubInvalidNotification(t *testing.T) {
+	hub := NewHub("")
+
+	watcher := hub.Watch(1)
+	defer watcher.Close()
+
+	hub.notify("not json")
+	require.Empty(t, watcher.C)
+}
+
+func TestWatcherClose(t *testing.T) {
+	hub := NewHub("")
+
+	watcher := hub.Watch(1)
+	watcher.Close()
+	watcher.Close()
+
+	_, ok := <-watcher.C
+	require.False(t, ok)
+	require.Empty(t, hub.watchers)
+
+	hub.dispatch(accountChange(1, 10))
+}
+
+func TestHubClosesLaggingWatcher(t *testing.T) {
+	hub := NewHub("")
+
+	lagging := hub.Watch(1)
+	defer lagging.Close()
+
+	for i := 0; i <= watcherBufferSize; i++ {
+		hub.dispatch(accountChange(1, int64(i)))
+	}
+
+	for i := 0; i < watcherBufferSize; i++ {
+		change, ok := <-lagging.C
+		require.True(t, ok)
+		require.Equal(t, int64(i), change.Account.Balance)
+	}
+
+	_, ok := <-lagging.C
+	require.False(t, ok)
+
+	// a new watcher is not affected
+	watcher := hub.Watch(1)
+	defer watcher.Close()
+
+	hub.dispatch(accountChange(1, 1))
+	require.Len(t, watcher.C, 1)
+}
+
+func TestHubCloseAll(t *testing.T) {
+	hub := NewHub("")
+
+	watcher1, watcher2 := hub.Watch(1), hub.Watch(1, 2)
+	hub.closeAll()
+
+	for _, watcher := range []*Watcher{watcher1, watcher2} {
+		_, ok := <-watcher.C
+		require.False(t, ok)
+	}
+
+	require.Empty(t, hub.watchers)
+}
@@ -0,0 +1,218 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+)
+
+const (
+	// MaxAttempts is the number of attempts before a delivery fails, a manual redelivery tries once more.
+	MaxAttempts = 8
+	// deliveryTimeout is how long a webhook may take to respond.
+	deliveryTimeout = 10 * time.Second
+	// deliveryLease keeps other workers from claiming a delivery while it is attempted.
+	// It is renewed just before each attempt, so it only has to cover a single attempt of the batch.
+	deliveryLease = 2 * deliveryTimeout
+	// claimBatchSize is the number of due deliveries claimed at once.
+	claimBatchSize = 50
+	baseBackoff    = 10 * time.Second
+	maxBackoff     = time.Hour
+	maxErrorLength = 500
+)
+
+// Backoff returns how long to wait after the given number of failed attempts.
+// It doubles with every attempt, starting at 10s and capped at one hour.
+func Backoff(attempts int32) time.Duration {
+	backoff := baseBackoff
+
+	for i := int32(1); i < attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+
+	return backoff
+}
+
+// Deliverer posts due webhook deliveries and retries failed ones with backoff.
+type Deliverer struct {
+	store    db.Store
+	client   *http.Client
+	interval time.Duration
+	// ctx is created with the deliverer so that Stop can cancel it while Start is starting.
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDeliverer creates a new deliverer which checks for due deliveries at every interval.
+func NewDeliverer(store db.Store, interval time.Duration) *Deliverer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Deliverer{
+		store:    store,
+		client:   newClient(),
+		interval: interval,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+}
+
+// Start runs the deliverer until it is stopped.
+func (d *Deliverer) Start() error {
+	ctx := d.ctx
+
+	defer close(d.done)
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	log.Println("webhook deliverer is running every", d.interval)
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DeliverDue(ctx); err != nil {
+			log.Println("failed to deliver webhooks:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops the deliverer and waits for the running deliveries to finish.
+// It can be called before Start runs, which then returns at once.
+func (d *Deliverer) Stop(ctx context.Context) error {
+	d.cancel()
+
+	select {
+	case <-d.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DeliverDue attempts every delivery that is due.
+func (d *Deliverer) DeliverDue(ctx context.Context) error {
+	deliveries, err := d.store.ClaimDueWebhookDeliveries(ctx, db.ClaimDueWebhookDeliveriesParams{
+		LeasedUntil: time.Now().Add(deliveryLease),
+		LimitCount:  claimBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		// the lease of a delivery later in the batch may have run out while the earlier ones were attempted
+		delivery, err := d.store.RenewWebhookDeliveryLease(ctx, db.RenewWebhookDeliveryLeaseParams{
+			ID:          delivery.ID,
+			LeasedUntil: time.Now().Add(deliveryLease),
+			HeldUntil:   delivery.NextAttemptAt,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// the deliveries of a deleted subscription are deleted with it
+		if _, err := d.Deliver(ctx, delivery); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Deliver attempts a delivery and records the outcome in the delivery log.
+func (d *Deliverer) Deliver(ctx context.Context, delivery db.WebhookDelivery) (db.WebhookDelivery, error) {
+	subscription, err := d.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return delivery, err
+	}
+
+	statusCode, err := d.post(ctx, subscription, delivery)
+	now := time.Now()
+
+	arg := db.RecordWebhookDeliveryAttemptParams{
+		ID:             delivery.ID,
+		Status:         DeliveryStatusSucceeded,
+		NextAttemptAt:  now,
+		LastStatusCode: int32(statusCode),
+		DeliveredAt:    sql.NullTime{Time: now, Valid: true},
+	}
+
+	if err != nil {
+		arg.DeliveredAt = sql.NullTime{}
+		arg.LastError = truncate(err.Error(), maxErrorLength)
+		arg.Status = DeliveryStatusPending
+		arg.NextAttemptAt = now.Add(Backoff(delivery.Attempts + 1))
+
+		if delivery.Attempts+1 >= MaxAttempts {
+			arg.Status = DeliveryStatusFailed
+		}
+	}
+
+	return d.store.RecordWebhookDeliveryAttempt(ctx, arg)
+}
+
+// post sends the signed payload and returns the status code of the response.
+func (d *Deliverer) post(ctx context.Context, subscription db.WebhookSubscription, delivery db.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, timestamp, delivery.Payload))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(EventHeader, delivery.EventType)
+
+	rsp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+
+	// drain the body so that the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(rsp.Body, 1<<16))
+
+	if rsp.StatusCode < http.StatusOK || rsp.StatusCode >= http.StatusMultipleChoices {
+		return rsp.StatusCode, fmt.Errorf("webhook responded with status %d", rsp.StatusCode)
+	}
+
+	return rsp.StatusCode, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/outbox"
+)
+
+// Dispatcher is an outbox sink which creates the webhook deliveries of completed transfers.
+// Deliveries are unique per subscription and event, so an event published again creates no duplicates.
+type Dispatcher struct {
+	store db.Store
+}
+
+// NewDispatcher creates a new dispatcher.
+func NewDispatcher(store db.Store) *Dispatcher {
+	return &Dispatcher{store: store}
+}
+
+// Publish creates the deliveries of a domain event for the subscriptions of the users involved.
+func (d *Dispatcher) Publish(ctx context.Context, event outbox.Event) error {
+	if event.Type != db.EventTransferCompleted && event.Type != db.EventTransferApproved {
+		return nil
+	}
+
+	var transferEvent db.TransferEvent
+	if err := json.Unmarshal(event.Payload, &transferEvent); err != nil {
+		return err
+	}
+
+	transfer := transferEvent.Transfer
+	// the balance of the sender when the transfer was posted, later transfers may have changed it since
+	balance := transferEvent.FromAccountBalance
+	balanceBefore := balance + transfer.Amount + transfer.Fee
+
+	// accounts are closed rather than deleted, and the owner of an account closed since
+	// is still notified of the transfers it made before
+	fromAccount, err := d.store.GetAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		return err
+	}
+
+	toAccount, err := d.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		return err
+	}
+
+	subscriptions, err := d.store.ListWebhookSubscriptionsByOwners(ctx, []string{fromAccount.Owner, toAccount.Owner})
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		var payloads []Payload
+
+		if subscription.Owner == fromAccount.Owner && subscribed(subscription, EventTransferSent) {
+			payloads = append(payloads, Payload{
+				Type: EventTransferSent,
+				Data: TransferData{
+					AccountID: fromAccount.ID,
+					Transfer:  newTransfer(transfer, fromAccount, toAccount, subscription.Owner),
+				},
+			})
+		}
+
+		if subscription.Owner == toAccount.Owner && subscribed(subscription, EventTransferReceived) {
+			payloads = append(payloads, Payload{
+				Type: EventTransferReceived,
+				Data: TransferData{
+					AccountID: toAccount.ID,
+					Transfer:  newTransfer(transfer, fromAccount, toAccount, subscription.Owner),
+				},
+			})
+		}
+
+		// only the transfer which takes the balance below the threshold notifies
+		if subscription.Owner == fromAccount.Owner && subscribed(subscription, EventBalanceLow) &&
+			balance < subscription.LowBalanceThreshold && balanceBefore >= subscription.LowBalanceThreshold {
+			payloads = append(payloads, Payload{
+				Type: EventBalanceLow,
+				Data: BalanceData{
+					AccountID: fromAccount.ID,
+					Balance:   balance,
+					Currency:  fromAccount.Currency,
+					Threshold: subscription.LowBalanceThreshold,
+				},
+			})
+		}
+
+		for _, payload := range payloads {
+			payload.EventID = event.ID
+			payload.CreatedAt = event.CreatedAt
+
+			data, err := json.Marshal(payload)
+			if err != nil {
+				return err
+			}
+
+			err = d.store.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+				SubscriptionID: subscription.ID,
+				EventID:        event.ID,
+				EventType:      payload.Type,
+				Payload:        data,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
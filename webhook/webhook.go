@@ -0,0 +1,110 @@
+// Package webhook notifies the webhook subscriptions of users about their account activity.
+package webhook
+
+import (
+	"time"
+
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+)
+
+// Event types users can subscribe to.
+const (
+	EventTransferReceived = "transfer.received"
+	EventTransferSent     = "transfer.sent"
+	EventBalanceLow       = "balance.low"
+)
+
+// IsSupportedEventType returns true if users can subscribe to the event type.
+func IsSupportedEventType(eventType string) bool {
+	switch eventType {
+	case EventTransferReceived, EventTransferSent, EventBalanceLow:
+		return true
+	}
+
+	return false
+}
+
+// Statuses of deliveries.
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// Payload is the body posted to a webhook.
+type Payload struct {
+	// EventID identifies the event, a payload may be delivered more than once.
+	EventID   int64       `json:"event_id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// TransferData is the data of transfer.received and transfer.sent events.
+type TransferData struct {
+	// AccountID is the account of the subscriber that received or sent the money.
+	AccountID int64    `json:"account_id"`
+	Transfer  Transfer `json:"transfer"`
+}
+
+// Transfer is a transfer as seen by the subscriber, the accounts of other users are only given by their account number.
+type Transfer struct {
+	ID                int64     `json:"id"`
+	FromAccountID     int64     `json:"from_account_id,omitempty"`
+	FromAccountNumber string    `json:"from_account_number"`
+	ToAccountID       int64     `json:"to_account_id,omitempty"`
+	ToAccountNumber   string    `json:"to_account_number"`
+	Amount            int64     `json:"amount"`
+	Fee               int64     `json:"fee,omitempty"`
+	Currency          string    `json:"currency"`
+	Description       string    `json:"description"`
+	Category          string    `json:"category"`
+	ClientReference   string    `json:"client_reference"`
+	Status            string    `json:"status"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func newTransfer(transfer db.Transfer, fromAccount, toAccount db.Account, owner string) Transfer {
+	t := Transfer{
+		ID:                transfer.ID,
+		FromAccountNumber: fromAccount.Number,
+		ToAccountNumber:   toAccount.Number,
+		Amount:            transfer.Amount,
+		Currency:          fromAccount.Currency,
+		Description:       transfer.Description,
+		Category:          transfer.Category,
+		ClientReference:   transfer.ClientReference,
+		Status:            transfer.Status,
+		CreatedAt:         transfer.CreatedAt,
+	}
+
+	// the fee is charged to the sender, so only the sender sees it
+	if fromAccount.Owner == owner {
+		t.FromAccountID = fromAccount.ID
+		t.Fee = transfer.Fee
+	}
+
+	if toAccount.Owner == owner {
+		t.ToAccountID = toAccount.ID
+	}
+
+	return t
+}
+
+// BalanceData is the data of balance.low events.
+type BalanceData struct {
+	AccountID int64  `json:"account_id"`
+	Balance   int64  `json:"balance"`
+	Currency  string `json:"currency"`
+	Threshold int64  `json:"threshold"`
+}
+
+func subscribed(subscription db.WebhookSubscription, eventType string) bool {
+	for _, t := range subscription.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
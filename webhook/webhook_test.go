@@ -0,0 +1,512 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/ifantsai/simple-bank-api/db/mock"
+	db "github.com/ifantsai/simple-bank-api/db/sqlc"
+	"github.com/ifantsai/simple-bank-api/outbox"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, len(secretPrefix)+2*secretSize)
+
+	body := []byte(`{"type":"transfer.received"}`)
+	signature := Sign(secret, 1700000000, body)
+
+	require.True(t, Verify(secret, signature, 1700000000, body))
+	require.False(t, Verify(secret, signature, 1700000001, body))
+	require.False(t, Verify(secret, signature, 1700000000, []byte(`{"type":"transfer.sent"}`)))
+	require.False(t, Verify("whsec_other", signature, 1700000000, body))
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, 10*time.Second, Backoff(0))
+	require.Equal(t, 10*time.Second, Backoff(1))
+	require.Equal(t, 20*time.Second, Backoff(2))
+	require.Equal(t, 80*time.Second, Backoff(4))
+	require.Equal(t, 1280*time.Second, Backoff(MaxAttempts))
+	require.Equal(t, time.Hour, Backoff(100))
+}
+
+func TestDeliver(t *testing.T) {
+	var received int
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received++
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		require.NoError(t, err)
+		require.True(t, Verify("whsec_test", r.Header.Get(SignatureHeader), timestamp, body))
+		require.Equal(t, "3", r.Header.Get(DeliveryHeader))
+		require.Equal(t, EventTransferReceived, r.Header.Get(EventHeader))
+
+		if received == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	subscription := db.WebhookSubscription{ID: 1, Url: receiver.URL, Secret: "whsec_test"}
+	delivery := db.WebhookDelivery{
+		ID:             3,
+		SubscriptionID: subscription.ID,
+		EventType:      EventTransferReceived,
+		Payload:        json.RawMessage(`{"type":"transfer.received"}`),
+		Attempts:       2,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetWebhookSubscription(gomock.Any(), gomock.Eq(subscription.ID)).
+		Times(2).
+		Return(subscription, nil)
+
+	gomock.InOrder(
+		store.EXPECT().
+			RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+				require.Equal(t, DeliveryStatusPending, arg.Status)
+				require.Equal(t, int32(http.StatusServiceUnavailable), arg.LastStatusCode)
+				require.NotEmpty(t, arg.LastError)
+				require.False(t, arg.DeliveredAt.Valid)
+				require.WithinDuration(t, time.Now().Add(Backoff(3)), arg.NextAttemptAt, time.Second)
+
+				return db.WebhookDelivery{}, nil
+			}),
+		store.EXPECT().
+			RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+			Times(1).
+			DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+				require.Equal(t, DeliveryStatusSucceeded, arg.Status)
+				require.Equal(t, int32(http.StatusNoContent), arg.LastStatusCode)
+				require.Empty(t, arg.LastError)
+				require.True(t, arg.DeliveredAt.Valid)
+
+				return db.WebhookDelivery{}, nil
+			}),
+	)
+
+	// the receiver listens on loopback, which the client of deliveries refuses
+	deliverer := NewDeliverer(store, 0)
+	deliverer.client = receiver.Client()
+
+	_, err := deliverer.Deliver(context.Background(), delivery)
+	require.NoError(t, err)
+
+	_, err = deliverer.Deliver(context.Background(), delivery)
+	require.NoError(t, err)
+	require.Equal(t, 2, received)
+}
+
+func TestDeliverLastAttempt(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetWebhookSubscription(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.WebhookSubscription{ID: 1, Url: receiver.URL, Secret: "whsec_test"}, nil)
+	store.EXPECT().
+		RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			require.Equal(t, DeliveryStatusFailed, arg.Status)
+
+			return db.WebhookDelivery{}, nil
+		})
+
+	deliverer := NewDeliverer(store, 0)
+	deliverer.client = receiver.Client()
+
+	_, err := deliverer.Deliver(context.Background(), db.WebhookDelivery{
+		ID:             1,
+		SubscriptionID: 1,
+		Attempts:       MaxAttempts - 1,
+	})
+	require.NoError(t, err)
+}
+
+func TestDeliverDue(t *testing.T) {
+	var received []string
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(DeliveryHeader))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	leasedUntil := time.Now().Add(deliveryLease).Truncate(time.Microsecond)
+	deliveries := []db.WebhookDelivery{
+		{ID: 1, SubscriptionID: 1, NextAttemptAt: leasedUntil},
+		{ID: 2, SubscriptionID: 1, NextAttemptAt: leasedUntil},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(deliveries, nil)
+
+	// another worker took the first delivery over after its lease ran out
+	store.EXPECT().
+		RenewWebhookDeliveryLease(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ context.Context, arg db.RenewWebhookDeliveryLeaseParams) (db.WebhookDelivery, error) {
+			require.Equal(t, leasedUntil, arg.HeldUntil)
+			require.True(t, arg.LeasedUntil.After(time.Now()))
+
+			if arg.ID == 1 {
+				return db.WebhookDelivery{}, sql.ErrNoRows
+			}
+
+			delivery := deliveries[1]
+			delivery.NextAttemptAt = arg.LeasedUntil
+
+			return delivery, nil
+		})
+	store.EXPECT().
+		GetWebhookSubscription(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.WebhookSubscription{ID: 1, Url: receiver.URL, Secret: "whsec_test"}, nil)
+	store.EXPECT().
+		RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			require.Equal(t, int64(2), arg.ID)
+			require.Equal(t, DeliveryStatusSucceeded, arg.Status)
+
+			return db.WebhookDelivery{}, nil
+		})
+
+	deliverer := NewDeliverer(store, 0)
+	deliverer.client = receiver.Client()
+
+	require.NoError(t, deliverer.DeliverDue(context.Background()))
+	require.Equal(t, []string{"2"}, received)
+}
+
+func TestDelivererStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		ClaimDueWebhookDeliveries(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return([]db.WebhookDelivery{}, nil)
+
+	deliverer := NewDeliverer(store, time.Hour)
+
+	// Stop may run before the goroutine of Start did
+	go func() {
+		require.NoError(t, deliverer.Start())
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, deliverer.Stop(ctx))
+}
+
+func TestValidateURL(t *testing.T) {
+	require.NoError(t, ValidateURL(context.Background(), "https://203.0.113.10/hooks"))
+	require.NoError(t, ValidateURL(context.Background(), "https://[2001:4860:4860::8888]/hooks"))
+
+	for _, rawURL := range []string{
+		"http://203.0.113.10/hooks",
+		"https:///hooks",
+		"https://127.0.0.1/hooks",
+		"https://[::1]/hooks",
+		"https://10.0.0.8/hooks",
+		"https://192.168.1.1/hooks",
+		"https://169.254.169.254/latest/meta-data",
+		"https://0.0.0.0/hooks",
+	} {
+		require.ErrorIs(t, ValidateURL(context.Background(), rawURL), ErrUnsafeURL, rawURL)
+	}
+}
+
+func TestCheckIP(t *testing.T) {
+	testCases := []struct {
+		name   string
+		ip     string
+		public bool
+	}{
+		{name: "Public", ip: "203.0.113.10", public: true},
+		{name: "PublicIPv6", ip: "2001:4860:4860::8888", public: true},
+		{name: "Loopback", ip: "127.0.0.1"},
+		{name: "Private", ip: "172.16.0.1"},
+		{name: "LinkLocal", ip: "169.254.169.254"},
+		{name: "ThisNetwork", ip: "0.1.2.3"},
+		{name: "CarrierGradeNAT", ip: "100.64.0.1"},
+		{name: "CarrierGradeNATEnd", ip: "100.127.255.254"},
+		{name: "Benchmarking", ip: "198.19.0.1"},
+		{name: "NAT64", ip: "64:ff9b::a00:1"},
+		{name: "MappedCarrierGradeNAT", ip: "::ffff:100.64.0.1"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkIP(net.ParseIP(tc.ip))
+			if tc.public {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrUnsafeURL)
+			}
+		})
+	}
+}
+
+func TestDeliverRefusesPrivateAddress(t *testing.T) {
+	var received bool
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetWebhookSubscription(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.WebhookSubscription{ID: 1, Url: receiver.URL, Secret: "whsec_test"}, nil)
+	store.EXPECT().
+		RecordWebhookDeliveryAttempt(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.RecordWebhookDeliveryAttemptParams) (db.WebhookDelivery, error) {
+			require.Equal(t, DeliveryStatusPending, arg.Status)
+			require.Contains(t, arg.LastError, "is not public")
+
+			return db.WebhookDelivery{}, nil
+		})
+
+	// the address is checked when connecting, whatever the host resolved to
+	_, err := NewDeliverer(store, 0).Deliver(context.Background(), db.WebhookDelivery{ID: 1, SubscriptionID: 1})
+	require.NoError(t, err)
+	require.False(t, received)
+}
+
+func TestDispatcher(t *testing.T) {
+	// later transfers changed the balance of the sender since the event was recorded
+	fromAccount := db.Account{ID: 1, Owner: "alice", Balance: 20, Currency: "USD", Number: "DE00ALICE"}
+	toAccount := db.Account{ID: 2, Owner: "bob", Balance: 150, Currency: "USD", Number: "DE00BOB"}
+	transfer := db.Transfer{ID: 7, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: 100, Fee: 1}
+
+	payload, err := json.Marshal(db.TransferEvent{Transfer: transfer, FromAccountBalance: 50})
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	store.EXPECT().
+		ListWebhookSubscriptionsByOwners(gomock.Any(), gomock.Eq([]string{fromAccount.Owner, toAccount.Owner})).
+		Times(1).
+		Return([]db.WebhookSubscription{
+			{
+				ID:                  1,
+				Owner:               fromAccount.Owner,
+				EventTypes:          []string{EventTransferSent, EventTransferReceived, EventBalanceLow},
+				LowBalanceThreshold: 100,
+			},
+			{ID: 2, Owner: toAccount.Owner, EventTypes: []string{EventTransferReceived, EventBalanceLow}},
+		}, nil)
+
+	var deliveries []db.CreateWebhookDeliveryParams
+
+	store.EXPECT().
+		CreateWebhookDelivery(gomock.Any(), gomock.Any()).
+		Times(3).
+		DoAndReturn(func(_ context.Context, arg db.CreateWebhookDeliveryParams) error {
+			deliveries = append(deliveries, arg)
+
+			return nil
+		})
+
+	err = NewDispatcher(store).Publish(context.Background(), outbox.Event{
+		ID:      9,
+		Type:    db.EventTransferCompleted,
+		Payload: payload,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(1), deliveries[0].SubscriptionID)
+	require.Equal(t, EventTransferSent, deliveries[0].EventType)
+	require.Equal(t, int64(1), deliveries[1].SubscriptionID)
+	require.Equal(t, EventBalanceLow, deliveries[1].EventType)
+	require.Equal(t, int64(2), deliveries[2].SubscriptionID)
+	require.Equal(t, EventTransferReceived, deliveries[2].EventType)
+
+	for _, delivery := range deliveries {
+		require.Equal(t, int64(9), delivery.EventID)
+	}
+
+	var sent struct {
+		EventID int64        `json:"event_id"`
+		Type    string       `json:"type"`
+		Data    TransferData `json:"data"`
+	}
+
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &sent))
+	require.Equal(t, int64(9), sent.EventID)
+	require.Equal(t, fromAccount.ID, sent.Data.AccountID)
+	require.Equal(t, transfer.ID, sent.Data.Transfer.ID)
+	require.Equal(t, fromAccount.ID, sent.Data.Transfer.FromAccountID)
+	require.Equal(t, transfer.Fee, sent.Data.Transfer.Fee)
+	require.Zero(t, sent.Data.Transfer.ToAccountID)
+	require.Equal(t, toAccount.Number, sent.Data.Transfer.ToAccountNumber)
+
+	// the recipient only sees the account number of the sender
+	var received struct {
+		Data TransferData `json:"data"`
+	}
+
+	require.NoError(t, json.Unmarshal(deliveries[2].Payload, &received))
+	require.Equal(t, toAccount.ID, received.Data.AccountID)
+	require.Zero(t, received.Data.Transfer.FromAccountID)
+	require.Zero(t, received.Data.Transfer.Fee)
+	require.Equal(t, fromAccount.Number, received.Data.Transfer.FromAccountNumber)
+	require.Equal(t, toAccount.ID, received.Data.Transfer.ToAccountID)
+
+	var low struct {
+		Data BalanceData `json:"data"`
+	}
+
+	require.NoError(t, json.Unmarshal(deliveries[1].Payload, &low))
+	require.Equal(t, int64(50), low.Data.Balance)
+	require.Equal(t, int64(100), low.Data.Threshold)
+}
+
+func TestDispatcherBalanceAlreadyLow(t *testing.T) {
+	fromAccount := db.Account{ID: 1, Owner: "alice", Balance: 50, Currency: "USD"}
+	toAccount := db.Account{ID: 2, Owner: "bob", Balance: 150, Currency: "USD"}
+	transfer := db.Transfer{ID: 8, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: 10}
+
+	payload, err := json.Marshal(db.TransferEvent{Transfer: transfer, FromAccountBalance: 50})
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	store.EXPECT().
+		ListWebhookSubscriptionsByOwners(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.WebhookSubscription{
+			{ID: 1, Owner: fromAccount.Owner, EventTypes: []string{EventBalanceLow}, LowBalanceThreshold: 100},
+		}, nil)
+
+	// the balance was below the threshold before the transfer already
+	store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).Times(0)
+
+	err = NewDispatcher(store).Publish(context.Background(), outbox.Event{
+		ID:      10,
+		Type:    db.EventTransferCompleted,
+		Payload: payload,
+	})
+	require.NoError(t, err)
+}
+
+func TestDispatcherClosedAccount(t *testing.T) {
+	// the sender closed the account since the transfer
+	fromAccount := db.Account{ID: 1, Owner: "alice", Currency: "USD", Status: db.AccountStatusClosed}
+	toAccount := db.Account{ID: 2, Owner: "bob", Balance: 150, Currency: "USD"}
+	transfer := db.Transfer{ID: 11, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: 10}
+
+	payload, err := json.Marshal(db.TransferEvent{Transfer: transfer})
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	store.EXPECT().
+		ListWebhookSubscriptionsByOwners(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.WebhookSubscription{
+			{ID: 1, Owner: fromAccount.Owner, EventTypes: []string{EventTransferSent}},
+		}, nil)
+	store.EXPECT().CreateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+	err = NewDispatcher(store).Publish(context.Background(), outbox.Event{
+		ID:      12,
+		Type:    db.EventTransferCompleted,
+		Payload: payload,
+	})
+	require.NoError(t, err)
+}
+
+func TestDispatcherMissingAccount(t *testing.T) {
+	transfer := db.Transfer{ID: 13, FromAccountID: 1, ToAccountID: 2, Amount: 10}
+
+	payload, err := json.Marshal(db.TransferEvent{Transfer: transfer})
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+
+	// accounts are never deleted, so the event is retried rather than dropped
+	err = NewDispatcher(store).Publish(context.Background(), outbox.Event{
+		ID:      14,
+		Type:    db.EventTransferCompleted,
+		Payload: payload,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestDispatcherIgnoresOtherEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	err := NewDispatcher(store).Publish(context.Background(), outbox.Event{Type: db.EventTransferHeld})
+	require.NoError(t, err)
+}